@@ -64,6 +64,8 @@ func (c *applyCmd) register() *cobra.Command {
 
 	addConnectServerFlags(cmd)
 
+	addCommand(cmd, newApplyFileWatchCmd(c.streams))
+
 	c.cmd = cmd
 	c.flags = flags
 	return cmd
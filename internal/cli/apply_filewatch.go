@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/util/csaupgrade"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// The field manager that owns the fields `tilt apply filewatch` sets,
+// unless --field-manager says otherwise.
+//
+// It's the same on every run, so that applying the same FileWatch again
+// converges on it instead of conflicting with the last apply.
+const applyFieldManager = "tilt-cli"
+
+// The create filewatch flags that don't make sense for a declarative apply.
+var createOnlyFileWatchFlags = []string{"update", "ensure", "replace", "generate-name", "edit", "attach", "patch", "add-ignore"}
+
+// A declarative CLI for file watches.
+//
+// Takes the same arguments as createFileWatchCmd, but server-side applies
+// the FileWatch, so that running it again with the same arguments is a no-op.
+type applyFileWatchCmd struct {
+	*createFileWatchCmd
+}
+
+var _ tiltCmd = &applyFileWatchCmd{}
+
+func newApplyFileWatchCmd(streams genericclioptions.IOStreams) *applyFileWatchCmd {
+	create := newCreateFileWatchCmd(streams)
+	create.apply = true
+	create.helper.createdVerb = "applied"
+	create.helper.fieldManager = applyFieldManager
+	create.helper.printFlags = genericclioptions.NewPrintFlags("applied")
+	return &applyFileWatchCmd{createFileWatchCmd: create}
+}
+
+func (c *applyFileWatchCmd) name() model.TiltSubcommand { return "apply" }
+
+func (c *applyFileWatchCmd) register() *cobra.Command {
+	cmd := c.createFileWatchCmd.register()
+	cmd.Short = "Apply a filewatch to a running tilt session"
+	cmd.Long = `Apply a FileWatch to a running tilt session.
+
+Takes the same arguments and flags as 'tilt create filewatch', but
+creates the FileWatch if it doesn't exist, and otherwise makes the
+existing FileWatch match. Running it again with the same arguments
+leaves the FileWatch as is, so it's safe to run from scripts.
+
+A FileWatch that doesn't exist yet is created. An existing one is applied
+server-side, with the field manager 'tilt-cli', or the one passed with
+--field-manager. Use the same one on every run. If the tilt session can't
+apply it, its spec is replaced like 'tilt create filewatch --update'.
+`
+	cmd.Example = `tilt apply fw src-and-web src web --ignore=web/node_modules
+
+tilt apply fw -f watches.yaml`
+
+	for _, name := range createOnlyFileWatchFlags {
+		_ = cmd.Flags().MarkHidden(name)
+	}
+	return cmd
+}
+
+func (c *applyFileWatchCmd) run(ctx context.Context, args []string) error {
+	for _, name := range createOnlyFileWatchFlags {
+		if c.cmd.Flags().Changed(name) {
+			return c.helper.fail(usageErrorf("--%s can't be used with 'tilt apply filewatch'. Use 'tilt create filewatch --%s' instead", name, name))
+		}
+	}
+	return c.createFileWatchCmd.run(ctx, args)
+}
+
+// Server-side applies the FileWatch.
+func (c *createFileWatchCmd) serverSideApply(ctx context.Context, fw *v1alpha1.FileWatch) (*unstructured.Unstructured, error) {
+	data, err := applyPatch(fw)
+	if err != nil {
+		return nil, err
+	}
+	return c.helper.dynamicClient.Resource(fw.GetGroupVersionResource()).
+		Patch(ctx, fw.Name, types.ApplyPatchType, data, applyPatchOptions(c.helper.fieldManager))
+}
+
+// Moves the fields of a FileWatch that was created instead of applied
+// to the apply field manager, so that the next apply can remove them.
+//
+// Otherwise, the fields would stay owned by the create, and the next
+// apply without them would leave them in place.
+func (c *createFileWatchCmd) takeOverAppliedFields(ctx context.Context, fw *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	manager := c.helper.fieldManager
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(fw, sets.New(manager), manager)
+	if err != nil {
+		return nil, err
+	}
+	if patch == nil {
+		return fw, nil
+	}
+	result, err := c.helper.dynamicClient.Resource((&v1alpha1.FileWatch{}).GetGroupVersionResource()).
+		Patch(ctx, fw.GetName(), types.JSONPatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("taking over the fields of FileWatch %s: %v", fw.GetName(), wrapNoSessionError(err))
+	}
+	return result, nil
+}
+
+// Returns the apply patch for a FileWatch: the object, without the
+// fields that the server owns.
+func applyPatch(fw *v1alpha1.FileWatch) ([]byte, error) {
+	u, err := toUnstructured(fw)
+	if err != nil {
+		return nil, err
+	}
+	unstructured.RemoveNestedField(u.Object, "status")
+	unstructured.RemoveNestedField(u.Object, "metadata", "creationTimestamp")
+	return json.Marshal(u.Object)
+}
+
+// Forces the apply, so that fields other clients set, e.g., with
+// 'tilt create filewatch --update', are taken over instead of conflicting.
+func applyPatchOptions(fieldManager string) metav1.PatchOptions {
+	force := true
+	return metav1.PatchOptions{
+		FieldManager: fieldManager,
+		Force:        &force,
+	}
+}
+
+// Whether the server rejected an apply patch because it can't apply,
+// rather than because of the FileWatch.
+func isServerSideApplyUnsupported(err error) bool {
+	return apierrors.IsUnsupportedMediaType(err) || apierrors.IsMethodNotSupported(err)
+}
@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestApplyFileWatch(t *testing.T) {
+	f := newServerFixture(t)
+	f.Chdir()
+
+	out := bytes.NewBuffer(nil)
+	applyFileWatch(t, f, out, "--ignore", "web/node_modules", "--allow-missing", "my-watch", "src", "web")
+	assert.Contains(t, out.String(), `filewatch.tilt.dev/my-watch applied`)
+
+	// Applying again with different arguments replaces the spec, rather than
+	// failing because the FileWatch exists.
+	out.Reset()
+	applyFileWatch(t, f, out, "--allow-missing", "my-watch", "src")
+	assert.Contains(t, out.String(), `filewatch.tilt.dev/my-watch applied`)
+
+	var fw v1alpha1.FileWatch
+	err := f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(f.Path(), "src")}, fw.Spec.WatchedPaths)
+	assert.Empty(t, fw.Spec.Ignores)
+}
+
+func TestApplyFileWatchServerSideApply(t *testing.T) {
+	client := newFakeClientWithFileWatch(t, "my-watch")
+	var patchTypes []types.PatchType
+	var patch map[string]interface{}
+	client.PrependReactor("patch", "filewatches", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patchAction := action.(k8stesting.PatchAction)
+		patchTypes = append(patchTypes, patchAction.GetPatchType())
+		patch = map[string]interface{}{}
+		err := json.Unmarshal(patchAction.GetPatch(), &patch)
+		return true, &unstructured.Unstructured{Object: patch}, err
+	})
+
+	out := bytes.NewBuffer(nil)
+	cmd := newApplyFileWatchCmd(genericclioptions.IOStreams{Out: out})
+	cmd.helper.dynamicClient = client
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), `filewatch.tilt.dev/my-watch applied`)
+
+	assert.Equal(t, []types.PatchType{types.ApplyPatchType}, patchTypes)
+	assert.Equal(t, "FileWatch", patch["kind"])
+	assert.NotContains(t, patch, "status")
+	assert.NotContains(t, patch["metadata"], "creationTimestamp")
+	for _, action := range client.Actions() {
+		assert.NotEqual(t, "create", action.GetVerb())
+	}
+}
+
+func TestApplyFileWatchCreatesNewFileWatch(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(v1alpha1.NewScheme())
+
+	cmd := newApplyFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	cmd.helper.dynamicClient = client
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var verbs []string
+	for _, action := range client.Actions() {
+		verbs = append(verbs, action.GetVerb())
+	}
+	assert.Equal(t, []string{"get", "create"}, verbs)
+}
+
+func TestApplyFileWatchUpdateWithoutServerSideApply(t *testing.T) {
+	client := newFakeClientWithFileWatch(t, "my-watch")
+	client.PrependReactor("patch", "filewatches", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewGenericServerResponse(http.StatusUnsupportedMediaType, "patch",
+			v1alpha1.Resource("filewatches"), "my-watch", "", 0, false)
+	})
+
+	cmd := newApplyFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	cmd.helper.dynamicClient = client
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var verbs []string
+	for _, action := range client.Actions() {
+		verbs = append(verbs, action.GetVerb())
+	}
+	assert.Equal(t, []string{"get", "patch", "create", "get", "update"}, verbs)
+}
+
+// A fake dynamic client with an existing FileWatch, and no recorded actions.
+func newFakeClientWithFileWatch(t *testing.T, name string) *dynamicfake.FakeDynamicClient {
+	existing, err := toUnstructured(&v1alpha1.FileWatch{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	require.NoError(t, err)
+	client := dynamicfake.NewSimpleDynamicClient(v1alpha1.NewScheme())
+	_, err = client.Resource((&v1alpha1.FileWatch{}).GetGroupVersionResource()).Create(context.Background(), existing, metav1.CreateOptions{})
+	require.NoError(t, err)
+	client.ClearActions()
+	return client
+}
+
+func TestApplyPatchOptions(t *testing.T) {
+	opts := applyPatchOptions(applyFieldManager)
+	assert.Equal(t, "tilt-cli", opts.FieldManager)
+	if assert.NotNil(t, opts.Force) {
+		assert.True(t, *opts.Force)
+	}
+	assert.Equal(t, "my-tool", applyPatchOptions("my-tool").FieldManager)
+}
+
+func TestApplyFileWatchFieldManager(t *testing.T) {
+	f := newServerFixture(t)
+	f.Chdir()
+
+	out := bytes.NewBuffer(nil)
+	applyFileWatch(t, f, out, "--field-manager=my-tool", "--allow-missing", "my-watch", "src", "web")
+	applyFileWatch(t, f, out, "--field-manager=my-tool", "--allow-missing", "my-watch", "src")
+
+	var fw v1alpha1.FileWatch
+	err := f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(f.Path(), "src")}, fw.Spec.WatchedPaths)
+	require.NotEmpty(t, fw.ManagedFields)
+	for _, entry := range fw.ManagedFields {
+		assert.Equal(t, "my-tool", entry.Manager)
+	}
+}
+
+func TestIsServerSideApplyUnsupported(t *testing.T) {
+	gr := v1alpha1.Resource("filewatches")
+	assert.False(t, isServerSideApplyUnsupported(nil))
+	assert.True(t, isServerSideApplyUnsupported(
+		apierrors.NewGenericServerResponse(http.StatusUnsupportedMediaType, "patch", gr, "src", "", 0, false)))
+	assert.True(t, isServerSideApplyUnsupported(apierrors.NewMethodNotSupported(gr, "patch")))
+	assert.False(t, isServerSideApplyUnsupported(
+		apierrors.NewInternalError(errors.New("object does not implement the Object interfaces"))))
+	assert.False(t, isServerSideApplyUnsupported(apierrors.NewInternalError(errors.New("disk full"))))
+	assert.False(t, isServerSideApplyUnsupported(apierrors.NewBadRequest("spec.watchedPaths cannot be empty")))
+}
+
+func TestApplyFileWatchCreateOnlyFlags(t *testing.T) {
+	cmd := newApplyFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	assert.True(t, c.Flags().Lookup("update").Hidden)
+
+	err := c.Flags().Parse([]string{"--update", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	assert.EqualError(t, err, "--update can't be used with 'tilt apply filewatch'. Use 'tilt create filewatch --update' instead")
+}
+
+func applyFileWatch(t *testing.T, f *serverFixture, out *bytes.Buffer, args ...string) {
+	t.Helper()
+	cmd := newApplyFileWatchCmd(genericclioptions.IOStreams{Out: out})
+	c := cmd.register()
+	err := c.Flags().Parse(args)
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+}
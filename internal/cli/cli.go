@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -101,6 +102,24 @@ up-to-date in real-time. Think 'docker build && kubectl apply' or 'docker-compos
 	}
 }
 
+// An error that the command has already shown to the user, e.g., as JSON.
+// The command still fails, but isn't printed again.
+type printedError struct {
+	err error
+}
+
+func (e printedError) Error() string { return e.err.Error() }
+func (e printedError) Unwrap() error { return e.err }
+
+// An error that the command exits with a specific code for, instead of 1.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e exitCodeError) Error() string { return e.err.Error() }
+func (e exitCodeError) Unwrap() error { return e.err }
+
 type tiltCmd interface {
 	name() model.TiltSubcommand
 	register() *cobra.Command
@@ -162,9 +181,17 @@ func addCommand(parent *cobra.Command, child tiltCmd) {
 		err := child.run(ctx, args)
 		if err != nil {
 			// TODO(maia): this shouldn't print if we've already pretty-printed it
-			_, printErr := fmt.Fprintf(output.OriginalStderr, "Error: %v\n", err)
-			if printErr != nil {
-				panic(printErr)
+			var printed printedError
+			if !errors.As(err, &printed) {
+				_, printErr := fmt.Fprintf(output.OriginalStderr, "Error: %v\n", err)
+				if printErr != nil {
+					panic(printErr)
+				}
+			}
+
+			var coded exitCodeError
+			if errors.As(err, &coded) {
+				os.Exit(coded.code)
 			}
 			os.Exit(1)
 		}
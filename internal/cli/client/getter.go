@@ -74,13 +74,20 @@ func (f *Getter) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error)
 	if err != nil {
 		return nil, err
 	}
+	return NewCachedDiscoveryClient(f.dir, config)
+}
+
+// NewCachedDiscoveryClient returns a discovery client for the API server at config,
+// that caches what it discovers under dir for a few minutes.
+func NewCachedDiscoveryClient(dir *dirs.TiltDevDir, config *rest.Config) (discovery.CachedDiscoveryInterface, error) {
+	config = rest.CopyConfig(config)
 
 	// The more groups you have, the more discovery requests you need to make.
 	// given 25 groups (our groups + a few custom resources) with one-ish version each, discovery needs to make 50 requests
 	// double it just so we don't end up here again for a while.  This config is only used for discovery.
 	config.Burst = 100
 
-	cacheDir := filepath.Join(f.dir.Root(), "cache")
+	cacheDir := filepath.Join(dir.Root(), "cache")
 	httpCacheDir := filepath.Join(cacheDir, "http")
 	discoveryCacheDir := computeDiscoverCacheDir(filepath.Join(cacheDir, "discovery"), config.Host)
 
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// How long shell completion waits for the tilt session before giving up,
+// so that a missing session never blocks the shell.
+const completionTimeout = time.Second
+
+// Returns a cobra ValidArgsFunction that completes the NAME argument
+// with the names of FileWatches in the running tilt session.
+//
+// Arguments after NAME get restDirective, so that commands that take
+// PATHS can still complete them as files.
+func completeFileWatchNames(restDirective cobra.ShellCompDirective) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, restDirective
+		}
+
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctx, cancel := context.WithTimeout(ctx, completionTimeout)
+		defer cancel()
+
+		// If we can't reach the session, offer no completions rather than an error.
+		client, err := newDynamicClient(ctx)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return listFileWatchNames(ctx, client, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// Lists the names of FileWatches that start with prefix, or nil if they can't be listed.
+func listFileWatchNames(ctx context.Context, client dynamic.Interface, prefix string) []string {
+	list, err := client.Resource((&v1alpha1.FileWatch{}).GetGroupVersionResource()).
+		List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+	return objectNames(list, prefix)
+}
+
+// Returns the sorted names of the objects in list that start with prefix.
+func objectNames(list *unstructured.UnstructuredList, prefix string) []string {
+	var names []string
+	for _, item := range list.Items {
+		name := item.GetName()
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
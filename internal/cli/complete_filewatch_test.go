@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestObjectNames(t *testing.T) {
+	list := &unstructured.UnstructuredList{}
+	for _, name := range []string{"web", "src", "src-and-web"} {
+		item := unstructured.Unstructured{}
+		item.SetName(name)
+		list.Items = append(list.Items, item)
+	}
+
+	assert.Equal(t, []string{"src", "src-and-web", "web"}, objectNames(list, ""))
+	assert.Equal(t, []string{"src", "src-and-web"}, objectNames(list, "sr"))
+	assert.Empty(t, objectNames(list, "x"))
+	assert.Empty(t, objectNames(&unstructured.UnstructuredList{}, ""))
+}
+
+func TestListFileWatchNames(t *testing.T) {
+	client := newFileWatchListClient()
+	for _, name := range []string{"web", "src"} {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(v1alpha1.SchemeGroupVersion.WithKind("FileWatch"))
+		obj.SetName(name)
+		_, err := client.Resource((&v1alpha1.FileWatch{}).GetGroupVersionResource()).
+			Create(context.Background(), obj, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"src", "web"}, listFileWatchNames(context.Background(), client, ""))
+	assert.Equal(t, []string{"web"}, listFileWatchNames(context.Background(), client, "w"))
+}
+
+func TestListFileWatchNamesServerError(t *testing.T) {
+	client := newFileWatchListClient()
+	client.PrependReactor("list", "filewatches", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("connection refused")
+	})
+
+	assert.Nil(t, listFileWatchNames(context.Background(), client, ""))
+}
+
+func TestCompleteFileWatchNamesAfterName(t *testing.T) {
+	c := newCreateFileWatchCmd(genericclioptions.IOStreams{}).register()
+	names, directive := c.ValidArgsFunction(c, []string{"my-watch"}, "")
+	assert.Nil(t, names)
+	assert.Equal(t, cobra.ShellCompDirectiveDefault, directive)
+
+	c = newDeleteFileWatchCmd(genericclioptions.IOStreams{}).register()
+	names, directive = c.ValidArgsFunction(c, []string{"my-watch"}, "")
+	assert.Nil(t, names)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+}
+
+func newFileWatchListClient() *dynamicfake.FakeDynamicClient {
+	gvr := (&v1alpha1.FileWatch{}).GetGroupVersionResource()
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "FileWatchList"})
+}
@@ -1,37 +1,225 @@
 package cli
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
 	"os"
+	"os/signal"
+	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apiserver/pkg/storage/names"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/cmd/util/editor"
+	"sigs.k8s.io/yaml"
 
+	buildkitDockerignore "github.com/moby/buildkit/frontend/dockerfile/dockerignore"
+	tiltDockerignore "github.com/tilt-dev/dockerignore"
+	"github.com/tilt-dev/tilt-apiserver/pkg/server/builder/resource"
 	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/cli/visitor"
+	"github.com/tilt-dev/tilt/internal/dockerignore"
 	engineanalytics "github.com/tilt-dev/tilt/internal/engine/analytics"
+	"github.com/tilt-dev/tilt/internal/hud"
+	"github.com/tilt-dev/tilt/internal/hud/server"
+	"github.com/tilt-dev/tilt/internal/ignore"
+	"github.com/tilt-dev/tilt/internal/ospath"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
 )
 
+// The field manager that owns the fields `tilt create filewatch` sets,
+// unless --field-manager says otherwise.
+const createFieldManager = "tilt"
+
 // A human-friendly CLI for creating file watches.
 //
-// (as opposed to the machine-friendly CLIs of create -f or apply -f)
+// Builds FileWatches from NAME and PATHS, or reads them from a manifest
+// with -f. It also backs 'tilt apply filewatch', which applies them instead.
 type createFileWatchCmd struct {
 	helper *createHelper
 	cmd    *cobra.Command
 
-	ignoreValues []string
+	ignoreValues  []string
+	ignoreFiles   []string
+	ignoreFor     []string
+	ignoreGlobs   []string
+	onlyExt       []string
+	debounce      time.Duration
+	maxEvents     int32
+	since         string
+	fromSpec      string
+	allowMissing  bool
+	allowEscape   bool
+	wait          bool
+	waitTimeout   time.Duration
+	relativeTo    string
+	update        bool
+	ensure        bool
+	replace       bool
+	poll          bool
+	pollInterval  time.Duration
+	heartbeat     time.Duration
+	rateLimit     string
+	jsonPatch     string
+	noCollapse    bool
+	sortPaths     bool
+	recursive     bool
+	followLinks   bool
+	labels        []string
+	annotations   []string
+	description   string
+	filename      string
+	pathsFrom     string
+	trigger       bool
+	noAnalytics   bool
+	outputStatus  bool
+	disabled      bool
+	owner         string
+	excludeHidden bool
+	ignoreCase    bool
+	fromGitignore bool
+	envPrefix     string
+	ignoreBase    string
+	noWarnIgnores bool
+	noSummary     bool
+	printResolved bool
+	noExpand      bool
+	noCwdJoin     bool
+	requireAbs    bool
+	generateName  string
+	maxPaths      int
+	forKustomize  bool
+	ttl           time.Duration
+
+	// Print the files that the FileWatch would watch now, up to previewLimit of them, and exit.
+	previewMatches bool
+	previewLimit   int
+
+	// With --patch, the patterns to add to the ignores of an existing
+	// FileWatch, instead of creating one.
+	patch      bool
+	addIgnores []string
+
+	// Whether to check the tilt session's FileWatch API version against
+	// this CLI's, and whether a mismatch fails the command.
+	skipVersionCheck bool
+	strictVersion    bool
+
+	// How long the whole command may take. 0 means no limit.
+	timeout time.Duration
+
+	// Print how long each step of the command took to stderr.
+	timings bool
+
+	// Server-side apply the FileWatch instead of creating it. Set by `tilt apply filewatch`.
+	apply bool
+
+	// Warn about watched directories with more files than this. If zero, don't check.
+	largeDirThreshold int
+
+	// The object named by --owner, looked up in the tilt session.
+	ownerRef *metav1.OwnerReference
+
+	// With --trigger-resource, the resource whose builds the FileWatch triggers,
+	// and the ID of its target that the file changes go to.
+	linkedResource string
+	linkedTarget   string
+
+	// With --infer-paths-from, the resource whose FileWatches' paths to watch,
+	// and those paths.
+	inferPathsFrom string
+	inferredPaths  []string
+
+	// With --url, remote URLs to check for changes instead of local paths.
+	urls []string
+
+	// With --sync, the LOCAL:REMOTE pairs to copy file changes into a running
+	// container, and the container from --sync-service or --sync-container.
+	// The syncs are resolved along with the paths to watch.
+	syncs         []string
+	syncService   string
+	syncContainer string
+	resolvedSyncs []v1alpha1.LiveUpdateSync
+
+	// With --merge-ignores-from, the FileWatch whose ignores to copy, and those ignores.
+	mergeIgnoresFrom string
+	mergedIgnores    []v1alpha1.IgnoreDef
+
+	// With --inherit-ignores, the global ignores of the tilt session.
+	inheritIgnores   bool
+	inheritedIgnores []v1alpha1.IgnoreDef
+
+	// Triggers an update of a resource. Replaced in tests.
+	triggerResource func(resource string) error
+
+	// With --follow-logs, streams the session's logs to handler until the connection closes. Replaced in tests.
+	followLogs bool
+	streamLogs func(ctx context.Context, handler server.ViewHandler) error
+
+	// With --wait-for-change, prints each file change the FileWatch reports
+	// until interrupted. With --attach, of an existing FileWatch, instead of
+	// creating one.
+	waitForChange bool
+	attach        string
+	eventsFormat  string
+
+	// With --edit, opens the YAML in an editor and returns the edited YAML. Replaced in tests.
+	edit       bool
+	editObject func(original []byte) ([]byte, error)
+
+	// Unless --yes, watching the filesystem root or the home directory is
+	// confirmed if stdin is a terminal. Replaced in tests.
+	yes        bool
+	isTerminal func(in io.Reader) bool
+
+	// The directory to resolve relative paths against, as determined by
+	// --relative-to. If empty, uses the current directory.
+	baseDir string
 }
 
 var _ tiltCmd = &createFileWatchCmd{}
 
 func newCreateFileWatchCmd(streams genericclioptions.IOStreams) *createFileWatchCmd {
 	helper := newCreateHelper(streams)
+	helper.fieldManager = createFieldManager
+	helper.widePrinter = newFileWatchTablePrinter()
 	return &createFileWatchCmd{
-		helper: helper,
+		helper:          helper,
+		triggerResource: postTrigger,
+		editObject:      launchEditor,
+		streamLogs:      streamSessionLogs,
+		isTerminal:      isTerminalReader,
 	}
 }
 
@@ -46,7 +234,51 @@ func (c *createFileWatchCmd) register() *cobra.Command {
 
 To create a file watch, first supply the name of the
 watch so you can reference it later. Then supply the paths
-to watch. All paths will be watched recursively.
+to watch. Directories will be watched recursively. Files will
+be watched on their own, without the rest of their directory.
+
+Paths must exist, unless you pass --allow-missing to watch
+paths that will be created later.
+
+Paths inside another watched directory are dropped, since the
+directory's watch already covers them, and watching both would
+report each change twice. Pass --no-collapse to keep them.
+
+Watched paths keep the order they're given in. Globs, --paths-from,
+and --from-spec make that order harder to predict, so pass
+--sort-paths to sort them instead, e.g., to get the same FileWatch
+on every run for diffs.
+
+Relative paths are resolved against the current directory.
+A leading ~/ is expanded to your home directory, even if quoted.
+Use --relative-to=tiltfile to resolve them against the directory
+of the Tiltfile that the running tilt session loaded instead.
+Relative paths that climb out of that directory with '..' are an
+error, since they rarely mean to watch that much. Pass
+--allow-escape to watch them anyway.
+
+Paths may contain glob patterns, which are expanded against
+the filesystem. Use '**' to match any number of directories.
+Quote patterns so that your shell does not expand them first.
+A pattern that matches more than --max-paths paths (500 by default)
+is an error, since watching that many paths one by one is slow.
+
+Environment variables like $HOME or ${VAR} in paths and ignore
+patterns are expanded, even if quoted. Using an undefined variable
+is an error. Pass --no-expand to use the arguments as is.
+
+Tools that compute the paths to watch themselves can pass --no-cwd-join
+to watch PATHS verbatim, with none of the resolution above. Relative
+paths stay relative. Add --require-absolute to fail on them instead.
+
+To check what PATHS and the ignore flags resolve to, pass --print-resolved.
+It prints the watched paths and ignores, and exits without talking to
+the tilt session.
+
+To check that the ignores do what you expect, pass --preview-matches.
+It walks the watched paths, and prints the files in them that the ignores
+don't match, i.e., the files that would be watched now, sorted. Then it exits
+without talking to the tilt session. It stops after --preview-limit files.
 
 On its own, a FileWatch is an object that watches a set
 of files, and updates its status field with the most recent
@@ -54,98 +286,3889 @@ file changed.
 
 A FileWatch is intended to combine with other Tilt objects to
 trigger events when a file changes.
+
+Ignore patterns use .dockerignore syntax. A pattern starting with '!'
+re-includes paths ignored by an earlier --ignore or --ignore-file
+pattern. It can't re-include paths ignored by --ignore-for.
+
+Patterns are relative to the current directory. To anchor them somewhere
+else, e.g., at the root of a repository when running from a subdirectory,
+pass --ignore-base=DIR.
+
+Pass --exclude-hidden to ignore hidden files and directories, i.e.,
+anything whose name starts with a dot, like .git. To watch some of them
+anyway, re-include them with a pattern like --ignore='!**/.github'.
+
+To reuse the ignores of another FileWatch in the tilt session, pass
+--merge-ignores-from NAME. Its ignores with the same base path as this
+FileWatch's are merged into them, ahead of their patterns. The rest keep
+their own base paths, and are left out if they're unrelated to every
+watched path.
+
+Pass --from-gitignore to also ignore what git ignores. This reads the
+.gitignore files from the root of the git repository down to the current
+directory, and those inside the watched paths, each relative to its own
+directory. Like --ignore-for, --ignore can't re-include what they ignore.
+
+For CI systems that list the directories to ignore in environment variables,
+pass --from-env-prefix=PREFIX, e.g., --from-env-prefix=TILT_IGNORE_. The value
+of each environment variable whose name starts with PREFIX is an ignore
+pattern, like an --ignore value. They're added in order of the variables'
+names, before the --ignore patterns, so --ignore='!PATTERN' can re-include
+what they ignore.
+
+Symlinks in watched directories aren't followed, and watched paths
+that are symlinks are resolved to their targets. Pass --follow-symlinks
+to keep watched paths as they are, and also watch the targets of symlinks,
+with their changes reported at the symlink's path. A symlink to a watched
+path, or to a directory that contains one, isn't followed, so symlink
+cycles don't watch anything twice.
+
+By default, changes are detected with native filesystem notifications.
+On network mounts like NFS, where notifications are unreliable, pass --poll
+to check the watched paths for changes every --poll-interval instead.
+
+To start from an existing FileWatchSpec, pass a YAML file (or '-' for stdin)
+with --from-spec. Any PATHS are appended to the spec's watched paths, and
+flags like --ignore replace the corresponding fields of the spec.
+
+To read the paths to watch from a file (or '-' for stdin), one per line,
+pass --paths-from. Blank lines and lines starting with '#' are skipped.
+The paths are added after any PATHS, which may then be omitted.
+
+To watch the same paths as a resource of the tilt session, pass
+--infer-paths-from RESOURCE. This adds the paths watched by the resource's
+FileWatches, i.e., those with its tilt.dev/resource annotation, after any
+PATHS, which may then be omitted. It's an error if the resource doesn't
+watch any paths.
+
+To create several FileWatches at once, pass a YAML file of FileWatch
+objects (or '-' for stdin) with -f instead of NAME and PATHS. Relative
+paths in the file are resolved like PATHS. If some FileWatches fail to
+create, the rest are still created, and the failures are reported at the end,
+after a summary line like "3 created, 1 unchanged, 1 error.". Pass
+--no-summary to leave it out.
+
+To rebuild a resource of the tilt session whenever the files change,
+pass --trigger-resource NAME. This links the FileWatch to the resource
+with the tilt.dev/resource and tilt.dev/target annotations, like the
+FileWatches that the Tiltfile creates. The resource must exist.
+
+To run a build right away, pass --trigger. After the FileWatch is
+created, this triggers an update of the resource that it belongs to,
+i.e., the resource named by its tilt.dev/resource annotation.
+
+To delete the FileWatch along with another object, pass --owner KIND/NAME,
+e.g., --owner cmd/my-server. The object must already exist in the tilt
+session.
+
+To stage a FileWatch without starting it, pass --disabled. The FileWatch
+doesn't watch anything until you run 'tilt enable NAME', and 'tilt disable NAME'
+turns it back off.
+
+To see what the FileWatch reports once it starts watching, pass
+--output-status. This prints the FileWatch's status as YAML, instead
+of the created object. Unlike --wait, it doesn't fail if the status
+takes longer than --wait-timeout to fill in; it prints whatever status
+the FileWatch has at that point, with a warning.
+
+To debug a FileWatch that watches the wrong place, pass -v. Before
+creating the FileWatch, this prints the address of the tilt session's
+API server, and the resolved paths and ignores, to stderr.
+
+For a throwaway FileWatch, pass --generate-name instead of NAME. The
+FileWatch is named with the prefix "fw-" and a unique random suffix,
+and the name is printed once it's created. Pass --generate-name=PREFIX for another prefix.
+
+To clean up a FileWatch automatically, pass --ttl=DURATION, e.g., --ttl=1h.
+This sets the tilt.dev/expires-at annotation to the time the FileWatch
+expires, and the tilt session deletes it after that time. Anything else
+can set the annotation too, as an RFC3339 time.
+
+To note why a FileWatch exists for later readers, pass --description=TEXT.
+This sets the tilt.dev/description annotation, which 'tilt get
+filewatch-changes --show-paths' and 'tilt describe filewatch' show.
+
+For scripts that should be safe to re-run, pass --ensure. If a FileWatch
+with this name already exists, it's left alone when its spec and metadata
+already match, and updated otherwise. The output says whether the FileWatch
+was created, updated, or unchanged, and the command succeeds in all three cases.
+
+To start over instead, pass --replace. If a FileWatch with this name
+already exists, it's deleted, and the new one is created in its place.
+Unlike --update, the new FileWatch is a different object, so the status
+and file event history of the old one are lost.
+
+To review an update before making it, pass --update --dry-run=client. This
+reads the existing FileWatch from the tilt session and prints a unified diff
+of its spec against the new one, like 'kubectl diff', instead of updating it.
+Nothing is printed if the specs already match. If there's no FileWatch with
+this name yet, the whole spec shows up as added.
+
+To see why a FileWatch isn't firing, pass --follow-logs. After the FileWatch
+is created, this prints the lines of the tilt session's log that mention it
+until you press Ctrl-C, reconnecting if the session drops. Run 'tilt up'
+with --debug to see the watcher's debug logs, too.
+
+To check which changes a FileWatch reports, e.g., to verify its ignores,
+pass --wait-for-change. After the FileWatch is created, this prints the
+time and path of each file change it reports until you press Ctrl-C,
+like 'tail -f', reconnecting if the session drops. To do the same for a
+FileWatch that already exists, pass --wait-for-change --attach=NAME
+without any other arguments. For a script to read the changes,
+pass --output-events-as=json to print each one as a line of JSON instead.
+
+To watch remote files instead of local ones, pass --url=URL instead of
+PATHS. May be repeated. The FileWatch checks each URL with a HEAD request
+every --poll-interval (1m if not specified), and reports the URL as changed
+when its ETag or Last-Modified header changes. A FileWatch can't watch both
+URLs and local paths, so flags about local paths can't be used with --url.
+
+To copy the changes into a running container, like live_update's sync()
+in a Tiltfile, pass --sync=LOCAL:REMOTE. May be repeated. LOCAL is watched
+along with PATHS, and REMOTE must be an absolute path in the container.
+Pass --sync-service=SERVICE to sync into a Docker Compose service, or
+--sync-container=RESOURCE/CONTAINER to sync into a container of the pods
+of a Kubernetes resource. This creates a LiveUpdate with the same name as
+the FileWatch, which copies each change that the FileWatch reports.
+
+To ignore more files in a FileWatch that already exists, without creating
+it again, pass its NAME with --patch and --add-ignore=PATTERN. The patterns
+are added after the FileWatch's first ignores, relative to their base path,
+so they're relative to the current directory for a FileWatch created with
+'tilt create filewatch'. The FileWatch must exist.
+
+To review the FileWatch before creating it, pass --edit. This opens the
+FileWatch as YAML in the editor named by your TILT_EDITOR, VISUAL, or
+EDITOR environment variables (or vi, if none are set), and creates it as
+edited once you close the editor. If you don't change the file, or empty
+it, nothing is created.
+
+To capture the name in a script, e.g., name=$(tilt create fw ...), pass
+--output-name-only-on-success. Once the FileWatch is created, its name
+is printed to stdout, and the usual output (in the format of -o) goes to
+stderr with any warnings. If the command fails, nothing is printed to stdout.
+
+To preview the FileWatch without creating it, pass --dry-run=client.
+This prints the object without connecting to the tilt session.
+
+To set a field that doesn't have a flag yet, pass --json-patch with an
+RFC 6902 JSON patch, e.g., --json-patch='[{"op":"add","path":"/spec/maxEvents","value":10}]'.
+It's applied to the FileWatch after all the other flags, before it's created.
+--json-patch is unstable: it exposes the FileWatch's fields as they are today,
+so a patch may stop working when they change.
+
+To commit the FileWatch to a repo, e.g., for kustomize, pass -o yaml (or json)
+with --for-kustomize. This leaves out the fields that the tilt session fills
+in, like status, managedFields, resourceVersion, and uid, and owner references,
+since they only make sense in one session. Combine it with --dry-run=client
+to export the FileWatch without creating it.
+
+The exit code says why the command failed:
+  0  success
+  1  any other error
+  2  invalid arguments or FileWatch
+  3  the FileWatch already exists, or changed while updating it
+  4  the tilt session is unreachable
+  5  the command timed out (see --timeout and --wait-timeout)
+
+If the command is interrupted, e.g., with Ctrl-C, while it's talking to the
+tilt session or waiting with --wait, the requests in flight are canceled, and
+the command fails with an error that says it was canceled. Interrupting
+--follow-logs or --wait-for-change isn't a failure.
+
+Errors and warnings are printed to stderr, and only the FileWatch to stdout.
+If the tilt session rejects the FileWatch as invalid, the error lists each
+invalid field on its own line, with the flag or argument that set it.
+Pass --quiet-errors to print just the error, without the hints on how to
+fix it. The exit code doesn't change.
 `,
 		Aliases: []string{"fw"},
-		Args:    cobra.MinimumNArgs(2),
-		Example: `tilt create fw src-and-web src web --ignore=web/node_modules`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if c.filename != "" || c.attach != "" {
+				return cobra.NoArgs(cmd, args)
+			}
+			if c.patch {
+				return cobra.ExactArgs(1)(cmd, args)
+			}
+			// With --generate-name, there's no NAME, so every argument is a path.
+			minArgs := 2
+			if c.generateName != "" {
+				minArgs--
+			}
+			if c.fromSpec != "" || c.pathsFrom != "" || c.inferPathsFrom != "" || len(c.urls) > 0 {
+				minArgs--
+			}
+			return cobra.MinimumNArgs(minArgs)(cmd, args)
+		},
+		ValidArgsFunction: completeFileWatchNames(cobra.ShellCompDirectiveDefault),
+		Example: `tilt create fw src-and-web src web --ignore=web/node_modules
+
+tilt create fw go-src 'src/**/*.go'
+
+tilt create fw src src --ignore-file=.dockerignore --wait
+
+tilt create fw monorepo frontend backend --ignore-for=frontend:node_modules --ignore-for=backend:target
+
+cat spec.yaml | tilt create fw src-and-web --from-spec - --ignore=web/node_modules
+
+tilt create fw src src --relative-to=tiltfile
+
+tilt create fw nfs-src /mnt/nfs/src --poll --poll-interval=2s
+
+tilt create fw src src --dry-run=client -o yaml
+
+tilt create fw src src --dry-run=client -o yaml --for-kustomize > filewatch.yaml
+
+tilt create fw docs docs --label team=docs --annotation owner=jane@example.com
+
+tilt create fw protos api/proto --description "Regenerates the API clients"
+
+find . -name go.mod -execdir pwd \; | tilt create fw go-modules --paths-from -
+
+tilt create fw -f watches.yaml --update
+
+tilt create fw web-sources --infer-paths-from web
+
+tilt create fw src src --ensure
+
+tilt create fw src src --follow-logs
+
+tilt create fw src src --wait-for-change
+
+tilt create fw --generate-name src
+
+tilt create fw src src --edit
+
+tilt create fw src --patch --add-ignore='**/*.log'
+
+tilt create fw web-src web/src --annotation tilt.dev/resource=web --trigger`,
 	}
 
 	cmd.Flags().StringSliceVar(&c.ignoreValues, "ignore", nil,
-		"Patterns to ignore. Supports same syntax as .dockerignore. Paths are relative to the current directory.")
+		"Patterns to ignore. Supports same syntax as .dockerignore, including '!' to re-include paths ignored by an earlier pattern. Paths are relative to the current directory, or see --relative-to and --ignore-base. Watched paths outside that directory get the same patterns, relative to themselves.")
+	cmd.Flags().StringVar(&c.ignoreBase, "ignore-base", "",
+		"The directory that --ignore and --ignore-file patterns are relative to, instead of the current directory (or see --relative-to). Must be an existing directory.")
+	cmd.Flags().BoolVar(&c.excludeHidden, "exclude-hidden", false,
+		"Ignore hidden files and directories, like .git and .DS_Store. Pass --ignore='!PATTERN' to watch some of them anyway.")
+	cmd.Flags().BoolVar(&c.ignoreCase, "ignore-case", false,
+		"Match ignore patterns regardless of case, e.g., so that 'build' also ignores 'BUILD' on a case-insensitive filesystem.")
+	cmd.Flags().StringArrayVar(&c.ignoreFiles, "ignore-file", nil,
+		"Files of patterns to ignore, like a .dockerignore. Comments and blank lines are skipped. May be repeated.")
+	cmd.Flags().BoolVar(&c.fromGitignore, "from-gitignore", false,
+		"Also ignore what git ignores: the .gitignore files from the root of the git repository down to the current directory, and those inside the watched paths. "+
+			"Their patterns are matched on their own, so --ignore='!PATTERN' can't re-include what they ignore.")
+	cmd.Flags().StringVar(&c.envPrefix, "from-env-prefix", "",
+		"Also ignore the values of the environment variables whose names start with this prefix, e.g., TILT_IGNORE_, in order of their names. Each value is one pattern, like an --ignore value.")
+	cmd.Flags().StringArrayVar(&c.ignoreFor, "ignore-for", nil,
+		"A PATH:PATTERN pair of a pattern to ignore, relative to PATH instead of the current directory. May be repeated.")
+	cmd.Flags().StringArrayVar(&c.ignoreGlobs, "ignore-glob", nil,
+		"A glob of paths to ignore, e.g., '/tmp/build-*'. Expanded when the FileWatch is created, so paths that match later aren't ignored. Relative globs are relative to the current directory, or see --relative-to. May be repeated.")
+	cmd.Flags().StringSliceVar(&c.onlyExt, "only-ext", nil,
+		"Only watch files with these extensions, e.g., --only-ext=.go,.proto. Everything else is ignored, so --ignore can still ignore some of these files.")
+	cmd.Flags().DurationVar(&c.debounce, "debounce", 0,
+		"How long to wait for file changes to settle before reporting them (e.g., 250ms). If not specified, uses a short default.")
+	cmd.Flags().Int32Var(&c.maxEvents, "max-events", 0,
+		"The most file changes to batch together before reporting them, to bound memory use on busy directories. Must be at least 1. If not specified, batches are unbounded.")
+	cmd.Flags().StringVar(&c.since, "since", "",
+		"Report files modified after this time as changed when the watch starts. Either a duration ago (e.g., 5m) or an RFC3339 timestamp (e.g., 2006-01-02T15:04:05Z).")
+	cmd.Flags().BoolVar(&c.recursive, "recursive", true,
+		"Watch everything inside watched directories. With --recursive=false, only watch their immediate children.")
+	cmd.Flags().BoolVar(&c.followLinks, "follow-symlinks", false,
+		"Also watch the targets of symlinks in watched directories, and report their changes at the symlink's path. "+
+			"Watched paths are kept as symlinks instead of being resolved. Symlinks to a watched path, or to a directory that contains one, aren't followed, so symlink cycles are safe.")
+	cmd.Flags().BoolVar(&c.poll, "poll", false,
+		"Detect file changes by polling the filesystem instead of with native notifications. Useful for network mounts like NFS.")
+	cmd.Flags().DurationVar(&c.pollInterval, "poll-interval", time.Second,
+		"How often to poll for file changes with --poll. With --url, how often to check the URLs, 1m if not specified.")
+	cmd.Flags().DurationVar(&c.heartbeat, "heartbeat", 0,
+		"How often the FileWatch updates status.lastHeartbeatTime, even if no files changed, so that monitors can tell that it's still alive (e.g., 30s). If not specified, there's no heartbeat.")
+	cmd.Flags().StringVar(&c.jsonPatch, "json-patch", "",
+		"(Unstable) An RFC 6902 JSON patch to apply to the FileWatch before creating it, to set fields that don't have a flag yet.")
+	cmd.Flags().StringVar(&c.rateLimit, "rate-limit", "",
+		"The most file events to report per window, as N/DURATION (e.g., 5/10s). Up to N events are reported in a burst; changes that come in faster are held and reported together later. If not specified, events aren't limited.")
+	cmd.Flags().StringArrayVar(&c.labels, "label", nil,
+		"A KEY=VALUE label to add to the FileWatch. May be repeated.")
+	cmd.Flags().StringArrayVar(&c.annotations, "annotation", nil,
+		"A KEY=VALUE annotation to add to the FileWatch. May be repeated.")
+	cmd.Flags().StringVar(&c.description, "description", "",
+		"A note about why the FileWatch exists, for later readers. Sets the tilt.dev/description annotation.")
+	cmd.Flags().StringVarP(&c.filename, "filename", "f", "",
+		"Path to a YAML file of FileWatch objects to create, or '-' to read them from stdin.")
+	cmd.Flags().StringVar(&c.pathsFrom, "paths-from", "",
+		"Path to a file of paths to watch, one per line, or '-' to read them from stdin.")
+	cmd.Flags().StringArrayVar(&c.urls, "url", nil,
+		"An http or https URL to check for changes instead of local PATHS, by its ETag or Last-Modified header. May be repeated.")
+	cmd.Flags().StringArrayVar(&c.syncs, "sync", nil,
+		"A LOCAL:REMOTE pair of paths to watch LOCAL and copy its changes to REMOTE in a running container. Needs --sync-service or --sync-container. May be repeated.")
+	cmd.Flags().StringVar(&c.syncService, "sync-service", "",
+		"The Docker Compose service whose container --sync copies changes into.")
+	cmd.Flags().StringVar(&c.syncContainer, "sync-container", "",
+		"The container that --sync copies changes into, as RESOURCE/CONTAINER: the name of a container in the pods of the Kubernetes resource RESOURCE.")
+	cmd.Flags().StringVar(&c.inferPathsFrom, "infer-paths-from", "",
+		"The name of a resource in the tilt session whose watched paths to watch too.")
+	cmd.Flags().StringVar(&c.fromSpec, "from-spec", "",
+		"Path to a YAML FileWatchSpec to start from, or '-' to read it from stdin.")
+	cmd.Flags().BoolVar(&c.allowMissing, "allow-missing", false,
+		"Allow watching paths that don't exist yet.")
+	cmd.Flags().BoolVar(&c.allowEscape, "allow-escape", false,
+		"Allow relative paths that climb out of the current directory (or the Tiltfile's directory, with --relative-to=tiltfile) with '..'.")
+	cmd.Flags().IntVar(&c.maxPaths, "max-paths", 500,
+		"The most paths that a glob in PATHS may match. 0 means no limit.")
+	cmd.Flags().BoolVar(&c.noCollapse, "no-collapse", false,
+		"Watch exactly the paths specified, even if some are inside others.")
+	cmd.Flags().BoolVar(&c.sortPaths, "sort-paths", false,
+		"Sort the watched paths byte-wise, instead of keeping the order they're given in.")
+	cmd.Flags().BoolVarP(&c.helper.quiet, "quiet", "q", false,
+		"Only print the name of the created FileWatch.")
+	cmd.Flags().BoolVar(&c.helper.nameOnlyOnSuccess, "output-name-only-on-success", false,
+		"Print only the name of the created FileWatch to stdout, and the usual output to stderr.")
+
+	cmd.Flags().BoolVar(&c.wait, "wait", false,
+		"Wait until the FileWatch has started watching before printing it.")
+	cmd.Flags().BoolVar(&c.outputStatus, "output-status", false,
+		"After creating the FileWatch, wait for it to report its status, and print the status as YAML instead of the object.")
+	cmd.Flags().DurationVar(&c.waitTimeout, "wait-timeout", 30*time.Second,
+		"How long to wait with --wait or --output-status before giving up.")
+	cmd.Flags().StringVar(&c.owner, "owner", "",
+		"A KIND/NAME object in the tilt session that owns the FileWatch, so that deleting it deletes the FileWatch (e.g., cmd/my-server).")
+	cmd.Flags().BoolVar(&c.disabled, "disabled", false,
+		"Create the FileWatch disabled. Use 'tilt enable NAME' to start it.")
+	cmd.Flags().StringVar(&c.linkedResource, "trigger-resource", "",
+		"The name of a resource in the tilt session to rebuild whenever the watched files change.")
+	cmd.Flags().BoolVar(&c.trigger, "trigger", false,
+		"After creating the FileWatch, trigger an update of the resource it belongs to (see its tilt.dev/resource annotation).")
+	cmd.Flags().BoolVar(&c.followLogs, "follow-logs", false,
+		"After creating the FileWatch, print the lines of the tilt session's log that mention it, until interrupted.")
+	cmd.Flags().BoolVar(&c.waitForChange, "wait-for-change", false,
+		"After creating the FileWatch, print the time and path of each file change it reports, until interrupted.")
+	cmd.Flags().StringVar(&c.eventsFormat, "output-events-as", "text",
+		"With --wait-for-change, how to print file changes. One of: (text, json). json prints a JSON object per changed path, with the FileWatch's name, the path, the time, and the FileWatch's resourceVersion.")
+	cmd.Flags().StringVar(&c.attach, "attach", "",
+		"With --wait-for-change, print the file changes of the existing FileWatch with this name, instead of creating one.")
+	cmd.Flags().BoolVar(&c.edit, "edit", false,
+		"Open the FileWatch in an editor before creating it, and create it as edited.")
+	cmd.Flags().BoolVar(&c.patch, "patch", false,
+		"Add the --add-ignore patterns to the existing FileWatch named NAME, instead of creating one.")
+	cmd.Flags().StringArrayVar(&c.addIgnores, "add-ignore", nil,
+		"With --patch, a pattern to add to the FileWatch's ignores. May be repeated.")
+	cmd.Flags().BoolVar(&c.update, "update", false,
+		"If a FileWatch with this name already exists, update its spec instead of failing. With --dry-run=client, print a diff of the spec instead of updating it.")
+	cmd.Flags().BoolVar(&c.ensure, "ensure", false,
+		"Make sure a FileWatch with this name and spec exists. Like --update, but leaves an existing FileWatch that already matches alone, and reports it as unchanged.")
+	cmd.Flags().BoolVar(&c.replace, "replace", false,
+		"If a FileWatch with this name already exists, delete it and create this one in its place, instead of failing. Its status and file event history are lost.")
+	cmd.Flags().IntVar(&c.helper.retries, "retries", c.helper.retries,
+		"How many times to retry creating the FileWatch if the tilt session is unreachable or returns a server error.")
+	cmd.Flags().BoolVar(&c.noAnalytics, "no-analytics", false,
+		"Don't send analytics for this command, regardless of your analytics settings. Useful in CI.")
+	cmd.Flags().DurationVar(&c.timeout, "timeout", 0,
+		"How long to wait for the whole command, e.g., if the tilt session is stuck, before failing. 0 means no timeout.")
+	cmd.Flags().BoolVar(&c.noExpand, "no-expand", false,
+		"Use PATHS and ignore patterns as is, instead of expanding environment variables like $HOME or ${VAR} in them.")
+	cmd.Flags().BoolVar(&c.noCwdJoin, "no-cwd-join", false,
+		"Watch PATHS exactly as given, e.g., when a tool has already computed them, instead of joining relative paths to the current directory and expanding ~, globs, environment variables, and symlinks.")
+	cmd.Flags().BoolVar(&c.requireAbs, "require-absolute", false,
+		"Fail if any of PATHS isn't an absolute path, after expanding ~ and environment variables like $HOME.")
+	cmd.Flags().BoolVar(&c.strictVersion, "strict-version", false,
+		"Fail if the tilt session serves FileWatches at a different API version than this tilt CLI, instead of warning.")
+	cmd.Flags().BoolVar(&c.skipVersionCheck, "skip-version-check", false,
+		"Don't check the tilt session's FileWatch API version against this tilt CLI's.")
+	cmd.Flags().BoolVar(&c.timings, "timings", false,
+		"Print how long client setup, the API version check, path resolution, and creating the FileWatch each took to stderr, e.g., to see why the command is slow.")
+	cmd.Flags().StringVar(&c.mergeIgnoresFrom, "merge-ignores-from", "",
+		"The name of another FileWatch in the tilt session whose ignores to add to this one's.")
+	cmd.Flags().BoolVar(&c.inheritIgnores, "inherit-ignores", false,
+		"Also ignore what the running tilt session ignores everywhere, like the patterns in .tiltignore and watch_settings(ignore=...). Their patterns come before the other ignores, so '!' patterns can re-include paths.")
+	cmd.Flags().BoolVar(&c.yes, "yes", false,
+		"Don't ask for confirmation before watching the filesystem root or your home directory.")
+	cmd.Flags().BoolVar(&c.noWarnIgnores, "no-warn-ignores", false,
+		"Don't warn about ignores whose base path is unrelated to every watched path.")
+	cmd.Flags().BoolVar(&c.noSummary, "no-summary", false,
+		"With -f, don't print how many FileWatches were created, unchanged, or failed at the end.")
+	cmd.Flags().BoolVar(&c.forKustomize, "for-kustomize", false,
+		"With -o yaml or -o json, leave out the fields that the tilt session fills in, like status and resourceVersion, so that the output can be committed, e.g., for kustomize.")
+	cmd.Flags().BoolVar(&c.printResolved, "print-resolved", false,
+		"Only print the watched paths and ignores, after expanding globs, ~, environment variables, and symlinks, and exit. Doesn't talk to the tilt session.")
+	cmd.Flags().BoolVar(&c.previewMatches, "preview-matches", false,
+		"Only print the files that the FileWatch would watch now, i.e., the files in the watched paths that the ignores don't match, and exit. Doesn't talk to the tilt session.")
+	cmd.Flags().IntVar(&c.previewLimit, "preview-limit", 1000,
+		"With --preview-matches, the most files to print. The walk stops once it finds more.")
+	cmd.Flags().IntVar(&c.largeDirThreshold, "large-dir-threshold", 10000,
+		"Warn if a watched directory has more than this many files that aren't ignored. 0 disables the warning.")
+	cmd.Flags().StringVar(&c.generateName, "generate-name", "",
+		"Instead of NAME, generate a unique name for the FileWatch, starting with PREFIX (\"fw-\" if not specified). Use --generate-name=PREFIX.")
+	cmd.Flags().Lookup("generate-name").NoOptDefVal = "fw-"
+	cmd.Flags().DurationVar(&c.ttl, "ttl", 0,
+		"Delete the FileWatch from the tilt session this long after it's created (e.g., 1h). If not specified, it's kept until deleted.")
+	cmd.Flags().StringVar(&c.relativeTo, "relative-to", "cwd",
+		"What relative paths are resolved against. One of: (cwd, tiltfile). "+
+			"With 'tiltfile', uses the directory of the Tiltfile that the running tilt session loaded.")
 
 	c.helper.addFlags(cmd)
+	c.helper.addDryRunFlag(cmd)
+	c.helper.addErrorFormatFlag(cmd)
+	cmdutil.AddFieldManagerFlagVar(cmd, &c.helper.fieldManager, c.helper.fieldManager)
+	cmd.MarkFlagsMutuallyExclusive("quiet", "output")
+	cmd.MarkFlagsMutuallyExclusive("output-status", "output")
+	cmd.MarkFlagsMutuallyExclusive("output-status", "quiet")
+	cmd.MarkFlagsMutuallyExclusive("output-name-only-on-success", "quiet")
+	cmd.MarkFlagsMutuallyExclusive("output-name-only-on-success", "output-status")
+	cmd.MarkFlagsMutuallyExclusive("edit", "filename")
+	cmd.MarkFlagsMutuallyExclusive("for-kustomize", "output-status")
+	cmd.MarkFlagsMutuallyExclusive("follow-logs", "filename")
+	cmd.MarkFlagsMutuallyExclusive("wait-for-change", "filename")
+	cmd.MarkFlagsMutuallyExclusive("wait-for-change", "follow-logs")
+	cmd.MarkFlagsMutuallyExclusive("ensure", "update")
+	cmd.MarkFlagsMutuallyExclusive("replace", "update")
+	cmd.MarkFlagsMutuallyExclusive("replace", "ensure")
+	cmd.MarkFlagsMutuallyExclusive("strict-version", "skip-version-check")
+	// These need the tilt session, or only make sense when creating it.
+	for _, sessionFlag := range []string{"dry-run", "inherit-ignores", "merge-ignores-from", "edit", "wait", "output-status", "trigger", "follow-logs", "wait-for-change"} {
+		cmd.MarkFlagsMutuallyExclusive("print-resolved", sessionFlag)
+		cmd.MarkFlagsMutuallyExclusive("preview-matches", sessionFlag)
+	}
+	cmd.MarkFlagsMutuallyExclusive("preview-matches", "print-resolved")
+	cmd.MarkFlagsMutuallyExclusive("preview-matches", "filename")
+	// These only make sense when creating the FileWatch.
+	for _, createFlag := range []string{"filename", "print-resolved", "preview-matches", "generate-name", "update", "ensure", "replace", "edit", "disabled", "wait", "output-status", "trigger", "ttl"} {
+		cmd.MarkFlagsMutuallyExclusive("attach", createFlag)
+	}
+	// --patch only changes the ignores of an existing FileWatch.
+	for _, createFlag := range []string{"filename", "print-resolved", "generate-name", "update", "ensure", "replace", "edit", "disabled", "attach", "from-spec", "paths-from", "ignore", "ignore-file", "ignore-for", "ignore-glob", "ttl", "sync"} {
+		cmd.MarkFlagsMutuallyExclusive("patch", createFlag)
+	}
+	// These need to know the name before the FileWatch is created.
+	for _, nameFlag := range []string{"filename", "update", "ensure", "replace", "disabled"} {
+		cmd.MarkFlagsMutuallyExclusive("generate-name", nameFlag)
+	}
+	// These only apply to local paths.
+	for _, pathFlag := range []string{"from-spec", "paths-from", "infer-paths-from", "exclude-hidden", "ignore", "ignore-base", "ignore-case", "ignore-file", "from-gitignore", "from-env-prefix", "ignore-for", "ignore-glob", "only-ext", "since", "recursive", "follow-symlinks", "inherit-ignores", "merge-ignores-from", "poll", "max-paths", "no-expand", "no-cwd-join", "require-absolute", "preview-matches", "sync"} {
+		cmd.MarkFlagsMutuallyExclusive("url", pathFlag)
+	}
+	// The objects in a -f file have complete specs of their own.
+	for _, specFlag := range []string{"url", "sync", "from-spec", "paths-from", "infer-paths-from", "exclude-hidden", "ignore", "ignore-base", "ignore-case", "ignore-file", "from-gitignore", "from-env-prefix", "ignore-for", "ignore-glob", "only-ext", "debounce", "max-events", "since", "recursive", "follow-symlinks", "inherit-ignores", "merge-ignores-from", "poll", "poll-interval", "heartbeat", "rate-limit", "json-patch"} {
+		cmd.MarkFlagsMutuallyExclusive("filename", specFlag)
+	}
 	c.cmd = cmd
 
 	return cmd
 }
 
 func (c *createFileWatchCmd) run(ctx context.Context, args []string) error {
-	a := analytics.Get(ctx)
+	if err := c.helper.checkErrorFormat(); err != nil {
+		return c.helper.fail(err)
+	}
+	if c.timeout < 0 {
+		return c.helper.fail(usageErrorf("--timeout must not be negative, got %s", c.timeout))
+	}
+	if c.maxPaths < 0 {
+		return c.helper.fail(usageErrorf("--max-paths must not be negative, got %d", c.maxPaths))
+	}
+	if strings.TrimSpace(c.helper.fieldManager) == "" {
+		return c.helper.fail(usageErrorf("--field-manager cannot be empty"))
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	// Debug logs go to stderr with the command's other messages,
+	// instead of mixing with the objects printed to stdout.
+	ctx = logger.WithLogger(ctx, logger.NewLogger(logger.Get(ctx).Level(), c.helper.streams.ErrOut))
+
+	// Embedders may not trap signals, so a Ctrl-C would kill the process
+	// with requests in flight, instead of ending the command.
+	ctx, stopSignals := cancelOnSignal(ctx)
+	err := explainFieldErrors(c.create(ctx, args))
+	if sig := stopSignals(); sig != nil && err != nil {
+		err = canceledError{signal: sig, err: err}
+	}
+	// A command that finishes in time, like --follow-logs, isn't a failure.
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = timeoutError{timeout: c.timeout, err: err}
+	}
+	return c.helper.fail(err)
+}
+
+// Returned when the command doesn't finish within --timeout.
+type timeoutError struct {
+	timeout time.Duration
+	err     error
+}
+
+func (e timeoutError) Error() string {
+	return fmt.Sprintf("timed out after %s (see --timeout): %v", e.timeout, e.err)
+}
+
+func (e timeoutError) Unwrap() error {
+	return e.err
+}
+
+// Returned when the command is interrupted by a signal before it finishes.
+type canceledError struct {
+	signal os.Signal
+	err    error
+}
+
+func (e canceledError) Error() string {
+	return fmt.Sprintf("canceled by %s", e.signal)
+}
+
+func (e canceledError) Unwrap() error {
+	return e.err
+}
+
+// Cancels the context when the process gets SIGINT or SIGTERM, so that
+// requests in flight abort.
+//
+// The returned func removes the signal handler, and returns the signal that
+// canceled the context, or nil if none did. Call it once the command finishes.
+func cancelOnSignal(ctx context.Context) (context.Context, func() os.Signal) {
+	ctx, cancel := context.WithCancel(ctx)
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+
+	done := make(chan struct{})
+	received := make(chan os.Signal, 1)
+	go func() {
+		select {
+		case sig := <-sigs:
+			cancel()
+			received <- sig
+		case <-done:
+			received <- nil
+		}
+	}()
+
+	return ctx, func() os.Signal {
+		signal.Stop(sigs)
+		close(done)
+		cancel()
+		return <-received
+	}
+}
+
+// The flags that set each field of a FileWatch, so that validation errors
+// from the tilt session can say which flag to fix. List indexes are dropped
+// before the lookup, e.g., spec.ignores[0].patterns is looked up as
+// spec.ignores.patterns. Keep this up to date when adding flags.
+var fileWatchFieldFlags = map[string]string{
+	"metadata.name":            "NAME",
+	"metadata.labels":          "--label",
+	"metadata.annotations":     "--annotation",
+	"metadata.ownerReferences": "--owner",
+	"spec.watchedPaths":        "PATHS",
+	"spec.ignores":             "--ignore",
+	"spec.ignores.basePath":    "--ignore-for",
+	"spec.ignores.patterns":    "--ignore",
+	"spec.ignores.ignoreCase":  "--ignore-case",
+	"spec.debounceDuration":    "--debounce",
+	"spec.mode":                "--poll",
+	"spec.pollInterval":        "--poll-interval",
+	"spec.maxEvents":           "--max-events",
+	"spec.since":               "--since",
+	"spec.nonRecursive":        "--recursive",
+	"spec.followSymlinks":      "--follow-symlinks",
+	"spec.heartbeatInterval":   "--heartbeat",
+	"spec.urls":                "--url",
+	"spec.rateLimitEvents":     "--rate-limit",
+	"spec.rateLimitWindow":     "--rate-limit",
+}
+
+// Matches the list indexes in a field path, like the [0] in spec.ignores[0].patterns.
+var fieldIndexPattern = regexp.MustCompile(`\[[^\]]*\]`)
+
+// Returned when the tilt session rejects a FileWatch as invalid, with a line
+// for each invalid field that names the flag that set it.
+type fieldErrorsError struct {
+	name  string
+	lines []string
+	err   error
+}
+
+func (e fieldErrorsError) Error() string {
+	return fmt.Sprintf("FileWatch %q is invalid:\n  %s", e.name, strings.Join(e.lines, "\n  "))
+}
+
+func (e fieldErrorsError) Unwrap() error {
+	return e.err
+}
+
+// Rewrites the tilt session's validation errors as a "field: reason" line per
+// invalid field, instead of the one long line that the apiserver returns.
+// All other errors are returned unchanged.
+func explainFieldErrors(err error) error {
+	var status apierrors.APIStatus
+	if !apierrors.IsInvalid(err) || !errors.As(err, &status) {
+		return err
+	}
+	details := status.Status().Details
+	if details == nil {
+		return err
+	}
+
+	var lines []string
+	for _, cause := range details.Causes {
+		if cause.Field == "" {
+			continue
+		}
+		line := fmt.Sprintf("%s: %s", cause.Field, cause.Message)
+		if flag, ok := fileWatchFieldFlags[fieldIndexPattern.ReplaceAllString(cause.Field, "")]; ok {
+			line += fmt.Sprintf(" (see %s)", flag)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return err
+	}
+	return fieldErrorsError{name: details.Name, lines: lines, err: err}
+}
+
+func (c *createFileWatchCmd) create(ctx context.Context, args []string) error {
+	if c.printResolved {
+		return c.printResolvedFileWatches(ctx, args)
+	}
+	if c.previewMatches {
+		return c.printMatchingFiles(ctx, args)
+	}
+
+	// Counts are added to the tags once the FileWatches are built,
+	// so they're reported when the command finishes.
 	cmdTags := engineanalytics.CmdTags(map[string]string{})
-	a.Incr("cmd.create-filewatch", cmdTags.AsMap())
-	defer a.Flush(time.Second)
+	if !c.noAnalytics {
+		a := analytics.Get(ctx)
+		metric := "cmd.create-filewatch"
+		if c.apply {
+			metric = "cmd.apply-filewatch"
+		}
+		defer func() {
+			a.Incr(metric, cmdTags.AsMap())
+			a.Flush(time.Second)
+		}()
+	}
+
+	// Catch malformed labels before talking to the tilt session.
+	_, _, err := c.metadata()
+	if err != nil {
+		return usageError{err: err}
+	}
+	if c.attach != "" && !c.waitForChange {
+		return usageErrorf("--attach can only be used with --wait-for-change")
+	}
+	jsonEvents, err := isJSONEventsFormat(c.eventsFormat)
+	if err != nil {
+		return usageError{err: err}
+	}
+	if jsonEvents && !c.waitForChange {
+		return usageErrorf("--output-events-as can only be used with --wait-for-change")
+	}
+	if c.patch != (len(c.addIgnores) > 0) {
+		return usageErrorf("--patch and --add-ignore must be used together")
+	}
+	if c.forKustomize {
+		switch format := *c.helper.printFlags.OutputFormat; format {
+		case "yaml", "json":
+		default:
+			return usageErrorf("--for-kustomize needs -o yaml or -o json, got %q", format)
+		}
+	}
+	if c.disabled && (c.wait || c.trigger) {
+		return usageErrorf("--disabled can't be used with --wait or --trigger, since a disabled FileWatch doesn't watch")
+	}
+	if c.jsonPatch != "" {
+		_, err = decodeJSONPatch(c.jsonPatch)
+		if err != nil {
+			return usageError{err: err}
+		}
+	}
+	err = c.validateSyncFlags()
+	if err != nil {
+		return usageError{err: err}
+	}
 
-	err := c.helper.interpretFlags(ctx)
+	start := time.Now()
+	err = c.helper.interpretFlags(ctx)
+	if err == nil && c.helper.dryRun == dryRunClient && c.update {
+		// Diffs against the existing FileWatch, so needs the tilt session after all.
+		err = c.helper.connect(ctx)
+	}
+	c.printTiming("client setup", start)
 	if err != nil {
 		return err
 	}
 
-	fw, err := c.object(args)
+	if c.helper.dryRun == dryRunClient && c.wait {
+		return usageErrorf("--wait can't be used with --dry-run")
+	}
+	if c.helper.dryRun == dryRunClient && c.trigger {
+		return usageErrorf("--trigger can't be used with --dry-run")
+	}
+	if c.helper.dryRun == dryRunClient && c.outputStatus {
+		return usageErrorf("--output-status can't be used with --dry-run")
+	}
+	if c.helper.dryRun == dryRunClient && c.followLogs {
+		return usageErrorf("--follow-logs can't be used with --dry-run")
+	}
+	if c.helper.dryRun == dryRunClient && c.waitForChange {
+		return usageErrorf("--wait-for-change can't be used with --dry-run")
+	}
+	if c.helper.dryRun == dryRunClient && c.patch {
+		return usageErrorf("--patch can't be used with --dry-run, since it needs the existing FileWatch")
+	}
+
+	start = time.Now()
+	err = c.checkAPIVersion(ctx)
+	c.printTiming("API version check", start)
 	if err != nil {
 		return err
 	}
 
-	return c.helper.create(ctx, fw)
-}
+	if c.attach != "" {
+		return c.waitForFileChanges(ctx, c.attach)
+	}
 
-// Interprets the flags specified on the commandline to the FileWatch to create.
-func (c *createFileWatchCmd) object(args []string) (*v1alpha1.FileWatch, error) {
-	name := args[0]
-	pathArgs := args[1:]
+	err = c.resolveBaseDir(ctx)
+	if err != nil {
+		return err
+	}
+
+	if c.patch {
+		return c.patchIgnores(ctx, args[0])
+	}
 
-	paths, err := c.paths(pathArgs)
+	err = c.resolveOwner(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	ignores, err := c.ignores()
+	err = c.resolveLinkedResource(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	fw := v1alpha1.FileWatch{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: name,
-		},
-		Spec: v1alpha1.FileWatchSpec{
-			WatchedPaths: paths,
-			Ignores:      ignores,
-		},
+	err = c.resolveInferredPaths(ctx)
+	if err != nil {
+		return err
 	}
-	return &fw, nil
-}
 
-// Interprets the paths specified on the commandline.
-func (c *createFileWatchCmd) paths(pathArgs []string) ([]string, error) {
-	result := []string{}
-	cwd, err := os.Getwd()
+	err = c.resolveMergedIgnores(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	for _, path := range pathArgs {
-		if filepath.IsAbs(path) {
-			result = append(result, path)
-		} else {
-			result = append(result, filepath.Join(cwd, path))
+	err = c.resolveInheritedIgnores(ctx)
+	if err != nil {
+		return err
+	}
+
+	if (c.wait || c.outputStatus) && c.waitTimeout <= 0 {
+		return usageErrorf("--wait-timeout must be positive, got %s", c.waitTimeout)
+	}
+
+	if c.filename != "" {
+		start = time.Now()
+		fws, err := c.fileObjects()
+		c.printTiming("path resolution", start)
+		if err != nil {
+			return usageError{err: err}
+		}
+		addCountTags(cmdTags, fws)
+		err = c.confirmDangerousPaths(fws)
+		if err != nil {
+			return err
 		}
+		return c.createFromFile(ctx, fws)
 	}
-	return result, nil
+
+	start = time.Now()
+	fw, err := c.object(args)
+	c.printTiming("path resolution", start)
+	if err != nil {
+		return usageError{err: err}
+	}
+	if c.edit {
+		fw, err = c.editFileWatch(fw)
+		if err != nil {
+			return usageError{err: err}
+		}
+		if fw == nil {
+			return nil
+		}
+	}
+	addCountTags(cmdTags, []*v1alpha1.FileWatch{fw})
+	err = c.confirmDangerousPaths([]*v1alpha1.FileWatch{fw})
+	if err != nil {
+		return err
+	}
+	return c.createAndPrint(ctx, fw)
 }
 
-// Interprets the ignores specified on the commandline.
-func (c *createFileWatchCmd) ignores() ([]v1alpha1.IgnoreDef, error) {
-	result := v1alpha1.IgnoreDef{}
-	cwd, err := os.Getwd()
+// With --timings, prints how long a step of the command took since start.
+func (c *createFileWatchCmd) printTiming(step string, start time.Time) {
+	if !c.timings {
+		return
+	}
+	_, _ = fmt.Fprintf(c.helper.streams.ErrOut, "Timing: %s took %s\n", step, time.Since(start).Round(time.Microsecond))
+}
+
+// Asks for confirmation before watching the filesystem root or the home
+// directory, since watching that much can slow down the whole machine.
+//
+// Doesn't ask with --yes, with --dry-run, or if stdin isn't a terminal,
+// e.g., in scripts. Only continues if the answer is yes.
+func (c *createFileWatchCmd) confirmDangerousPaths(fws []*v1alpha1.FileWatch) error {
+	if c.yes || c.helper.dryRun == dryRunClient || !c.isTerminal(c.helper.streams.In) {
+		return nil
+	}
+
+	var dangerous []string
+	for _, fw := range fws {
+		for _, path := range fw.Spec.WatchedPaths {
+			if isDangerousWatchPath(path) {
+				dangerous = append(dangerous, path)
+			}
+		}
+	}
+	if len(dangerous) == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(c.helper.streams.ErrOut,
+		"Watching %s can slow down the whole machine. Watch it anyway? [y/N] ", strings.Join(dangerous, ", "))
+	answer, err := bufio.NewReader(c.helper.streams.In).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return fmt.Errorf("not watching %s, since it wasn't confirmed (pass --yes to skip the confirmation)", strings.Join(dangerous, ", "))
+}
+
+// Whether a watched path is the filesystem root or the home directory.
+func isDangerousWatchPath(path string) bool {
+	if filepath.Dir(path) == path {
+		return true
+	}
+	home, err := os.UserHomeDir()
+	return err == nil && canonicalPath(home) == canonicalPath(path)
+}
+
+// Whether in is a terminal that a person can answer prompts on.
+func isTerminalReader(in io.Reader) bool {
+	f, ok := in.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// Adds how many paths the FileWatches watch and how many ignore patterns
+// they have to the analytics tags. Only buckets are reported, never the
+// paths themselves.
+func addCountTags(cmdTags engineanalytics.CmdTags, fws []*v1alpha1.FileWatch) {
+	paths := 0
+	ignores := 0
+	for _, fw := range fws {
+		paths += len(fw.Spec.WatchedPaths)
+		for _, ignore := range fw.Spec.Ignores {
+			ignores += len(ignore.Patterns)
+		}
+	}
+	cmdTags["paths"] = countBucket(paths)
+	cmdTags["ignores"] = countBucket(ignores)
+}
+
+// Rounds a count into a coarse bucket, so that analytics
+// can't be used to identify a particular project.
+func countBucket(n int) string {
+	switch {
+	case n <= 0:
+		return "0"
+	case n == 1:
+		return "1"
+	case n <= 5:
+		return "2-5"
+	default:
+		return "6+"
+	}
+}
+
+// Creates (or with --update or --ensure, updates) a FileWatch and prints the result.
+func (c *createFileWatchCmd) createAndPrint(ctx context.Context, fw *v1alpha1.FileWatch) error {
+	err := c.helper.setOperation(c.helper.createdVerb)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if len(c.ignoreValues) == 0 {
-		return nil, nil
+	// Overloads the global -v flag, like `tilt alpha tiltfile-result`.
+	if verbose {
+		c.printDiagnostics(fw)
+	}
+
+	if c.disabled {
+		fw.Spec.DisableSource = disableSourceForFileWatch(fw.Name)
 	}
 
-	result.BasePath = cwd
-	result.Patterns = append([]string{}, c.ignoreValues...)
-	return []v1alpha1.IgnoreDef{result}, nil
+	if c.update && c.helper.dryRun == dryRunClient {
+		return c.printSpecDiff(ctx, fw)
+	}
+
+	start := time.Now()
+	result, err := c.createOrUpdate(ctx, fw)
+	c.printTiming("create", start)
+	if err != nil {
+		return err
+	}
+	// With --generate-name, the name is only known once the FileWatch is created.
+	fw.Name = result.GetName()
+	c.warnIfIgnoreCaseDropped(fw, result)
+
+	if c.disabled {
+		err = c.disable(ctx, fw)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.syncToContainer(ctx, fw)
+	if err != nil {
+		return err
+	}
+
+	if c.wait {
+		result, err = c.waitForMonitor(ctx, fw)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.outputStatus {
+		err = c.printStatus(ctx, fw)
+	} else if c.forKustomize {
+		err = c.helper.print(exportableObject(result))
+	} else {
+		err = c.helper.print(result)
+	}
+	if err != nil {
+		return err
+	}
+
+	if c.trigger {
+		err = c.triggerOwner(result)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.followLogs {
+		return c.followFileWatchLogs(ctx, fw.Name)
+	}
+	if c.waitForChange {
+		return c.waitForFileChanges(ctx, fw.Name)
+	}
+	return nil
+}
+
+// Adds the --add-ignore patterns to the ignores of an existing FileWatch,
+// with a JSON merge patch, and prints the result.
+//
+// Merge patches replace lists whole, so the patch has all of the ignores.
+// It also has the resourceVersion they were read at, so that it fails
+// instead of dropping ignores added in the meantime.
+func (c *createFileWatchCmd) patchIgnores(ctx context.Context, name string) error {
+	patterns, err := c.expandEnv("--add-ignore", c.addIgnores)
+	if err != nil {
+		return usageError{err: err}
+	}
+
+	client := c.helper.dynamicClient.Resource((&v1alpha1.FileWatch{}).GetGroupVersionResource())
+	existing, err := client.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("no FileWatch named %s in the tilt session (use 'tilt create filewatch' without --patch to create it)", name)
+	}
+	if err != nil {
+		return wrapNoSessionError(err)
+	}
+
+	var fw v1alpha1.FileWatch
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(existing.Object, &fw)
+	if err != nil {
+		return err
+	}
+
+	ignores := append([]v1alpha1.IgnoreDef{}, fw.Spec.Ignores...)
+	if len(ignores) == 0 {
+		dir, err := c.dir()
+		if err != nil {
+			return err
+		}
+		ignores = append(ignores, v1alpha1.IgnoreDef{BasePath: dir})
+	}
+	first := ignores[0]
+	first.Patterns = dedupePatterns(append(append([]string{}, first.Patterns...), patterns...))
+	err = validateIgnores([]v1alpha1.IgnoreDef{{BasePath: first.BasePath, Patterns: patterns}})
+	if err != nil {
+		return usageError{err: err}
+	}
+	ignores[0] = first
+
+	patch, err := fileWatchIgnoresPatch(existing.GetResourceVersion(), ignores)
+	if err != nil {
+		return err
+	}
+	result, err := client.Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: c.helper.fieldManager})
+	if err != nil {
+		return wrapNoSessionError(err)
+	}
+
+	err = c.helper.setOperation("patched")
+	if err != nil {
+		return err
+	}
+	return c.helper.print(result)
+}
+
+// A JSON merge patch that replaces the ignores of a FileWatch,
+// if it's still at resourceVersion.
+func fileWatchIgnoresPatch(resourceVersion string, ignores []v1alpha1.IgnoreDef) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"resourceVersion": resourceVersion},
+		"spec":     map[string]interface{}{"ignores": ignores},
+	})
+}
+
+// The metadata fields that the tilt session fills in, or that only make
+// sense in one session.
+var sessionMetadataFields = []string{
+	"creationTimestamp",
+	"deletionGracePeriodSeconds",
+	"deletionTimestamp",
+	"generation",
+	"managedFields",
+	"ownerReferences",
+	"resourceVersion",
+	"selfLink",
+	"uid",
+}
+
+// A copy of an object without its status and session-specific metadata,
+// for --for-kustomize.
+func exportableObject(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	result := obj.DeepCopy()
+	unstructured.RemoveNestedField(result.Object, "status")
+	for _, field := range sessionMetadataFields {
+		unstructured.RemoveNestedField(result.Object, "metadata", field)
+	}
+	return result
+}
+
+// Prints each file change that the FileWatch reports, until the context is canceled.
+//
+// If the connection drops, reconnects like `tilt get filewatch-changes --watch`.
+func (c *createFileWatchCmd) waitForFileChanges(ctx context.Context, name string) error {
+	get := newGetFileWatchCmd(c.helper.streams)
+	get.printer = newFileChangesPrinter()
+	jsonEvents, err := isJSONEventsFormat(c.eventsFormat)
+	if err != nil {
+		return usageError{err: err}
+	}
+	if jsonEvents {
+		get.printer = newFileChangesJSONPrinter()
+	}
+
+	client := c.helper.dynamicClient.Resource((&v1alpha1.FileWatch{}).GetGroupVersionResource())
+	resourceVersion, err := get.get(ctx, client, name)
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("no FileWatch named %s in the tilt session", name)
+	}
+	if err != nil {
+		return wrapNoSessionError(err)
+	}
+
+	_, _ = fmt.Fprintf(c.helper.streams.ErrOut, "Waiting for file changes in FileWatch %s. Press Ctrl-C to stop.\n", name)
+	return get.watchLoop(ctx, client, name, resourceVersion)
+}
+
+// Prints the lines of the session's log that mention the FileWatch, until the context is canceled.
+//
+// If the connection drops, reconnects with exponential backoff, like `tilt get filewatch-changes --watch`.
+func (c *createFileWatchCmd) followFileWatchLogs(ctx context.Context, name string) error {
+	printer := hud.NewIncrementalPrinter(hud.Stdout(c.helper.streams.Out))
+	// Shared across connections, so that lines aren't printed again after reconnecting.
+	streamer := server.NewFilteredLogStreamer(nil, func(line logstore.LogLine) bool {
+		return mentionsName(line.Text, name)
+	}, printer)
+
+	backoff := getFileWatchMinBackoff
+	for {
+		err := c.streamLogs(ctx, streamer)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(c.helper.streams.ErrOut, "Streaming logs: %v\n", err)
+		} else {
+			// We got through to the session, so start over.
+			backoff = getFileWatchMinBackoff
+		}
+		_, _ = fmt.Fprintf(c.helper.streams.ErrOut, "Connection lost. Reconnecting in %s...\n", backoff)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > getFileWatchMaxBackoff {
+			backoff = getFileWatchMaxBackoff
+		}
+	}
+}
+
+// Streams the logs of the tilt session at --host and --port to handler.
+func streamSessionLogs(ctx context.Context, handler server.ViewHandler) error {
+	url, err := provideWebURL(provideWebHost(), provideWebPort())
+	if err != nil {
+		return err
+	}
+	return server.StreamLogsTo(ctx, true, url, handler)
+}
+
+// Whether text mentions name as a whole word, so that "src" isn't
+// mentioned by a line about "src-and-web".
+func mentionsName(text, name string) bool {
+	for i := strings.Index(text, name); i != -1; {
+		end := i + len(name)
+		if (i == 0 || !isNameChar(text[i-1])) && (end == len(text) || !isNameChar(text[end])) {
+			return true
+		}
+		next := strings.Index(text[i+1:], name)
+		if next == -1 {
+			return false
+		}
+		i += next + 1
+	}
+	return false
+}
+
+// Whether c can be part of an object name.
+func isNameChar(c byte) bool {
+	return c == '-' || c == '.' || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9')
+}
+
+// Warns if the tilt session dropped --ignore-case from the created FileWatch,
+// e.g., because it runs an older version of tilt that doesn't support it.
+func (c *createFileWatchCmd) warnIfIgnoreCaseDropped(fw *v1alpha1.FileWatch, result *unstructured.Unstructured) {
+	if !c.ignoreCase || c.helper.dryRun == dryRunClient || len(fw.Spec.Ignores) == 0 {
+		return
+	}
+
+	ignores, _, _ := unstructured.NestedSlice(result.Object, "spec", "ignores")
+	supported := len(ignores) > 0
+	for _, ignore := range ignores {
+		m, ok := ignore.(map[string]interface{})
+		if !ok || m["ignoreCase"] != true {
+			supported = false
+		}
+	}
+	if !supported {
+		_, _ = fmt.Fprintf(c.helper.streams.ErrOut,
+			"Warning: the tilt session doesn't support --ignore-case, so FileWatch %s still matches its ignores case-sensitively. Upgrade tilt to use --ignore-case.\n",
+			fw.Name)
+	}
+}
+
+// The ConfigMap that disables a FileWatch created with --disabled.
+//
+// Named after the FileWatch, with a prefix so that it can't collide
+// with the disable ConfigMap of a resource of the same name.
+func disableSourceForFileWatch(name string) *v1alpha1.DisableSource {
+	return &v1alpha1.DisableSource{
+		ConfigMap: &v1alpha1.ConfigMapDisableSource{
+			Name: fmt.Sprintf("filewatch-%s-disable", name),
+			Key:  "isDisabled",
+		},
+	}
+}
+
+// Writes the ConfigMap that disables a FileWatch created with --disabled,
+// so that 'tilt enable' can start it later.
+//
+// The ConfigMap is written once the FileWatch is created, so that a failed
+// create doesn't leave it behind, or disable an existing FileWatch of the
+// same name. A FileWatch whose ConfigMap is missing counts as disabled,
+// so it never starts watching in between.
+func (c *createFileWatchCmd) disable(ctx context.Context, fw *v1alpha1.FileWatch) error {
+	source := fw.Spec.DisableSource
+	if c.helper.dryRun == dryRunClient {
+		return nil
+	}
+
+	cm := &v1alpha1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: source.ConfigMap.Name},
+		Data:       map[string]string{source.ConfigMap.Key: "true"},
+	}
+	u, err := toUnstructured(cm)
+	if err != nil {
+		return err
+	}
+
+	client := c.helper.dynamicClient.Resource(cm.GetGroupVersionResource())
+	_, err = client.Create(ctx, u, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		existing, getErr := client.Get(ctx, cm.Name, metav1.GetOptions{})
+		if getErr != nil {
+			return wrapNoSessionError(getErr)
+		}
+		err = unstructured.SetNestedField(existing.Object, "true", "data", source.ConfigMap.Key)
+		if err != nil {
+			return err
+		}
+		_, err = client.Update(ctx, existing, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("disabling filewatch %s: %v", fw.Name, wrapNoSessionError(err))
+	}
+	return nil
+}
+
+// With -v, prints where a FileWatch is about to be created and what it
+// will watch, for debugging watches that target the wrong place.
+//
+// Only the API server's address is printed, never its credentials.
+func (c *createFileWatchCmd) printDiagnostics(fw *v1alpha1.FileWatch) {
+	w := c.helper.streams.ErrOut
+	if c.helper.restConfig != nil {
+		_, _ = fmt.Fprintf(w, "Tilt API server: %s\n", c.helper.restConfig.Host)
+		if c.helper.restConfig.BearerToken != "" {
+			_, _ = fmt.Fprintf(w, "Bearer token: <redacted>\n")
+		}
+	} else {
+		_, _ = fmt.Fprintf(w, "Tilt API server: <none> (dry run)\n")
+	}
+
+	name := fw.Name
+	if name == "" {
+		name = fw.GenerateName + "<generated>"
+	}
+	_, _ = fmt.Fprintf(w, "FileWatch %s watches:\n", name)
+	for _, path := range fw.Spec.WatchedPaths {
+		_, _ = fmt.Fprintf(w, "  %s\n", path)
+	}
+	if len(fw.Spec.Ignores) > 0 {
+		_, _ = fmt.Fprintf(w, "Ignoring:\n")
+		for _, ignore := range fw.Spec.Ignores {
+			suffix := ""
+			if ignore.IgnoreCase {
+				suffix = " (ignoring case)"
+			}
+			_, _ = fmt.Fprintf(w, "  %s: %s%s\n", ignore.BasePath, strings.Join(ignore.Patterns, ", "), suffix)
+		}
+	}
+}
+
+// Prints the watched paths and ignores that the arguments resolve to,
+// without talking to the tilt session or sending analytics.
+func (c *createFileWatchCmd) printResolvedFileWatches(ctx context.Context, args []string) error {
+	if c.relativeTo == "tiltfile" {
+		return usageErrorf("--print-resolved can't be used with --relative-to=tiltfile, which needs the running tilt session")
+	}
+	err := c.resolveBaseDir(ctx)
+	if err != nil {
+		return usageError{err: err}
+	}
+
+	var fws []*v1alpha1.FileWatch
+	if c.filename != "" {
+		fws, err = c.fileObjects()
+	} else {
+		var fw *v1alpha1.FileWatch
+		fw, err = c.object(args)
+		fws = append(fws, fw)
+	}
+	if err != nil {
+		return usageError{err: err}
+	}
+
+	for i, fw := range fws {
+		if i > 0 {
+			_, _ = fmt.Fprintln(c.helper.streams.Out)
+		}
+		printResolved(c.helper.streams.Out, fw)
+	}
+	return nil
+}
+
+// Prints the files that the FileWatch would watch now, for --preview-matches.
+func (c *createFileWatchCmd) printMatchingFiles(ctx context.Context, args []string) error {
+	if c.relativeTo == "tiltfile" {
+		return usageErrorf("--preview-matches can't be used with --relative-to=tiltfile, which needs the running tilt session")
+	}
+	if c.previewLimit < 1 {
+		return usageErrorf("--preview-limit must be at least 1, got %d", c.previewLimit)
+	}
+	err := c.resolveBaseDir(ctx)
+	if err != nil {
+		return usageError{err: err}
+	}
+
+	fw, err := c.object(args)
+	if err != nil {
+		return usageError{err: err}
+	}
+
+	matches, truncated := matchingFiles(fw.Spec, c.previewLimit)
+	for _, match := range matches {
+		_, _ = fmt.Fprintln(c.helper.streams.Out, match)
+	}
+	if truncated {
+		_, _ = fmt.Fprintf(c.helper.streams.ErrOut,
+			"Stopped after %d files (use --preview-limit to see more)\n", c.previewLimit)
+	}
+	return nil
+}
+
+// Prints the watched paths and ignores of a FileWatch, one per line,
+// in the order they're in the spec.
+func printResolved(w io.Writer, fw *v1alpha1.FileWatch) {
+	name := fw.Name
+	if name == "" {
+		name = fw.GenerateName + "<generated>"
+	}
+	_, _ = fmt.Fprintf(w, "FileWatch %s\n", name)
+	_, _ = fmt.Fprintf(w, "watched paths:\n")
+	for _, path := range fw.Spec.WatchedPaths {
+		_, _ = fmt.Fprintf(w, "  %s\n", path)
+	}
+	if len(fw.Spec.Ignores) == 0 {
+		_, _ = fmt.Fprintf(w, "ignores: none\n")
+		return
+	}
+	_, _ = fmt.Fprintf(w, "ignores:\n")
+	for _, ignore := range fw.Spec.Ignores {
+		suffix := ""
+		if ignore.IgnoreCase {
+			suffix = " (ignoring case)"
+		}
+		_, _ = fmt.Fprintf(w, "  %s%s:\n", ignore.BasePath, suffix)
+		for _, pattern := range ignore.Patterns {
+			_, _ = fmt.Fprintf(w, "    %s\n", pattern)
+		}
+	}
+}
+
+// Triggers the resource that a created FileWatch belongs to, so that
+// its builds run without waiting for a file change.
+//
+// FileWatches that don't belong to a resource yet only get a warning.
+func (c *createFileWatchCmd) triggerOwner(fw *unstructured.Unstructured) error {
+	resource := fw.GetAnnotations()[v1alpha1.AnnotationManifest]
+	if resource == "" {
+		_, _ = fmt.Fprintf(c.helper.streams.ErrOut,
+			"Warning: not triggering FileWatch %q, because it isn't associated with a resource (no %s annotation)\n",
+			fw.GetName(), v1alpha1.AnnotationManifest)
+		return nil
+	}
+
+	err := c.triggerResource(resource)
+	if err != nil {
+		return fmt.Errorf("triggering resource %q: %v", resource, err)
+	}
+	_, _ = fmt.Fprintf(c.helper.streams.ErrOut, "Triggered update for resource: %q\n", resource)
+	return nil
+}
+
+// Creates each FileWatch in the -f file.
+//
+// Keeps going past failures, and reports them all at the end. Unless
+// --no-summary, a file with more than one FileWatch also gets a summary
+// line, like "3 created, 1 unchanged, 1 error.".
+func (c *createFileWatchCmd) createFromFile(ctx context.Context, fws []*v1alpha1.FileWatch) error {
+	var failures []string
+	results := &createResults{}
+	for _, fw := range fws {
+		err := c.createAndPrint(ctx, fw)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("  %s: %v", fw.Name, err))
+			results.addError()
+			continue
+		}
+		results.add(c.helper.printFlags.NamePrintFlags.Operation)
+	}
+
+	if len(fws) > 1 && !c.noSummary {
+		_, _ = fmt.Fprintln(c.summaryOut(), results.summary())
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to create %d of %d FileWatches:\n%s",
+			len(failures), len(fws), strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+// Where the -f summary goes: stdout next to the usual "created" lines,
+// but stderr if stdout is only names or objects, so that it stays parseable.
+func (c *createFileWatchCmd) summaryOut() io.Writer {
+	h := c.helper
+	hasFormat := h.printFlags.OutputFormat != nil && *h.printFlags.OutputFormat != ""
+	if h.quiet || h.nameOnlyOnSuccess || c.outputStatus || hasFormat {
+		return h.streams.ErrOut
+	}
+	return h.streams.Out
+}
+
+// Counts the outcomes of creating several FileWatches.
+type createResults struct {
+	// The operations, e.g., "created" or "unchanged", in the order they first happened.
+	operations []string
+	counts     map[string]int
+	errors     int
+}
+
+func (r *createResults) add(operation string) {
+	operation = strings.TrimSuffix(operation, " (dry run)")
+	if r.counts == nil {
+		r.counts = map[string]int{}
+	}
+	if r.counts[operation] == 0 {
+		r.operations = append(r.operations, operation)
+	}
+	r.counts[operation]++
+}
+
+func (r *createResults) addError() {
+	r.errors++
+}
+
+// Returns the summary line, e.g., "3 created, 1 unchanged, 1 error.".
+func (r *createResults) summary() string {
+	parts := []string{}
+	for _, operation := range r.operations {
+		parts = append(parts, fmt.Sprintf("%d %s", r.counts[operation], operation))
+	}
+	if r.errors == 1 {
+		parts = append(parts, "1 error")
+	} else if r.errors > 1 {
+		parts = append(parts, fmt.Sprintf("%d errors", r.errors))
+	}
+	return strings.Join(parts, ", ") + "."
+}
+
+// Reads the FileWatches from the -f file.
+//
+// Paths are interpreted like the PATHS arguments, and --label and --annotation
+// are added to each FileWatch.
+func (c *createFileWatchCmd) fileObjects() ([]*v1alpha1.FileWatch, error) {
+	var v visitor.Interface = visitor.File(c.filename)
+	if c.filename == "-" {
+		v = visitor.Stdin(c.helper.streams.In)
+	}
+	objs, err := visitor.Decode(v1alpha1.NewScheme(), v)
+	if err != nil {
+		return nil, err
+	}
+	if len(objs) == 0 {
+		return nil, fmt.Errorf("no FileWatches found in %s", v.Name())
+	}
+
+	labels, annotations, err := c.metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := c.dir()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*v1alpha1.FileWatch{}
+	for _, obj := range objs {
+		fw, ok := obj.(*v1alpha1.FileWatch)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected only FileWatch objects, got %s",
+				v.Name(), obj.GetObjectKind().GroupVersionKind().Kind)
+		}
+		if fw.Name == "" {
+			return nil, fmt.Errorf("%s: every FileWatch must have a name", v.Name())
+		}
+
+		fw.Spec.WatchedPaths, err = c.paths(fw.Spec.WatchedPaths)
+		if err != nil {
+			return nil, fmt.Errorf("FileWatch %s: %v", fw.Name, err)
+		}
+		resolveIgnoreBasePaths(dir, fw.Spec.Ignores)
+		err = validateIgnores(fw.Spec.Ignores)
+		if err != nil {
+			return nil, fmt.Errorf("FileWatch %s: %v", fw.Name, err)
+		}
+		c.warnUnrelatedIgnores(fw.Spec.WatchedPaths, fw.Spec.Ignores)
+
+		fw.Labels = mergeStringMaps(fw.Labels, labels)
+		fw.Annotations = mergeStringMaps(fw.Annotations, annotations)
+		c.addOwnerRef(fw)
+		result = append(result, fw)
+	}
+	return result, nil
+}
+
+// Creates the FileWatch, or with --update, replaces the spec of the existing FileWatch.
+// With --ensure, an existing FileWatch that already matches is returned as is.
+// With --replace, the existing FileWatch is deleted and created again.
+//
+// With apply, an existing FileWatch is server-side applied instead. If the
+// tilt session can't apply it, falls back to replacing its spec, like --update.
+//
+// Any --label and --annotation values are added to those of the existing FileWatch.
+// Its status is left untouched.
+func (c *createFileWatchCmd) createOrUpdate(ctx context.Context, fw *v1alpha1.FileWatch) (*unstructured.Unstructured, error) {
+	if fw.Name == "" && fw.GenerateName != "" && c.helper.dryRun != dryRunClient {
+		return c.createWithGeneratedName(ctx, fw)
+	}
+
+	// The tilt apiserver can apply to an existing object, but can't create
+	// one from an apply patch, so new FileWatches are created instead.
+	if c.apply && c.helper.dryRun != dryRunClient {
+		_, err := c.helper.dynamicClient.Resource(fw.GetGroupVersionResource()).Get(ctx, fw.Name, metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, wrapNoSessionError(err)
+		}
+		if err == nil {
+			result, err := c.serverSideApply(ctx, fw)
+			if !isServerSideApplyUnsupported(err) {
+				return result, wrapNoSessionError(err)
+			}
+			logger.Get(ctx).Debugf("The tilt session can't server-side apply FileWatch %s (%v), so updating it instead", fw.Name, err)
+		}
+	}
+
+	result, err := c.helper.createObject(ctx, fw)
+	if err == nil && c.apply && c.helper.dryRun != dryRunClient {
+		return c.takeOverAppliedFields(ctx, result)
+	}
+	if err == nil || !(c.update || c.ensure || c.apply || c.replace) || !apierrors.IsAlreadyExists(err) {
+		return result, err
+	}
+	if c.replace {
+		return c.replaceExisting(ctx, fw)
+	}
+
+	client := c.helper.dynamicClient.Resource(fw.GetGroupVersionResource())
+	existing, err := client.Get(ctx, fw.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, wrapNoSessionError(err)
+	}
+
+	if c.ensure {
+		matches, err := fileWatchMatches(existing, fw)
+		if err != nil {
+			return nil, err
+		}
+		if matches {
+			err = c.helper.setOperation("unchanged")
+			if err != nil {
+				return nil, err
+			}
+			return existing, nil
+		}
+	}
+
+	spec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&fw.Spec)
+	if err != nil {
+		return nil, err
+	}
+	existing.Object["spec"] = spec
+	existing.SetLabels(mergeStringMaps(existing.GetLabels(), fw.Labels))
+	existing.SetAnnotations(mergeStringMaps(existing.GetAnnotations(), fw.Annotations))
+	existing.SetOwnerReferences(mergeOwnerRefs(existing.GetOwnerReferences(), fw.OwnerReferences))
+
+	result, err = client.Update(ctx, existing, metav1.UpdateOptions{FieldManager: c.helper.fieldManager})
+	if err != nil {
+		return nil, wrapNoSessionError(err)
+	}
+	// Apply reports the same verb whether or not the FileWatch existed.
+	if c.apply {
+		return result, nil
+	}
+
+	err = c.helper.setOperation("updated")
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Prints a unified diff of the spec of the existing FileWatch against fw's,
+// for --update --dry-run=client. The specs are diffed as YAML, so that each
+// line is one field or list item.
+func (c *createFileWatchCmd) printSpecDiff(ctx context.Context, fw *v1alpha1.FileWatch) error {
+	var current []string
+	client := c.helper.dynamicClient.Resource(fw.GetGroupVersionResource())
+	existing, err := client.Get(ctx, fw.Name, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return wrapNoSessionError(err)
+	}
+	if err == nil {
+		var existingFW v1alpha1.FileWatch
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(existing.Object, &existingFW)
+		if err != nil {
+			return err
+		}
+		current, err = specLines(existingFW.Spec)
+		if err != nil {
+			return err
+		}
+	}
+
+	desired, err := specLines(fw.Spec)
+	if err != nil {
+		return err
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        current,
+		B:        desired,
+		FromFile: fmt.Sprintf("filewatch.tilt.dev/%s (existing)", fw.Name),
+		ToFile:   fmt.Sprintf("filewatch.tilt.dev/%s (updated)", fw.Name),
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(c.helper.streams.Out, diff)
+	return err
+}
+
+// The lines of a spec as YAML, for diffing. Fields left at their zero
+// value are left out, so that they don't show up as added to a new FileWatch.
+func specLines(spec v1alpha1.FileWatchSpec) ([]string, error) {
+	fields, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&spec)
+	if err != nil {
+		return nil, err
+	}
+	zero, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&v1alpha1.FileWatchSpec{})
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range fields {
+		if apiequality.Semantic.DeepEqual(value, zero[key]) {
+			delete(fields, key)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	b, err := yaml.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.SplitAfter(string(b), "\n")
+	// The YAML ends with a newline, so the last line is empty.
+	return lines[:len(lines)-1], nil
+}
+
+// Deletes the existing FileWatch with fw's name, and creates fw in its place.
+//
+// Unlike an update, this makes a new object, with a new UID and an empty status.
+func (c *createFileWatchCmd) replaceExisting(ctx context.Context, fw *v1alpha1.FileWatch) (*unstructured.Unstructured, error) {
+	_, _ = fmt.Fprintf(c.helper.streams.ErrOut,
+		"Warning: replacing FileWatch %s, so its status and file event history are lost (use --update to keep them)\n", fw.Name)
+
+	client := c.helper.dynamicClient.Resource(fw.GetGroupVersionResource())
+	err := client.Delete(ctx, fw.Name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, wrapNoSessionError(err)
+	}
+
+	result, err := c.helper.createObject(ctx, fw)
+	if err != nil {
+		return nil, err
+	}
+	err = c.helper.setOperation("replaced")
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Whether updating existing to fw would leave it as is, i.e., it has the same
+// spec, and already has fw's labels, annotations, and owner references.
+func fileWatchMatches(existing *unstructured.Unstructured, fw *v1alpha1.FileWatch) (bool, error) {
+	current := &v1alpha1.FileWatch{}
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(existing.Object, current)
+	if err != nil {
+		return false, err
+	}
+	return apiequality.Semantic.DeepEqual(current.Spec, fw.Spec) &&
+		apiequality.Semantic.DeepEqual(mergeStringMaps(current.Labels, fw.Labels), current.Labels) &&
+		apiequality.Semantic.DeepEqual(mergeStringMaps(current.Annotations, fw.Annotations), current.Annotations) &&
+		len(mergeOwnerRefs(current.OwnerReferences, fw.OwnerReferences)) == len(current.OwnerReferences), nil
+}
+
+const editHeader = `# Edit the FileWatch to create. Lines starting with '#' are ignored.
+# To create nothing, close the editor without changing the file, or empty it.
+`
+
+// Opens the FileWatch in an editor, and returns the FileWatch as edited.
+//
+// Returns nil if the user didn't change the FileWatch, or emptied it.
+func (c *createFileWatchCmd) editFileWatch(fw *v1alpha1.FileWatch) (*v1alpha1.FileWatch, error) {
+	original, err := editableYAML(fw)
+	if err != nil {
+		return nil, err
+	}
+
+	edited, err := c.editObject(append([]byte(editHeader), original...))
+	if err != nil {
+		return nil, err
+	}
+	edited = stripCommentLines(edited)
+
+	if len(bytes.TrimSpace(edited)) == 0 {
+		_, _ = fmt.Fprintln(c.helper.streams.ErrOut, "Edit cancelled: the file is empty, so no FileWatch was created.")
+		return nil, nil
+	}
+	if bytes.Equal(bytes.TrimSpace(edited), bytes.TrimSpace(original)) {
+		_, _ = fmt.Fprintln(c.helper.streams.ErrOut, "Edit cancelled: the file wasn't changed, so no FileWatch was created.")
+		return nil, nil
+	}
+
+	result := &v1alpha1.FileWatch{}
+	err = yaml.UnmarshalStrict(edited, result)
+	if err != nil {
+		return nil, fmt.Errorf("reading edited FileWatch: %v", err)
+	}
+	if result.Kind != "" && result.Kind != "FileWatch" {
+		return nil, fmt.Errorf("reading edited FileWatch: kind must be FileWatch, got %q", result.Kind)
+	}
+	if result.Name == "" && result.GenerateName == "" {
+		return nil, fmt.Errorf("reading edited FileWatch: metadata.name cannot be empty")
+	}
+	if len(result.Spec.WatchedPaths) == 0 {
+		return nil, fmt.Errorf("reading edited FileWatch: spec.watchedPaths cannot be empty")
+	}
+	err = validateIgnores(result.Spec.Ignores)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Serializes the parts of the FileWatch that make sense to edit before creating it.
+func editableYAML(fw *v1alpha1.FileWatch) ([]byte, error) {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(fw)
+	if err != nil {
+		return nil, err
+	}
+	obj["apiVersion"] = v1alpha1.SchemeGroupVersion.String()
+	obj["kind"] = "FileWatch"
+	delete(obj, "status")
+	unstructured.RemoveNestedField(obj, "metadata", "creationTimestamp")
+	if spec, ok := obj["spec"].(map[string]interface{}); ok {
+		for key, value := range spec {
+			if value == nil {
+				delete(spec, key)
+			}
+		}
+	}
+	return yaml.Marshal(obj)
+}
+
+// Removes the lines that are only a comment.
+func stripCommentLines(data []byte) []byte {
+	var result []byte
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if bytes.HasPrefix(bytes.TrimSpace(line), []byte("#")) {
+			continue
+		}
+		result = append(result, line...)
+	}
+	return result
+}
+
+// Opens the YAML in the user's editor, and returns it as edited.
+func launchEditor(original []byte) ([]byte, error) {
+	e := editor.NewDefaultEditor([]string{"TILT_EDITOR", "VISUAL", "EDITOR"})
+	edited, path, err := e.LaunchTempFile("tilt-create-filewatch-", ".yaml", bytes.NewReader(original))
+	if path != "" {
+		_ = os.Remove(path)
+	}
+	return edited, err
+}
+
+// How many names to try for --generate-name before giving up.
+const maxGenerateNameAttempts = 5
+
+// Creates the FileWatch with a unique name starting with its GenerateName.
+//
+// The tilt session's API server doesn't generate names on its own, so we
+// generate them like a Kubernetes API server would, and try another name
+// if one is taken.
+func (c *createFileWatchCmd) createWithGeneratedName(ctx context.Context, fw *v1alpha1.FileWatch) (*unstructured.Unstructured, error) {
+	var err error
+	for i := 0; i < maxGenerateNameAttempts; i++ {
+		fw.Name = names.SimpleNameGenerator.GenerateName(fw.GenerateName)
+		var result *unstructured.Unstructured
+		result, err = c.helper.createObject(ctx, fw)
+		if !apierrors.IsAlreadyExists(err) {
+			return result, err
+		}
+	}
+	return nil, err
+}
+
+// Determines the directory to resolve relative paths against from --relative-to.
+func (c *createFileWatchCmd) resolveBaseDir(ctx context.Context) error {
+	switch c.relativeTo {
+	case "", "cwd":
+		c.baseDir = ""
+		return nil
+	case "tiltfile":
+	default:
+		return fmt.Errorf("--relative-to must be one of (cwd, tiltfile), got %q", c.relativeTo)
+	}
+
+	if c.helper.dryRun == dryRunClient {
+		return fmt.Errorf("--relative-to=tiltfile needs the running tilt session, so it can't be used with --dry-run=client")
+	}
+
+	tf := &v1alpha1.Tiltfile{}
+	obj, err := c.helper.dynamicClient.Resource(tf.GetGroupVersionResource()).
+		Get(ctx, model.MainTiltfileManifestName.String(), metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err == nil {
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, tf)
+		if err != nil {
+			return err
+		}
+	}
+	if tf.Spec.Path == "" {
+		return fmt.Errorf("--relative-to=tiltfile: no Tiltfile found in the running tilt session.\n" +
+			"Make sure 'tilt up' has loaded a Tiltfile, or use --relative-to=cwd")
+	}
+
+	c.baseDir = filepath.Dir(tf.Spec.Path)
+	return nil
+}
+
+// Checks that the tilt session serves FileWatches at the API version this CLI
+// creates them with, since the session may silently drop fields it doesn't know.
+//
+// Warns about a mismatch, or with --strict-version, fails.
+func (c *createFileWatchCmd) checkAPIVersion(ctx context.Context) error {
+	if c.skipVersionCheck || c.helper.dryRun == dryRunClient || c.helper.discoveryClient == nil {
+		return nil
+	}
+
+	gvk := v1alpha1.SchemeGroupVersion.WithKind("FileWatch")
+	served, err := servedVersions(c.helper.discoveryClient, gvk.GroupKind())
+	if err != nil {
+		if c.strictVersion {
+			return fmt.Errorf("checking the tilt session's FileWatch API version: %v", wrapNoSessionError(err))
+		}
+		logger.Get(ctx).Debugf("Couldn't check the tilt session's FileWatch API version: %v", err)
+		return nil
+	}
+	if len(served) > 0 && served[0] == gvk.Version {
+		return nil
+	}
+
+	mismatch := fmt.Sprintf("the tilt session doesn't serve FileWatches, but this tilt CLI creates them as %s", gvk.GroupVersion())
+	if len(served) > 0 {
+		mismatch = fmt.Sprintf("the tilt session serves FileWatches as %s/%s, but this tilt CLI creates them as %s, so the session may ignore some of their fields",
+			gvk.Group, served[0], gvk.GroupVersion())
+	}
+	if c.strictVersion {
+		return fmt.Errorf("%s (see --strict-version)", mismatch)
+	}
+	_, _ = fmt.Fprintf(c.helper.streams.ErrOut, "Warning: %s. Upgrade tilt so that they match, or pass --skip-version-check.\n", mismatch)
+	return nil
+}
+
+// Returns the versions that the server serves a kind at, starting with the
+// preferred version of its group.
+func servedVersions(client discovery.DiscoveryInterface, gk schema.GroupKind) ([]string, error) {
+	groups, err := client.ServerGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, group := range groups.Groups {
+		if group.Name != gk.Group {
+			continue
+		}
+
+		candidates := []metav1.GroupVersionForDiscovery{group.PreferredVersion}
+		for _, version := range group.Versions {
+			if version != group.PreferredVersion {
+				candidates = append(candidates, version)
+			}
+		}
+		for _, version := range candidates {
+			resources, err := client.ServerResourcesForGroupVersion(version.GroupVersion)
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range resources.APIResources {
+				// Skip subresources, like filewatches/status.
+				if r.Kind == gk.Kind && !strings.Contains(r.Name, "/") {
+					result = append(result, version.Version)
+					break
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// Looks up the object named by --owner, so that the FileWatch can refer to it.
+//
+// Fails if the object doesn't exist, so that we never create a FileWatch
+// that nothing will clean up.
+func (c *createFileWatchCmd) resolveOwner(ctx context.Context) error {
+	c.ownerRef = nil
+	if c.owner == "" {
+		return nil
+	}
+
+	kind, name, ok := strings.Cut(c.owner, "/")
+	if !ok || kind == "" || name == "" {
+		return fmt.Errorf("invalid --owner %q: must be KIND/NAME", c.owner)
+	}
+	obj := resourceObjectForKind(kind)
+	if obj == nil {
+		return fmt.Errorf("invalid --owner %q: unknown kind %q", c.owner, kind)
+	}
+	if c.helper.dryRun == dryRunClient {
+		return fmt.Errorf("--owner needs the running tilt session, so it can't be used with --dry-run=client")
+	}
+
+	gvks, _, err := v1alpha1.NewScheme().ObjectKinds(obj)
+	if err != nil {
+		return err
+	}
+
+	owner, err := c.helper.dynamicClient.Resource(obj.GetGroupVersionResource()).
+		Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("--owner %s: not found in the tilt session", c.owner)
+		}
+		return wrapNoSessionError(err)
+	}
+
+	c.ownerRef = &metav1.OwnerReference{
+		APIVersion: gvks[0].GroupVersion().String(),
+		Kind:       gvks[0].Kind,
+		Name:       owner.GetName(),
+		UID:        owner.GetUID(),
+	}
+	return nil
+}
+
+// Looks up the resource named by --trigger-resource, and the target of it
+// that file changes rebuild.
+//
+// That's the target that deploys the resource, rather than one of its images,
+// since a change to it rebuilds the whole resource.
+func (c *createFileWatchCmd) resolveLinkedResource(ctx context.Context) error {
+	c.linkedTarget = ""
+	if c.linkedResource == "" {
+		return nil
+	}
+	if c.helper.dryRun == dryRunClient {
+		return fmt.Errorf("--trigger-resource needs the running tilt session, so it can't be used with --dry-run=client")
+	}
+
+	obj, err := c.helper.dynamicClient.Resource((&v1alpha1.UIResource{}).GetGroupVersionResource()).
+		Get(ctx, c.linkedResource, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("--trigger-resource %s: no such resource in the tilt session", c.linkedResource)
+		}
+		return wrapNoSessionError(err)
+	}
+	var resource v1alpha1.UIResource
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &resource)
+	if err != nil {
+		return err
+	}
+
+	for _, spec := range resource.Status.Specs {
+		if spec.Type != v1alpha1.UIResourceTargetTypeImage && spec.ID != "" {
+			c.linkedTarget = spec.ID
+			return nil
+		}
+	}
+	return fmt.Errorf("--trigger-resource %s: the resource doesn't build or run anything, so file changes can't trigger it", c.linkedResource)
+}
+
+// With --infer-paths-from, looks up the paths that the resource watches,
+// i.e., the watched paths of the FileWatches annotated with its name.
+func (c *createFileWatchCmd) resolveInferredPaths(ctx context.Context) error {
+	c.inferredPaths = nil
+	if c.inferPathsFrom == "" {
+		return nil
+	}
+	if c.helper.dryRun == dryRunClient {
+		return fmt.Errorf("--infer-paths-from needs the running tilt session, so it can't be used with --dry-run=client")
+	}
+
+	_, err := c.helper.dynamicClient.Resource((&v1alpha1.UIResource{}).GetGroupVersionResource()).
+		Get(ctx, c.inferPathsFrom, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("--infer-paths-from %s: no such resource in the tilt session", c.inferPathsFrom)
+		}
+		return wrapNoSessionError(err)
+	}
+
+	list, err := c.helper.dynamicClient.Resource((&v1alpha1.FileWatch{}).GetGroupVersionResource()).
+		List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return wrapNoSessionError(err)
+	}
+	// Sorted by name, so the paths come in the same order on every run.
+	sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].GetName() < list.Items[j].GetName() })
+
+	seen := make(map[string]bool)
+	for _, item := range list.Items {
+		if item.GetAnnotations()[v1alpha1.AnnotationManifest] != c.inferPathsFrom {
+			continue
+		}
+		var fw v1alpha1.FileWatch
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &fw)
+		if err != nil {
+			return err
+		}
+		for _, path := range fw.Spec.WatchedPaths {
+			if !seen[path] {
+				seen[path] = true
+				c.inferredPaths = append(c.inferredPaths, path)
+			}
+		}
+	}
+	if len(c.inferredPaths) == 0 {
+		return fmt.Errorf("--infer-paths-from %s: the resource doesn't watch any paths, so there are none to infer", c.inferPathsFrom)
+	}
+	return nil
+}
+
+// With --merge-ignores-from, reads the ignores of the other FileWatch.
+//
+// Their base paths are resolved like those of --from-spec, and ignores
+// that share a base path are merged, so that each base path has one ignore
+// to merge this FileWatch's patterns into.
+func (c *createFileWatchCmd) resolveMergedIgnores(ctx context.Context) error {
+	c.mergedIgnores = nil
+	if c.mergeIgnoresFrom == "" {
+		return nil
+	}
+	if c.helper.dryRun == dryRunClient {
+		return fmt.Errorf("--merge-ignores-from needs the running tilt session, so it can't be used with --dry-run=client")
+	}
+
+	obj, err := c.helper.dynamicClient.Resource((&v1alpha1.FileWatch{}).GetGroupVersionResource()).
+		Get(ctx, c.mergeIgnoresFrom, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("--merge-ignores-from %s: no FileWatch named %s in the tilt session", c.mergeIgnoresFrom, c.mergeIgnoresFrom)
+		}
+		return wrapNoSessionError(err)
+	}
+	var fw v1alpha1.FileWatch
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &fw)
+	if err != nil {
+		return err
+	}
+
+	dir, err := c.dir()
+	if err != nil {
+		return err
+	}
+	resolveIgnoreBasePaths(dir, fw.Spec.Ignores)
+	c.mergedIgnores = mergeIgnoreDefs(nil, fw.Spec.Ignores)
+	return nil
+}
+
+// With --inherit-ignores, reads the global ignores of the running tilt session.
+//
+// The session doesn't serve them on their own, but it adds them, and only
+// them, to the FileWatch of each Tiltfile's config files. If the session
+// can't be read, warns and continues with just the other ignores.
+func (c *createFileWatchCmd) resolveInheritedIgnores(ctx context.Context) error {
+	if !c.inheritIgnores {
+		return nil
+	}
+	if c.helper.dryRun == dryRunClient {
+		_, _ = fmt.Fprintf(c.helper.streams.ErrOut,
+			"Warning: --inherit-ignores needs the running tilt session, so no ignores are inherited with --dry-run=client\n")
+		return nil
+	}
+
+	list, err := c.helper.dynamicClient.Resource((&v1alpha1.FileWatch{}).GetGroupVersionResource()).
+		List(ctx, metav1.ListOptions{})
+	if err != nil {
+		_, _ = fmt.Fprintf(c.helper.streams.ErrOut,
+			"Warning: couldn't read the ignores of the tilt session, so none are inherited: %v\n", wrapNoSessionError(err))
+		return nil
+	}
+
+	var ignores []v1alpha1.IgnoreDef
+	for _, item := range list.Items {
+		targetID := item.GetAnnotations()[v1alpha1.AnnotationTargetID]
+		if !strings.HasPrefix(targetID, string(model.TargetTypeConfigs)+":") {
+			continue
+		}
+		var fw v1alpha1.FileWatch
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &fw)
+		if err != nil {
+			return err
+		}
+		ignores = mergeIgnoreDefs(ignores, fw.Spec.Ignores)
+	}
+	c.inheritedIgnores = ignores
+	return nil
+}
+
+// Adds the --owner reference to a FileWatch, if any.
+func (c *createFileWatchCmd) addOwnerRef(fw *v1alpha1.FileWatch) {
+	if c.ownerRef != nil {
+		fw.OwnerReferences = append(fw.OwnerReferences, *c.ownerRef)
+	}
+}
+
+// Adds the owner references in refs that aren't in existing yet.
+func mergeOwnerRefs(existing, refs []metav1.OwnerReference) []metav1.OwnerReference {
+	result := existing
+	for _, ref := range refs {
+		found := false
+		for _, e := range existing {
+			if e.UID == ref.UID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, ref)
+		}
+	}
+	return result
+}
+
+// Finds the tilt API type for a kind on the command line.
+//
+// Accepts the kind in any case, or its plural resource name, e.g.,
+// Cmd, cmd, or cmds.
+func resourceObjectForKind(kind string) resource.Object {
+	scheme := v1alpha1.NewScheme()
+	for _, obj := range v1alpha1.AllResourceObjects() {
+		if strings.EqualFold(kind, obj.GetGroupVersionResource().Resource) {
+			return obj
+		}
+		gvks, _, err := scheme.ObjectKinds(obj)
+		if err == nil && strings.EqualFold(kind, gvks[0].Kind) {
+			return obj
+		}
+	}
+	return nil
+}
+
+// The directory to resolve relative paths against.
+func (c *createFileWatchCmd) dir() (string, error) {
+	if c.baseDir != "" {
+		return c.baseDir, nil
+	}
+	return os.Getwd()
+}
+
+// Polls the FileWatch until its filesystem monitor has started.
+//
+// Returns an error if the FileWatch reports an error, or if it
+// doesn't start before --wait-timeout.
+func (c *createFileWatchCmd) waitForMonitor(ctx context.Context, fw *v1alpha1.FileWatch) (*unstructured.Unstructured, error) {
+	var result *unstructured.Unstructured
+	client := c.helper.dynamicClient.Resource(fw.GetGroupVersionResource())
+	err := wait.PollUntilContextTimeout(ctx, 100*time.Millisecond, c.waitTimeout, true,
+		// Each Get uses the outer context, so that a request in flight
+		// at the timeout doesn't fail in place of the timeout.
+		func(context.Context) (bool, error) {
+			obj, err := client.Get(ctx, fw.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+
+			var current v1alpha1.FileWatch
+			err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &current)
+			if err != nil {
+				return false, err
+			}
+			if current.Status.Error != "" {
+				return false, fmt.Errorf("filewatch %s: %s", fw.Name, current.Status.Error)
+			}
+
+			result = obj
+			return !current.Status.MonitorStartTime.IsZero(), nil
+		})
+	if err != nil {
+		if ctx.Err() == nil && wait.Interrupted(err) {
+			return nil, waitTimeoutError{timeout: c.waitTimeout, name: fw.Name}
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// Returned when the FileWatch doesn't start watching within --wait-timeout.
+type waitTimeoutError struct {
+	timeout time.Duration
+	name    string
+}
+
+func (e waitTimeoutError) Error() string {
+	return fmt.Sprintf("timed out after %s waiting for filewatch %s to start watching", e.timeout, e.name)
+}
+
+// Polls until the FileWatch starts watching or reports an error,
+// then prints its status as YAML.
+//
+// If that takes longer than --wait-timeout, prints whatever status
+// the FileWatch has, with a warning.
+func (c *createFileWatchCmd) printStatus(ctx context.Context, fw *v1alpha1.FileWatch) error {
+	var status v1alpha1.FileWatchStatus
+	client := c.helper.dynamicClient.Resource(fw.GetGroupVersionResource())
+	err := wait.PollUntilContextTimeout(ctx, 100*time.Millisecond, c.waitTimeout, true,
+		// Each Get uses the outer context, so that a request in flight
+		// at the timeout doesn't fail in place of the timeout.
+		func(context.Context) (bool, error) {
+			obj, err := client.Get(ctx, fw.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+
+			var current v1alpha1.FileWatch
+			err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &current)
+			if err != nil {
+				return false, err
+			}
+
+			status = current.Status
+			return !status.MonitorStartTime.IsZero() || status.Error != "", nil
+		})
+	if err != nil {
+		if ctx.Err() != nil || !wait.Interrupted(err) {
+			return err
+		}
+		_, _ = fmt.Fprintf(c.helper.streams.ErrOut,
+			"Warning: timed out after %s waiting for filewatch %s to report its status. Printing its status so far.\n",
+			c.waitTimeout, fw.Name)
+	}
+
+	data, err := yaml.Marshal(status)
+	if err != nil {
+		return err
+	}
+	_, err = c.helper.streams.Out.Write(data)
+	return err
+}
+
+// Interprets the flags specified on the commandline to the FileWatch to create.
+func (c *createFileWatchCmd) object(args []string) (*v1alpha1.FileWatch, error) {
+	name := ""
+	pathArgs := args
+	if c.generateName == "" {
+		name = args[0]
+		pathArgs = args[1:]
+	}
+
+	if c.debounce < 0 {
+		return nil, fmt.Errorf("--debounce must not be negative, got %s", c.debounce)
+	}
+	if c.cmd.Flags().Changed("max-events") && c.maxEvents < 1 {
+		return nil, fmt.Errorf("--max-events must be at least 1, got %d", c.maxEvents)
+	}
+	if c.cmd.Flags().Changed("heartbeat") && c.heartbeat <= 0 {
+		return nil, fmt.Errorf("--heartbeat must be positive, got %s", c.heartbeat)
+	}
+	var rateLimitEvents int32
+	var rateLimitWindow time.Duration
+	if c.cmd.Flags().Changed("rate-limit") {
+		var err error
+		rateLimitEvents, rateLimitWindow, err = parseRateLimit(c.rateLimit)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var since time.Time
+	if c.since != "" {
+		var err error
+		since, err = parseSince(c.since, time.Now())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.fromSpec == "-" && c.pathsFrom == "-" {
+		return nil, fmt.Errorf("--from-spec and --paths-from can't both read from stdin")
+	}
+
+	spec, err := c.baseSpec()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.urls) > 0 {
+		err = c.applyURLs(&spec, pathArgs)
+	} else {
+		err = c.applyPaths(&spec, pathArgs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c.fromSpec == "" || c.cmd.Flags().Changed("debounce") {
+		spec.DebounceDuration = metav1.Duration{Duration: c.debounce}
+	}
+	if c.cmd.Flags().Changed("max-events") {
+		spec.MaxEvents = c.maxEvents
+	}
+	if c.cmd.Flags().Changed("heartbeat") {
+		spec.HeartbeatInterval = metav1.Duration{Duration: c.heartbeat}
+	}
+	if c.cmd.Flags().Changed("rate-limit") {
+		spec.RateLimitEvents = rateLimitEvents
+		spec.RateLimitWindow = metav1.Duration{Duration: rateLimitWindow}
+	}
+	if !since.IsZero() {
+		spec.Since = metav1.NewMicroTime(since)
+	}
+	if c.cmd.Flags().Changed("recursive") {
+		spec.NonRecursive = !c.recursive
+	}
+	if spec.NonRecursive {
+		c.warnNonRecursiveFiles(spec.WatchedPaths)
+	}
+	if c.cmd.Flags().Changed("follow-symlinks") {
+		spec.FollowSymlinks = c.followLinks
+	}
+
+	err = c.applyPoll(&spec)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, annotations, err := c.metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	fw, err := NewFileWatchBuilder(name).
+		WithGenerateName(c.generateName).
+		WithLabels(labels).
+		WithAnnotations(annotations).
+		WithSpec(spec).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+	c.addOwnerRef(fw)
+	if c.jsonPatch != "" {
+		return applyJSONPatch(fw, c.jsonPatch)
+	}
+	return fw, nil
+}
+
+func decodeJSONPatch(value string) (jsonpatch.Patch, error) {
+	patch, err := jsonpatch.DecodePatch([]byte(value))
+	if err != nil {
+		return nil, fmt.Errorf("--json-patch must be a JSON array of patch operations: %v", err)
+	}
+	return patch, nil
+}
+
+// Applies a --json-patch to the FileWatch as unstructured JSON.
+//
+// Fields that a FileWatch doesn't have are an error, so that a typo
+// in a path isn't silently dropped.
+func applyJSONPatch(fw *v1alpha1.FileWatch, value string) (*v1alpha1.FileWatch, error) {
+	patch, err := decodeJSONPatch(value)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := json.Marshal(fw)
+	if err != nil {
+		return nil, err
+	}
+	doc, err = patch.Apply(doc)
+	if err != nil {
+		return nil, fmt.Errorf("--json-patch: %v", err)
+	}
+
+	obj := map[string]interface{}{}
+	err = json.Unmarshal(doc, &obj)
+	if err != nil {
+		return nil, fmt.Errorf("--json-patch: %v", err)
+	}
+	result := &v1alpha1.FileWatch{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructuredWithValidation(obj, result, true)
+	if err != nil {
+		return nil, fmt.Errorf("--json-patch: %v", err)
+	}
+	return result, nil
+}
+
+// Sets the watched paths and ignores of the spec from PATHS and the flags.
+func (c *createFileWatchCmd) applyPaths(spec *v1alpha1.FileWatchSpec, pathArgs []string) error {
+	pathsFrom, err := c.readPathsFrom()
+	if err != nil {
+		return err
+	}
+
+	syncPaths, err := c.resolveSyncs()
+	if err != nil {
+		return err
+	}
+
+	paths, err := c.paths(append(append(append(append(append([]string{}, spec.WatchedPaths...), pathArgs...), pathsFrom...), c.inferredPaths...), syncPaths...))
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no paths to watch: specify PATHS, --paths-from, or watchedPaths in --from-spec")
+	}
+	spec.WatchedPaths = paths
+
+	ignores, err := c.ignores(paths)
+	if err != nil {
+		return err
+	}
+	if ignores != nil {
+		spec.Ignores = ignores
+	}
+	if len(c.mergedIgnores) > 0 {
+		spec.Ignores = mergeIgnoreDefs(relatedIgnores(paths, c.mergedIgnores), spec.Ignores)
+	}
+	if len(c.inheritedIgnores) > 0 {
+		spec.Ignores = mergeIgnoreDefs(relatedIgnores(paths, c.inheritedIgnores), spec.Ignores)
+	}
+	if c.ignoreCase {
+		for i := range spec.Ignores {
+			spec.Ignores[i].IgnoreCase = true
+		}
+	}
+	err = validateIgnores(spec.Ignores)
+	if err != nil {
+		return err
+	}
+	if c.largeDirThreshold < 0 {
+		return fmt.Errorf("--large-dir-threshold must not be negative, got %d", c.largeDirThreshold)
+	}
+	c.warnLargeDirs(spec.WatchedPaths, spec.Ignores)
+	c.warnUnrelatedIgnores(spec.WatchedPaths, spec.Ignores)
+	return nil
+}
+
+// Checks --sync and the flags that go with it, before talking to the tilt session.
+func (c *createFileWatchCmd) validateSyncFlags() error {
+	if len(c.syncs) == 0 {
+		if c.syncService != "" {
+			return fmt.Errorf("--sync-service can only be used with --sync")
+		}
+		if c.syncContainer != "" {
+			return fmt.Errorf("--sync-container can only be used with --sync")
+		}
+		return nil
+	}
+
+	for _, value := range c.syncs {
+		_, _, err := parseSync(value)
+		if err != nil {
+			return err
+		}
+	}
+	_, _, err := c.syncSelector()
+	return err
+}
+
+// Parses a --sync value, LOCAL:REMOTE.
+//
+// Splits at the last colon, so that LOCAL may be a Windows path like C:\src.
+func parseSync(value string) (local string, remote string, err error) {
+	i := strings.LastIndex(value, ":")
+	if i <= 0 || i == len(value)-1 {
+		return "", "", fmt.Errorf("--sync must be LOCAL:REMOTE, e.g., ./src:/app/src, got %q", value)
+	}
+	local, remote = value[:i], value[i+1:]
+	if hasGlobMeta(local) {
+		return "", "", fmt.Errorf("--sync %q: the local path can't be a glob", value)
+	}
+	// Container paths are always slash-separated, whatever the local OS.
+	if !path.IsAbs(remote) {
+		return "", "", fmt.Errorf("--sync %q: the container path %s must be absolute", value, remote)
+	}
+	return local, path.Clean(remote), nil
+}
+
+// The container that --sync copies changes into, and the resource whose
+// logs the syncs show up in.
+func (c *createFileWatchCmd) syncSelector() (v1alpha1.LiveUpdateSelector, string, error) {
+	if c.syncService != "" {
+		return v1alpha1.LiveUpdateSelector{
+			DockerCompose: &v1alpha1.LiveUpdateDockerComposeSelector{Service: c.syncService},
+		}, c.syncService, nil
+	}
+	if c.syncContainer != "" {
+		resource, container, ok := strings.Cut(c.syncContainer, "/")
+		if !ok || resource == "" || container == "" || strings.Contains(container, "/") {
+			return v1alpha1.LiveUpdateSelector{}, "", fmt.Errorf("--sync-container must be RESOURCE/CONTAINER, got %q", c.syncContainer)
+		}
+		return v1alpha1.LiveUpdateSelector{
+			Kubernetes: &v1alpha1.LiveUpdateKubernetesSelector{
+				DiscoveryName: resource,
+				ApplyName:     resource,
+				ContainerName: container,
+			},
+		}, resource, nil
+	}
+	return v1alpha1.LiveUpdateSelector{}, "", fmt.Errorf("--sync needs --sync-service or --sync-container, to know which container to copy changes into")
+}
+
+// Resolves the local side of each --sync like PATHS, and returns the local
+// paths, so that they're watched too.
+func (c *createFileWatchCmd) resolveSyncs() ([]string, error) {
+	c.resolvedSyncs = nil
+	if len(c.syncs) == 0 {
+		return nil, nil
+	}
+
+	dir, err := c.dir()
+	if err != nil {
+		return nil, err
+	}
+
+	locals := []string{}
+	for _, value := range c.syncs {
+		local, remote, err := parseSync(value)
+		if err != nil {
+			return nil, err
+		}
+		expanded, err := c.expandEnv("--sync", []string{local})
+		if err != nil {
+			return nil, err
+		}
+		resolved, err := resolveFileWatchPaths(expanded, dir, c.allowMissing, !c.followLinks, 0)
+		if isMissingPathsError(err) {
+			return nil, fmt.Errorf("--sync %q: %v\n(use --allow-missing to sync it anyway)", value, err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("--sync %q: %v", value, err)
+		}
+		c.resolvedSyncs = append(c.resolvedSyncs, v1alpha1.LiveUpdateSync{LocalPath: resolved[0], ContainerPath: remote})
+		locals = append(locals, resolved[0])
+	}
+	return locals, nil
+}
+
+// With --sync, creates a LiveUpdate with the same name as the FileWatch,
+// which copies the changes the FileWatch reports into the container.
+//
+// If the LiveUpdate already exists, e.g., with --update, its spec is replaced.
+func (c *createFileWatchCmd) syncToContainer(ctx context.Context, fw *v1alpha1.FileWatch) error {
+	if len(c.resolvedSyncs) == 0 || c.helper.dryRun == dryRunClient {
+		return nil
+	}
+
+	selector, resource, err := c.syncSelector()
+	if err != nil {
+		return err
+	}
+	dir, err := c.dir()
+	if err != nil {
+		return err
+	}
+	lu := &v1alpha1.LiveUpdate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fw.Name,
+			Annotations: map[string]string{v1alpha1.AnnotationManifest: resource},
+		},
+		Spec: v1alpha1.LiveUpdateSpec{
+			BasePath: canonicalPath(dir),
+			Selector: selector,
+			Sources:  []v1alpha1.LiveUpdateSource{{FileWatch: fw.Name}},
+			Syncs:    c.resolvedSyncs,
+		},
+	}
+	u, err := toUnstructured(lu)
+	if err != nil {
+		return err
+	}
+
+	client := c.helper.dynamicClient.Resource(lu.GetGroupVersionResource())
+	_, err = client.Create(ctx, u, metav1.CreateOptions{FieldManager: c.helper.fieldManager})
+	if apierrors.IsAlreadyExists(err) {
+		existing, getErr := client.Get(ctx, lu.Name, metav1.GetOptions{})
+		if getErr != nil {
+			return wrapNoSessionError(getErr)
+		}
+		existing.Object["spec"] = u.Object["spec"]
+		_, err = client.Update(ctx, existing, metav1.UpdateOptions{FieldManager: c.helper.fieldManager})
+	}
+	if err != nil {
+		return fmt.Errorf("creating liveupdate %s: %v", lu.Name, wrapNoSessionError(err))
+	}
+	return nil
+}
+
+// How often to check --url URLs, if --poll-interval isn't specified.
+// Remote servers shouldn't be checked as often as local files.
+const defaultURLPollInterval = time.Minute
+
+// Sets the spec to check the --url URLs instead of local paths.
+func (c *createFileWatchCmd) applyURLs(spec *v1alpha1.FileWatchSpec, pathArgs []string) error {
+	if len(pathArgs) > 0 {
+		return fmt.Errorf("--url can't be used with PATHS, got %s (use separate FileWatches for URLs and local paths)",
+			strings.Join(pathArgs, " "))
+	}
+	for _, u := range c.urls {
+		parsed, err := url.Parse(u)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("--url must be an http or https URL, got %q", u)
+		}
+	}
+
+	spec.Mode = v1alpha1.FileWatchModeURL
+	spec.URLs = append([]string{}, c.urls...)
+	spec.PollInterval = metav1.Duration{Duration: defaultURLPollInterval}
+	if c.cmd.Flags().Changed("poll-interval") {
+		if c.pollInterval <= 0 {
+			return fmt.Errorf("--poll-interval must be positive, got %s", c.pollInterval)
+		}
+		spec.PollInterval = metav1.Duration{Duration: c.pollInterval}
+	}
+	return nil
+}
+
+// Warns about watched directories with more than --large-dir-threshold files.
+//
+// Ignored files don't count, since they're what the warning suggests.
+func (c *createFileWatchCmd) warnLargeDirs(paths []string, ignores []v1alpha1.IgnoreDef) {
+	if c.largeDirThreshold == 0 {
+		return
+	}
+
+	matcher := ignore.CreateFileChangeFilter(ignores)
+	for _, path := range paths {
+		if countFiles(path, matcher, c.largeDirThreshold+1) > c.largeDirThreshold {
+			_, _ = fmt.Fprintf(c.helper.streams.ErrOut,
+				"Warning: %s has more than %d files, so watching it may slow down tilt. "+
+					"Consider ignoring the parts you don't need with --ignore.\n",
+				path, c.largeDirThreshold)
+		}
+	}
+}
+
+// Warns about watched files with --recursive=false, since a file
+// is watched the same either way.
+func (c *createFileWatchCmd) warnNonRecursiveFiles(paths []string) {
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err == nil && !info.IsDir() {
+			_, _ = fmt.Fprintf(c.helper.streams.ErrOut,
+				"Warning: %s is a file, so --recursive=false doesn't change how it's watched\n", path)
+		}
+	}
+}
+
+// Warns about ignores that can't ignore anything, because their base path
+// neither contains nor is inside any of the watched paths. This usually
+// means that the command ran from an unrelated directory.
+func (c *createFileWatchCmd) warnUnrelatedIgnores(paths []string, ignores []v1alpha1.IgnoreDef) {
+	if c.noWarnIgnores {
+		return
+	}
+
+	for _, ignore := range unrelatedIgnores(paths, ignores) {
+		_, _ = fmt.Fprintf(c.helper.streams.ErrOut,
+			"Warning: the ignores relative to %s won't ignore anything, since no watched path is inside it. "+
+				"Check that you ran this from the right directory, or pass --no-warn-ignores.\n",
+			ignore.BasePath)
+	}
+}
+
+// Returns the ignores whose base path is unrelated to every watched path.
+func unrelatedIgnores(paths []string, ignores []v1alpha1.IgnoreDef) []v1alpha1.IgnoreDef {
+	var result []v1alpha1.IgnoreDef
+	for _, ignore := range ignores {
+		if !isRelatedIgnore(paths, ignore) {
+			result = append(result, ignore)
+		}
+	}
+	return result
+}
+
+// Returns copies of the ignores whose base path contains or
+// is inside a watched path.
+func relatedIgnores(paths []string, ignores []v1alpha1.IgnoreDef) []v1alpha1.IgnoreDef {
+	var result []v1alpha1.IgnoreDef
+	for _, ignore := range ignores {
+		if isRelatedIgnore(paths, ignore) {
+			result = append(result, *ignore.DeepCopy())
+		}
+	}
+	return result
+}
+
+func isRelatedIgnore(paths []string, ignore v1alpha1.IgnoreDef) bool {
+	for _, path := range paths {
+		base := ignore.BasePath
+		if ignore.IgnoreCase {
+			base, path = strings.ToLower(base), strings.ToLower(path)
+		}
+		if ospath.IsChild(base, path) || ospath.IsChild(path, base) {
+			return true
+		}
+	}
+	return false
+}
+
+// Counts the files under dir that aren't ignored, stopping at limit
+// so that huge trees don't take long to count.
+//
+// Errors are skipped, since the count is only used for a warning.
+func countFiles(dir string, matcher model.PathMatcher, limit int) int {
+	count := 0
+	_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if entry != nil && entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if entry.IsDir() {
+			skip, _ := matcher.MatchesEntireDir(path)
+			if skip {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		ignored, _ := matcher.Matches(path)
+		if ignored {
+			return nil
+		}
+		count++
+		if count >= limit {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return count
+}
+
+// Lists the files in spec's watched paths that its ignores don't match, sorted.
+// Stops once it finds more than limit files, so that huge trees don't take long,
+// and reports whether it did.
+//
+// Errors are skipped, like in countFiles.
+func matchingFiles(spec v1alpha1.FileWatchSpec, limit int) ([]string, bool) {
+	matcher := ignore.CreateFileChangeFilter(spec.Ignores)
+	seen := make(map[string]bool)
+	matches := []string{}
+	truncated := false
+	for _, root := range spec.WatchedPaths {
+		_ = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if entry != nil && entry.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if entry.IsDir() {
+				if path != root && spec.NonRecursive {
+					return filepath.SkipDir
+				}
+				skip, _ := matcher.MatchesEntireDir(path)
+				if skip {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			ignored, _ := matcher.Matches(path)
+			if ignored || seen[path] {
+				return nil
+			}
+			if len(matches) >= limit {
+				truncated = true
+				return filepath.SkipAll
+			}
+			seen[path] = true
+			matches = append(matches, path)
+			return nil
+		})
+		if truncated {
+			break
+		}
+	}
+	sort.Strings(matches)
+	return matches, truncated
+}
+
+// Returns the entries of base, overridden by those of overrides.
+func mergeStringMaps(base, overrides map[string]string) map[string]string {
+	if len(overrides) == 0 {
+		return base
+	}
+	result := make(map[string]string, len(base)+len(overrides))
+	for k, v := range base {
+		result[k] = v
+	}
+	for k, v := range overrides {
+		result[k] = v
+	}
+	return result
+}
+
+// Interprets --label and --annotation, and the annotations that --trigger-resource, --ttl, and --description add.
+func (c *createFileWatchCmd) metadata() (labels map[string]string, annotations map[string]string, err error) {
+	labels, err = parseKeyValues("label", c.labels, validation.IsValidLabelValue)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Annotation values can be arbitrary strings.
+	annotations, err = parseKeyValues("annotation", c.annotations, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if c.linkedResource != "" {
+		links := map[string]string{v1alpha1.AnnotationManifest: c.linkedResource}
+		if c.linkedTarget != "" {
+			links[v1alpha1.AnnotationTargetID] = c.linkedTarget
+		}
+		for _, key := range []string{v1alpha1.AnnotationManifest, v1alpha1.AnnotationTargetID} {
+			existing, ok := annotations[key]
+			if value, linked := links[key]; ok && linked && existing != value {
+				return nil, nil, fmt.Errorf("--annotation %s=%s conflicts with --trigger-resource %s", key, existing, c.linkedResource)
+			}
+		}
+		annotations = mergeStringMaps(annotations, links)
+	}
+
+	if c.ttl != 0 {
+		// The annotation only has second precision.
+		if c.ttl < time.Second {
+			return nil, nil, fmt.Errorf("--ttl must be at least 1s, got %s", c.ttl)
+		}
+		if existing, ok := annotations[v1alpha1.AnnotationExpiresAt]; ok {
+			return nil, nil, fmt.Errorf("--annotation %s=%s conflicts with --ttl %s", v1alpha1.AnnotationExpiresAt, existing, c.ttl)
+		}
+		expiresAt := time.Now().Add(c.ttl).UTC().Format(time.RFC3339)
+		annotations = mergeStringMaps(annotations, map[string]string{v1alpha1.AnnotationExpiresAt: expiresAt})
+	}
+
+	if c.description != "" {
+		if existing, ok := annotations[v1alpha1.AnnotationDescription]; ok {
+			return nil, nil, fmt.Errorf("--annotation %s=%s conflicts with --description %q", v1alpha1.AnnotationDescription, existing, c.description)
+		}
+		annotations = mergeStringMaps(annotations, map[string]string{v1alpha1.AnnotationDescription: c.description})
+	}
+	return labels, annotations, nil
+}
+
+// Parses KEY=VALUE flag values into a map. Returns nil if there are no values.
+//
+// Keys must be qualified names. If validateValue is non-nil, it checks each value.
+func parseKeyValues(flag string, values []string, validateValue func(string) []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	result := make(map[string]string, len(values))
+	for _, v := range values {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --%s %q: must be KEY=VALUE", flag, v)
+		}
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid --%s key %q: %s", flag, key, strings.Join(errs, "; "))
+		}
+		if validateValue != nil {
+			if errs := validateValue(value); len(errs) > 0 {
+				return nil, fmt.Errorf("invalid --%s value %q: %s", flag, value, strings.Join(errs, "; "))
+			}
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// Sets the watch mode from --poll and --poll-interval.
+//
+// A spec from --from-spec that's already in poll mode keeps it,
+// so that --poll-interval alone can change its interval.
+func (c *createFileWatchCmd) applyPoll(spec *v1alpha1.FileWatchSpec) error {
+	// --url sets its own interval.
+	if spec.Mode == v1alpha1.FileWatchModeURL {
+		return nil
+	}
+	intervalChanged := c.cmd.Flags().Changed("poll-interval")
+	if c.poll {
+		spec.Mode = v1alpha1.FileWatchModePoll
+		spec.PollInterval = metav1.Duration{Duration: c.pollInterval}
+	} else if intervalChanged {
+		if spec.Mode != v1alpha1.FileWatchModePoll {
+			return fmt.Errorf("--poll-interval requires --poll")
+		}
+		spec.PollInterval = metav1.Duration{Duration: c.pollInterval}
+	}
+
+	if spec.Mode == v1alpha1.FileWatchModePoll && spec.PollInterval.Duration <= 0 {
+		return fmt.Errorf("--poll-interval must be positive, got %s", spec.PollInterval.Duration)
+	}
+	return nil
+}
+
+// Interprets --since as either a duration before now, or an absolute RFC3339 timestamp.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("--since must not be negative, got %s", value)
+		}
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("--since must be a duration (like 5m) or an RFC3339 timestamp (like 2006-01-02T15:04:05Z), got %q", value)
+	}
+	return t, nil
+}
+
+// Interprets --rate-limit as N/DURATION: at most N events per DURATION.
+func parseRateLimit(value string) (int32, time.Duration, error) {
+	n, d, ok := strings.Cut(value, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("--rate-limit must be N/DURATION (like 5/10s), got %q", value)
+	}
+	events, err := strconv.ParseInt(n, 10, 32)
+	if err != nil || events < 1 {
+		return 0, 0, fmt.Errorf("--rate-limit events must be a whole number of at least 1, got %q", n)
+	}
+	window, err := time.ParseDuration(d)
+	if err != nil || window <= 0 {
+		return 0, 0, fmt.Errorf("--rate-limit window must be a positive duration (like 10s), got %q", d)
+	}
+	return int32(events), window, nil
+}
+
+// Reads the spec passed with --from-spec, if any.
+//
+// Relative paths in the spec are interpreted relative to --relative-to.
+func (c *createFileWatchCmd) baseSpec() (v1alpha1.FileWatchSpec, error) {
+	spec := v1alpha1.FileWatchSpec{}
+	if c.fromSpec == "" {
+		return spec, nil
+	}
+
+	var contents []byte
+	var err error
+	if c.fromSpec == "-" {
+		contents, err = io.ReadAll(c.helper.streams.In)
+	} else {
+		contents, err = os.ReadFile(c.fromSpec)
+	}
+	if err != nil {
+		return spec, fmt.Errorf("reading --from-spec: %v", err)
+	}
+
+	err = yaml.UnmarshalStrict(contents, &spec)
+	if err != nil {
+		return spec, fmt.Errorf("decoding --from-spec: %v", err)
+	}
+
+	dir, err := c.dir()
+	if err != nil {
+		return spec, err
+	}
+	resolveIgnoreBasePaths(dir, spec.Ignores)
+	return spec, nil
+}
+
+// Resolves the base paths of ignores against dir.
+//
+// An empty base path means dir itself.
+func resolveIgnoreBasePaths(dir string, ignores []v1alpha1.IgnoreDef) {
+	for i, ignore := range ignores {
+		if ignore.BasePath == "" {
+			ignores[i].BasePath = canonicalPath(dir)
+		} else if !filepath.IsAbs(ignore.BasePath) {
+			ignores[i].BasePath = canonicalPath(filepath.Join(dir, ignore.BasePath))
+		} else {
+			ignores[i].BasePath = canonicalPath(ignore.BasePath)
+		}
+	}
+}
+
+// Interprets the paths specified on the commandline.
+//
+// Paths with glob metacharacters are expanded against the filesystem.
+// All other paths must exist, unless --allow-missing is set.
+//
+// Symlinks are resolved, so that the watched paths match the paths
+// that the filesystem reports events on. The paths keep their order,
+// unless --sort-paths is set.
+//
+// With --no-cwd-join, the paths are used verbatim instead.
+func (c *createFileWatchCmd) paths(pathArgs []string) ([]string, error) {
+	if c.noCwdJoin {
+		if c.requireAbs {
+			err := checkAbsolutePaths(pathArgs, false)
+			if err != nil {
+				return nil, err
+			}
+		}
+		result := append([]string{}, pathArgs...)
+		if c.sortPaths {
+			sort.Strings(result)
+		}
+		return result, nil
+	}
+
+	dir, err := c.dir()
+	if err != nil {
+		return nil, err
+	}
+
+	pathArgs, err = c.expandEnv("path", pathArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.requireAbs {
+		err = checkAbsolutePaths(pathArgs, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !c.allowEscape {
+		err = checkEscapingPaths(pathArgs, dir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// With --follow-symlinks, the watcher resolves symlinks itself, and
+	// reports changes at the symlink's path.
+	result, err := resolveFileWatchPaths(pathArgs, dir, c.allowMissing, !c.followLinks, c.maxPaths)
+	if isMissingPathsError(err) {
+		return nil, fmt.Errorf("%v\n(use --allow-missing to watch them anyway)", err)
+	}
+	if isTooManyMatchesError(err) {
+		return nil, fmt.Errorf("%v\n(watch a directory that contains them instead, make the pattern more specific, or pass a higher --max-paths)", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.noCollapse {
+		var removed []collapsedPath
+		result, removed = collapsePaths(result)
+		for _, r := range removed {
+			_, _ = fmt.Fprintf(c.helper.streams.ErrOut, "Not watching %s separately, because it's inside %s\n", r.path, r.ancestor)
+		}
+	}
+	if c.sortPaths {
+		// sort.Strings compares bytes, so the order doesn't depend on the locale.
+		sort.Strings(result)
+	}
+	return result, nil
+}
+
+// Returns an error for the first path that isn't absolute, for
+// --require-absolute.
+//
+// With home set, paths starting with ~ count as absolute, since they're
+// expanded to the home directory later.
+func checkAbsolutePaths(paths []string, home bool) error {
+	for _, path := range paths {
+		absPath := path
+		if home {
+			var err error
+			absPath, err = expandHome(path)
+			if err != nil {
+				return err
+			}
+		}
+		if !filepath.IsAbs(absPath) {
+			return fmt.Errorf("--require-absolute: %q is not an absolute path", path)
+		}
+	}
+	return nil
+}
+
+// Returns an error if any relative path climbs out of dir with '..',
+// with the absolute paths they resolve to.
+//
+// Absolute paths and paths starting with ~ are left alone, since they
+// say where they are explicitly.
+func checkEscapingPaths(paths []string, dir string) error {
+	escaping := []string{}
+	for _, path := range paths {
+		if filepath.IsAbs(path) || strings.HasPrefix(path, "~") {
+			continue
+		}
+		absPath := filepath.Join(dir, path)
+		if !ospath.IsChild(dir, absPath) {
+			escaping = append(escaping, fmt.Sprintf("%s (%s)", path, absPath))
+		}
+	}
+	if len(escaping) > 0 {
+		return fmt.Errorf("paths are outside of %s: %s\n(use --allow-escape to watch them anyway)",
+			dir, strings.Join(escaping, ", "))
+	}
+	return nil
+}
+
+// A watched path dropped by collapsePaths, and the path that covers it.
+type collapsedPath struct {
+	path     string
+	ancestor string
+}
+
+// Removes paths that are inside (or the same as) another path in the list,
+// keeping the rest in order.
+func collapsePaths(paths []string) ([]string, []collapsedPath) {
+	result := []string{}
+	removed := []collapsedPath{}
+	for i, path := range paths {
+		ancestor := ""
+		for j, other := range paths {
+			if i == j || !ospath.IsChild(other, path) {
+				continue
+			}
+			// Of two identical paths, keep the first.
+			if other == path && j > i {
+				continue
+			}
+			if ancestor == "" || ospath.IsChild(other, ancestor) {
+				ancestor = other
+			}
+		}
+
+		if ancestor == "" {
+			result = append(result, path)
+		} else {
+			removed = append(removed, collapsedPath{path: path, ancestor: ancestor})
+		}
+	}
+	return result, removed
+}
+
+// Resolves any symlinks in an absolute path.
+//
+// If the path doesn't exist yet, returns it unchanged.
+func canonicalPath(path string) string {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return path
+	}
+	return resolved
+}
+
+// Whether the path contains any characters that filepath.Match treats specially.
+func hasGlobMeta(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// Expands an absolute glob pattern into the paths that match it, in lexical order.
+//
+// Unlike filepath.Glob, supports '**' to match any number of directories.
+// When a directory matches, its children are not included separately,
+// because the directory will be watched recursively anyway.
+func expandGlob(pattern string) ([]string, error) {
+	if !strings.Contains(pattern, "**") {
+		return filepath.Glob(pattern)
+	}
+
+	matcher, err := tiltDockerignore.NewPatternMatcher([]string{pattern})
+	if err != nil {
+		return nil, err
+	}
+
+	root := globRoot(pattern)
+	result := []string{}
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+
+		matches, err := matcher.Matches(path)
+		if err != nil {
+			return err
+		}
+		if !matches {
+			return nil
+		}
+
+		result = append(result, path)
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// Returns the longest directory prefix of the pattern
+// that doesn't contain any glob metacharacters.
+func globRoot(pattern string) string {
+	dir := pattern
+	for hasGlobMeta(dir) {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
+
+// Interprets the ignores specified on the commandline.
+//
+// The --only-ext patterns come first, then the --exclude-hidden pattern,
+// then the patterns from --ignore-file, in the order the files were given,
+// followed by the --ignore patterns. These all share the --relative-to
+// directory as their base path.
+//
+// Each path in --ignore-for gets its own IgnoreDef, sorted by base path.
+// So do the .gitignore files with --from-gitignore, based at the root
+// of the git repository.
+//
+// Repeated patterns are dropped (see dedupePatterns).
+func (c *createFileWatchCmd) ignores(watchedPaths []string) ([]v1alpha1.IgnoreDef, error) {
+	if len(c.ignoreValues) == 0 && len(c.ignoreFiles) == 0 && len(c.ignoreFor) == 0 && len(c.ignoreGlobs) == 0 && len(c.onlyExt) == 0 && !c.excludeHidden && !c.fromGitignore && c.envPrefix == "" && c.ignoreBase == "" {
+		return nil, nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	dir, err := c.dir()
+	if err != nil {
+		return nil, err
+	}
+	base, err := c.ignoreBaseDir(cwd, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []v1alpha1.IgnoreDef{}
+	if len(c.ignoreValues) > 0 || len(c.ignoreFiles) > 0 || len(c.onlyExt) > 0 || c.excludeHidden || c.envPrefix != "" {
+		// First, so that the other patterns can ignore files with these extensions.
+		patterns, err := onlyExtPatterns(c.onlyExt)
+		if err != nil {
+			return nil, err
+		}
+		if c.excludeHidden {
+			// First, so that later '!' patterns can re-include hidden paths.
+			patterns = append(patterns, hiddenPattern)
+		}
+		for _, ignoreFile := range c.ignoreFiles {
+			filePatterns, err := readIgnoreFile(cwd, ignoreFile)
+			if err != nil {
+				return nil, err
+			}
+			patterns = append(patterns, filePatterns...)
+		}
+		if c.envPrefix != "" {
+			envPatterns := envPrefixPatterns(c.envPrefix, os.Environ())
+			if len(envPatterns) == 0 {
+				_, _ = fmt.Fprintf(c.helper.streams.ErrOut,
+					"Warning: no environment variables start with --from-env-prefix %q, so it ignores nothing\n", c.envPrefix)
+			}
+			patterns = append(patterns, envPatterns...)
+		}
+		ignoreValues, err := c.expandEnv("--ignore", c.ignoreValues)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, ignoreValues...)
+
+		result = append(result, FileWatchIgnores(patterns, base, watchedPaths)...)
+	}
+
+	perPath, err := c.ignoresForPaths(dir)
+	if err != nil {
+		return nil, err
+	}
+	result = append(result, perPath...)
+
+	if c.fromGitignore {
+		gitignore, err := gitignoreIgnores(dir, watchedPaths)
+		if err != nil {
+			return nil, err
+		}
+		if gitignore != nil {
+			result = append(result, *gitignore)
+		}
+	}
+
+	globs, err := c.ignoresForGlobs(dir, watchedPaths)
+	if err != nil {
+		return nil, err
+	}
+	return mergeIgnoreDefs(result, globs), nil
+}
+
+// Translates --only-ext into ignore patterns: one that ignores everything,
+// followed by one that re-includes each extension.
+func onlyExtPatterns(exts []string) ([]string, error) {
+	if len(exts) == 0 {
+		return []string{}, nil
+	}
+
+	patterns := []string{"**/*"}
+	for _, ext := range exts {
+		ext = strings.TrimSpace(ext)
+		if len(ext) < 2 || ext[0] != '.' || strings.ContainsAny(ext, `/\*?[!`) {
+			return nil, fmt.Errorf("invalid --only-ext %q: must be a dot followed by the extension, like .go", ext)
+		}
+		patterns = append(patterns, "!**/*"+ext)
+	}
+	return patterns, nil
+}
+
+// Expands --ignore-glob into the paths it matches now, so that the FileWatch
+// only needs plain patterns.
+//
+// Each path becomes a pattern relative to the ignore root it's in (see ignoreRoots),
+// or if it's in none of them, relative to its parent directory.
+func (c *createFileWatchCmd) ignoresForGlobs(dir string, watchedPaths []string) ([]v1alpha1.IgnoreDef, error) {
+	globs, err := c.expandEnv("--ignore-glob", c.ignoreGlobs)
+	if err != nil {
+		return nil, err
+	}
+
+	roots := ignoreRoots(canonicalPath(dir), watchedPaths)
+	patternsByRoot := make(map[string][]string)
+	for _, glob := range globs {
+		pattern, err := expandHome(glob)
+		if err != nil {
+			return nil, err
+		}
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(dir, pattern)
+		}
+
+		matches, err := expandGlob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --ignore-glob %q: %v", glob, err)
+		}
+		if len(matches) == 0 {
+			_, _ = fmt.Fprintf(c.helper.streams.ErrOut, "Warning: --ignore-glob %q doesn't match any paths, so it ignores nothing\n", glob)
+			continue
+		}
+
+		for _, match := range matches {
+			match = canonicalPath(match)
+			root := filepath.Dir(match)
+			for _, r := range roots {
+				if rel, ok := ospath.Child(r, match); ok && rel != "." {
+					root = r
+					break
+				}
+			}
+			rel, err := filepath.Rel(root, match)
+			if err != nil {
+				return nil, err
+			}
+			patternsByRoot[root] = append(patternsByRoot[root], escapeIgnorePattern(filepath.ToSlash(rel)))
+		}
+	}
+
+	result := make([]v1alpha1.IgnoreDef, 0, len(patternsByRoot))
+	for root, patterns := range patternsByRoot {
+		result = append(result, v1alpha1.IgnoreDef{BasePath: root, Patterns: dedupePatterns(patterns)})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].BasePath < result[j].BasePath
+	})
+	return result, nil
+}
+
+// Escapes the characters that .dockerignore patterns treat specially,
+// so that the pattern only matches the literal path.
+//
+// On Windows, the backslash is the path separator, so nothing can be escaped.
+func escapeIgnorePattern(path string) string {
+	if filepath.Separator == '\\' {
+		return path
+	}
+
+	var b strings.Builder
+	for i, r := range path {
+		if strings.ContainsRune(`*?[]\`, r) || (i == 0 && r == '!') {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Adds the patterns of extra to the ignore in ignores with the same base path,
+// or if there's none, adds the ignore itself.
+func mergeIgnoreDefs(ignores, extra []v1alpha1.IgnoreDef) []v1alpha1.IgnoreDef {
+	for _, e := range extra {
+		merged := false
+		for i := range ignores {
+			if ignores[i].BasePath == e.BasePath {
+				ignores[i].Patterns = dedupePatterns(append(ignores[i].Patterns, e.Patterns...))
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			ignores = append(ignores, e)
+		}
+	}
+	return ignores
+}
+
+// Matches files and directories whose names start with a dot, at any depth.
+const hiddenPattern = "**/.*"
+
+// Trims whitespace from each pattern, and drops empty and repeated patterns,
+// keeping the patterns in the order they were first seen.
+//
+// With '!' patterns, the last matching pattern decides whether a path is
+// ignored, so a repeat is only dropped if no pattern of the opposite kind
+// comes between it and the earlier copy. Otherwise, it would change
+// which paths are ignored.
+func dedupePatterns(patterns []string) []string {
+	result := make([]string, 0, len(patterns))
+	seen := make(map[string]bool)
+	lastNegated := false
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+
+		negated := strings.HasPrefix(p, "!")
+		if negated != lastNegated {
+			seen = make(map[string]bool)
+			lastNegated = negated
+		}
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		result = append(result, p)
+	}
+	return result
+}
+
+// Interprets the --ignore-for flags into one IgnoreDef per base path.
+//
+// Patterns for the same path are combined, in the order they were given.
+func (c *createFileWatchCmd) ignoresForPaths(dir string) ([]v1alpha1.IgnoreDef, error) {
+	ignoreFor, err := c.expandEnv("--ignore-for", c.ignoreFor)
+	if err != nil {
+		return nil, err
+	}
+
+	patternsByPath := make(map[string][]string)
+	for _, value := range ignoreFor {
+		i := strings.LastIndex(value, ":")
+		if i <= 0 || i == len(value)-1 {
+			return nil, fmt.Errorf("invalid --ignore-for %q: must be PATH:PATTERN", value)
+		}
+
+		path := value[:i]
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		path = canonicalPath(path)
+		patternsByPath[path] = append(patternsByPath[path], value[i+1:])
+	}
+
+	result := make([]v1alpha1.IgnoreDef, 0, len(patternsByPath))
+	for path, patterns := range patternsByPath {
+		result = append(result, v1alpha1.IgnoreDef{BasePath: path, Patterns: dedupePatterns(patterns)})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].BasePath < result[j].BasePath
+	})
+	return result, nil
+}
+
+// Checks that every ignore pattern compiles.
+//
+// The FileWatch controller silently skips ignores that it can't parse,
+// so it's better to catch them here.
+func validateIgnores(ignores []v1alpha1.IgnoreDef) error {
+	for _, ignore := range ignores {
+		for _, pattern := range ignore.Patterns {
+			if strings.TrimSpace(strings.TrimPrefix(pattern, "!")) == "" {
+				return fmt.Errorf("invalid ignore pattern %q: negation must be followed by a pattern", pattern)
+			}
+
+			matcher, err := dockerignore.NewDockerPatternMatcher(ignore.BasePath, []string{pattern})
+			if err == nil {
+				// Some malformed patterns are only detected when matching.
+				_, err = matcher.Matches(ignore.BasePath)
+			}
+			if err != nil {
+				return fmt.Errorf("invalid ignore pattern %q: %v", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Reads the paths in the --paths-from file, one per line.
+//
+// Surrounding whitespace is trimmed, and blank lines and '#' comments are skipped.
+func (c *createFileWatchCmd) readPathsFrom() ([]string, error) {
+	if c.pathsFrom == "" {
+		return nil, nil
+	}
+
+	var r io.Reader
+	if c.pathsFrom == "-" {
+		r = c.helper.streams.In
+	} else {
+		f, err := os.Open(c.pathsFrom)
+		if err != nil {
+			return nil, fmt.Errorf("reading --paths-from: %v", err)
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+
+	var result []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		result = append(result, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading --paths-from: %v", err)
+	}
+	return result, nil
+}
+
+// Expands environment variables like $VAR and ${VAR} in values, unless --no-expand is set.
+//
+// Fails on undefined variables, and on values that expand to nothing,
+// so that a typo doesn't silently watch or ignore the current directory.
+func (c *createFileWatchCmd) expandEnv(what string, values []string) ([]string, error) {
+	if c.noExpand {
+		return values, nil
+	}
+
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		var undefined []string
+		expanded := os.Expand(value, func(name string) string {
+			v, ok := os.LookupEnv(name)
+			if !ok {
+				undefined = append(undefined, "$"+name)
+			}
+			return v
+		})
+		if len(undefined) > 0 {
+			return nil, fmt.Errorf("%s %q uses undefined environment variables: %s (use --no-expand to use it as is)",
+				what, value, strings.Join(undefined, ", "))
+		}
+		if expanded == "" && value != "" {
+			return nil, fmt.Errorf("%s %q expands to nothing, because its environment variables are empty (use --no-expand to use it as is)",
+				what, value)
+		}
+		result = append(result, expanded)
+	}
+	return result, nil
+}
+
+// Expands a leading ~ to the user's home directory, like a shell would
+// if the path weren't quoted.
+//
+// Only a bare ~ or a path starting with ~/ is expanded. Other paths, like
+// ~user/src or src/~, are returned unchanged.
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("expanding %q: %v", path, err)
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+// Collects the .gitignore files that apply to the watched paths into one
+// IgnoreDef, based at the root of the git repository that dir is in.
+//
+// These are the .gitignore files in dir and each of its parents up to the
+// root, followed by those inside the watched paths, except in directories
+// that the patterns so far ignore. Each file's patterns are
+// rewritten to be relative to the root, and come after those of its parents,
+// so that they take precedence like they do in git.
+//
+// Returns nil if there are no patterns.
+func gitignoreIgnores(dir string, watchedPaths []string) (*v1alpha1.IgnoreDef, error) {
+	root, ok := findGitRoot(canonicalPath(dir))
+	if !ok {
+		return nil, fmt.Errorf("--from-gitignore: %s isn't in a git repository", dir)
+	}
+
+	patterns := []string{}
+	seen := make(map[string]bool)
+	add := func(d string) error {
+		if seen[d] {
+			return nil
+		}
+		seen[d] = true
+		filePatterns, err := readGitignore(root, d)
+		if err != nil {
+			return err
+		}
+		patterns = append(patterns, filePatterns...)
+		return nil
+	}
+
+	// Parents first, so that the files inside the watched paths take precedence.
+	var parents []string
+	for d := canonicalPath(dir); ; d = filepath.Dir(d) {
+		parents = append([]string{d}, parents...)
+		if d == root {
+			break
+		}
+	}
+	for _, d := range parents {
+		err := add(d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range watchedPaths {
+		if !ospath.IsChild(root, path) || !ospath.IsDir(path) {
+			continue
+		}
+		err := filepath.WalkDir(path, func(p string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if !entry.IsDir() {
+				return nil
+			}
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			if p != path && len(patterns) > 0 {
+				matcher, err := dockerignore.NewDockerPatternMatcher(root, patterns)
+				if err != nil {
+					return err
+				}
+				// Like git, which doesn't look inside ignored directories,
+				// even if a later pattern would re-include some of their files.
+				ignored, err := matcher.Matches(p)
+				if err != nil {
+					return err
+				}
+				if ignored {
+					return filepath.SkipDir
+				}
+			}
+			return add(p)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("--from-gitignore: %v", err)
+		}
+	}
+
+	patterns = dedupePatterns(patterns)
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	return &v1alpha1.IgnoreDef{BasePath: root, Patterns: patterns}, nil
+}
+
+// Returns the closest directory at or above dir that has a .git entry.
+func findGitRoot(dir string) (string, bool) {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// Reads the .gitignore in dir, if there is one, and rewrites its patterns
+// to be relative to root (see gitignorePattern).
+func readGitignore(root, dir string) ([]string, error) {
+	contents, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("--from-gitignore: %v", err)
+	}
+
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return nil, err
+	}
+	prefix := ""
+	if rel != "." {
+		prefix = escapeIgnorePattern(filepath.ToSlash(rel)) + "/"
+	}
+
+	var result []string
+	for _, line := range strings.Split(string(contents), "\n") {
+		if pattern, ok := gitignorePattern(line); ok {
+			result = append(result, prefixIgnorePattern(prefix, pattern))
+		}
+	}
+	return result, nil
+}
+
+// Converts a line of a .gitignore into a .dockerignore-style pattern relative
+// to the directory of the .gitignore, or returns false if the line has none.
+//
+// Unlike .dockerignore patterns, .gitignore patterns without a slash match at
+// any depth, so they get a leading '**/'. A trailing slash, which makes git only
+// match directories, is dropped, since ignores can't tell files and directories apart.
+func gitignorePattern(line string) (string, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	if !strings.HasSuffix(line, "\\ ") {
+		line = strings.TrimRight(line, " \t")
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", false
+	}
+
+	negated := strings.HasPrefix(line, "!")
+	line = strings.TrimPrefix(line, "!")
+	if strings.HasPrefix(line, "\\#") {
+		line = line[1:]
+	}
+
+	line = strings.TrimSuffix(line, "/")
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return "", false
+	}
+	if !anchored && !strings.HasPrefix(line, "**/") {
+		line = "**/" + line
+	}
+	if negated {
+		line = "!" + line
+	}
+	return line, true
+}
+
+// Puts prefix in front of a pattern, after its '!' if it has one.
+func prefixIgnorePattern(prefix, pattern string) string {
+	if strings.HasPrefix(pattern, "!") {
+		return "!" + prefix + pattern[1:]
+	}
+	return prefix + pattern
+}
+
+// The directory that --ignore patterns are relative to: --ignore-base if it's
+// set, and dir otherwise. A relative --ignore-base is relative to cwd.
+func (c *createFileWatchCmd) ignoreBaseDir(cwd, dir string) (string, error) {
+	if c.ignoreBase == "" {
+		return dir, nil
+	}
+
+	base, err := expandHome(c.ignoreBase)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(base) {
+		base = filepath.Join(cwd, base)
+	}
+	info, err := os.Stat(base)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("--ignore-base %s does not exist", c.ignoreBase)
+		}
+		return "", fmt.Errorf("--ignore-base: %v", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("--ignore-base must be a directory, got %s", c.ignoreBase)
+	}
+	return base, nil
+}
+
+// The values of the environment variables in env (as KEY=value pairs, like
+// os.Environ) whose names start with prefix, in order of their names.
+// Empty values are skipped, since they'd ignore nothing.
+func envPrefixPatterns(prefix string, env []string) []string {
+	var names []string
+	values := make(map[string]string)
+	for _, kv := range env {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, prefix) || strings.TrimSpace(value) == "" {
+			continue
+		}
+		names = append(names, name)
+		values[name] = strings.TrimSpace(value)
+	}
+	sort.Strings(names)
+
+	patterns := make([]string, 0, len(names))
+	for _, name := range names {
+		patterns = append(patterns, values[name])
+	}
+	return patterns
+}
+
+// Reads the patterns from a .dockerignore-style file, relative to the current directory.
+func readIgnoreFile(cwd string, path string) ([]string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cwd, path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading --ignore-file: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	patterns, err := buildkitDockerignore.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading --ignore-file %s: %v", path, err)
+	}
+	return patterns, nil
 }
@@ -2,16 +2,49 @@ package cli
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/yaml"
 
+	"github.com/tilt-dev/tilt/internal/dockerignore"
+	"github.com/tilt-dev/tilt/internal/hud/server"
+	"github.com/tilt-dev/tilt/internal/ignore"
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/internal/testutils/bufsync"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/apis"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+	proto_webview "github.com/tilt-dev/tilt/pkg/webview"
+	"github.com/tilt-dev/wmclient/pkg/analytics"
 )
 
 func TestCreateFileWatch(t *testing.T) {
@@ -24,6 +57,7 @@ func TestCreateFileWatch(t *testing.T) {
 	c := cmd.register()
 	err := c.Flags().Parse([]string{
 		"--ignore", "web/node_modules",
+		"--allow-missing",
 		"my-watch", "src", "web",
 	})
 	require.NoError(t, err)
@@ -53,7 +87,7 @@ func TestCreateFileWatchNoIgnore(t *testing.T) {
 
 	cmd := newCreateFileWatchCmd(streams)
 	c := cmd.register()
-	err := c.Flags().Parse([]string{"my-watch", "src"})
+	err := c.Flags().Parse([]string{"--allow-missing", "my-watch", "src"})
 	require.NoError(t, err)
 
 	err = cmd.run(f.ctx, c.Flags().Args())
@@ -70,3 +104,4780 @@ func TestCreateFileWatchNoIgnore(t *testing.T) {
 	}, fw.Spec.WatchedPaths)
 	assert.Equal(t, 0, len(fw.Spec.Ignores))
 }
+
+func TestCreateFileWatchQuiet(t *testing.T) {
+	f := newServerFixture(t)
+
+	out := bytes.NewBuffer(nil)
+	streams := genericclioptions.IOStreams{Out: out}
+
+	cmd := newCreateFileWatchCmd(streams)
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"-q", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, "my-watch\n", out.String())
+}
+
+func TestCreateFileWatchOutputNameOnlyOnSuccess(t *testing.T) {
+	f := newServerFixture(t)
+
+	out := bytes.NewBuffer(nil)
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out, ErrOut: errOut})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--output-name-only-on-success", "-o", "yaml", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, "my-watch\n", out.String())
+	assert.Contains(t, errOut.String(), "kind: FileWatch")
+	assert.Contains(t, errOut.String(), "name: my-watch")
+
+	// When the create fails, stdout stays empty.
+	out.Reset()
+	cmd = newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out, ErrOut: errOut})
+	c = cmd.register()
+	err = c.Flags().Parse([]string{"--output-name-only-on-success", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.Error(t, err)
+	assert.True(t, apierrors.IsAlreadyExists(err))
+	assert.Empty(t, out.String())
+}
+
+func TestCreateFileWatchExitCode(t *testing.T) {
+	f := newServerFixture(t)
+	create := func() error {
+		cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+		c := cmd.register()
+		err := c.Flags().Parse([]string{"--allow-missing", "my-watch", "src"})
+		require.NoError(t, err)
+		return cmd.run(f.ctx, c.Flags().Args())
+	}
+
+	require.NoError(t, create())
+
+	var coded exitCodeError
+	err := create()
+	require.True(t, errors.As(err, &coded))
+	assert.Equal(t, exitCodeConflict, coded.code)
+}
+
+func TestCreateFileWatchOutputNameOnlyOnSuccessWithQuiet(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--output-name-only-on-success", "-q", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = c.ValidateFlagGroups()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "[output-name-only-on-success quiet] were all set")
+	}
+}
+
+func TestCreateFileWatchJSONLines(t *testing.T) {
+	f := newServerFixture(t)
+
+	out := bytes.NewBuffer(nil)
+	streams := genericclioptions.IOStreams{Out: out}
+
+	cmd := newCreateFileWatchCmd(streams)
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"-o", "jsonl", "--allow-missing", "my-watch", "src", "web"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	line, err := out.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "", out.String(), "expected a single line")
+
+	var fw v1alpha1.FileWatch
+	err = json.Unmarshal([]byte(line), &fw)
+	require.NoError(t, err)
+
+	cwd, _ := os.Getwd()
+	assert.Equal(t, "my-watch", fw.Name)
+	assert.Equal(t, []string{
+		filepath.Join(cwd, "src"),
+		filepath.Join(cwd, "web"),
+	}, fw.Spec.WatchedPaths)
+}
+
+func TestCreateFileWatchQuietWithOutput(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"-q", "-o", "name", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = c.ValidateFlagGroups()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "[output quiet] were all set")
+	}
+}
+
+func TestCreateFileWatchWait(t *testing.T) {
+	f := newServerFixture(t)
+
+	out := bytes.NewBuffer(nil)
+	streams := genericclioptions.IOStreams{Out: out}
+
+	cmd := newCreateFileWatchCmd(streams)
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--wait", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	go func() {
+		// Simulate the controller starting the monitor.
+		assert.Eventually(t, func() bool {
+			var fw v1alpha1.FileWatch
+			err := f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+			if err != nil {
+				return false
+			}
+			fw.Status.MonitorStartTime = apis.NowMicro()
+			return f.client.Status().Update(f.ctx, &fw) == nil
+		}, 5*time.Second, 10*time.Millisecond)
+	}()
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), `filewatch.tilt.dev/my-watch created`)
+}
+
+func TestCreateFileWatchWaitTimeout(t *testing.T) {
+	f := newServerFixture(t)
+
+	out := bytes.NewBuffer(nil)
+	streams := genericclioptions.IOStreams{Out: out}
+
+	cmd := newCreateFileWatchCmd(streams)
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--wait", "--wait-timeout=200ms", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	assert.EqualError(t, err, "timed out after 200ms waiting for filewatch my-watch to start watching")
+	assert.Equal(t, "", out.String())
+}
+
+func TestCreateFileWatchWaitError(t *testing.T) {
+	f := newServerFixture(t)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--wait", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	go func() {
+		assert.Eventually(t, func() bool {
+			var fw v1alpha1.FileWatch
+			err := f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+			if err != nil {
+				return false
+			}
+			fw.Status.Error = "filewatch init: too many open files"
+			return f.client.Status().Update(f.ctx, &fw) == nil
+		}, 5*time.Second, 10*time.Millisecond)
+	}()
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	assert.EqualError(t, err, "filewatch my-watch: filewatch init: too many open files")
+}
+
+func TestCreateFileWatchOutputStatus(t *testing.T) {
+	f := newServerFixture(t)
+
+	out := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out, ErrOut: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--output-status", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	startTime := metav1.NewMicroTime(time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC))
+	go func() {
+		// Simulate the controller starting the monitor.
+		assert.Eventually(t, func() bool {
+			var fw v1alpha1.FileWatch
+			err := f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+			if err != nil {
+				return false
+			}
+			fw.Status.MonitorStartTime = startTime
+			return f.client.Status().Update(f.ctx, &fw) == nil
+		}, 5*time.Second, 10*time.Millisecond)
+	}()
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, "lastEventTime: null\nmonitorStartTime: \"2021-03-04T05:06:07.000000Z\"\n", out.String())
+}
+
+func TestCreateFileWatchOutputStatusTimeout(t *testing.T) {
+	f := newServerFixture(t)
+
+	out := bytes.NewBuffer(nil)
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out, ErrOut: errOut})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--output-status", "--wait-timeout=200ms", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, "lastEventTime: null\nmonitorStartTime: null\n", out.String())
+	assert.Equal(t, "Warning: timed out after 200ms waiting for filewatch my-watch to report its status. Printing its status so far.\n",
+		errOut.String())
+}
+
+func TestCreateFileWatchOutputStatusDryRun(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--output-status", "--dry-run=client", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	assert.EqualError(t, err, "--output-status can't be used with --dry-run")
+}
+
+func TestCreateFileWatchRelativeToTiltfile(t *testing.T) {
+	f := newServerFixture(t)
+	f.MkdirAll("project/src")
+	f.MkdirAll("elsewhere")
+	f.Chdir()
+	require.NoError(t, os.Chdir(f.JoinPath("elsewhere")))
+
+	err := f.client.Create(f.ctx, &v1alpha1.Tiltfile{
+		ObjectMeta: metav1.ObjectMeta{Name: model.MainTiltfileManifestName.String()},
+		Spec:       v1alpha1.TiltfileSpec{Path: f.JoinPath("project", "Tiltfile")},
+	})
+	require.NoError(t, err)
+
+	out := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out})
+	c := cmd.register()
+	err = c.Flags().Parse([]string{"--relative-to=tiltfile", "--ignore=src/vendor", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+
+	projectDir, _ := filepath.EvalSymlinks(f.JoinPath("project"))
+	assert.Equal(t, []string{filepath.Join(projectDir, "src")}, fw.Spec.WatchedPaths)
+	assert.Equal(t, projectDir, fw.Spec.Ignores[0].BasePath)
+}
+
+func TestCreateFileWatchRelativeToTiltfileNotFound(t *testing.T) {
+	f := newServerFixture(t)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--relative-to=tiltfile", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "no Tiltfile found in the running tilt session")
+		assert.Contains(t, err.Error(), "--relative-to=cwd")
+	}
+}
+
+func TestCreateFileWatchRelativeToInvalid(t *testing.T) {
+	f := newServerFixture(t)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--relative-to=home", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	assert.EqualError(t, err, `--relative-to must be one of (cwd, tiltfile), got "home"`)
+}
+
+func TestCreateFileWatchUpdate(t *testing.T) {
+	f := newServerFixture(t)
+
+	create := func(args ...string) (string, error) {
+		out := bytes.NewBuffer(nil)
+		cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out})
+		c := cmd.register()
+		err := c.Flags().Parse(append([]string{"--allow-missing"}, args...))
+		require.NoError(t, err)
+		err = cmd.run(f.ctx, c.Flags().Args())
+		return out.String(), err
+	}
+
+	out, err := create("my-watch", "src")
+	require.NoError(t, err)
+	assert.Contains(t, out, `filewatch.tilt.dev/my-watch created`)
+
+	eventTime := metav1.NewMicroTime(time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC))
+	f.recordFileEvent("my-watch", eventTime, "/src/a.go")
+
+	_, err = create("my-watch", "web")
+	assert.True(t, apierrors.IsAlreadyExists(err), "expected AlreadyExists, got: %v", err)
+
+	out, err = create("--update", "my-watch", "web")
+	require.NoError(t, err)
+	assert.Contains(t, out, `filewatch.tilt.dev/my-watch updated`)
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+
+	cwd, _ := os.Getwd()
+	assert.Equal(t, []string{filepath.Join(cwd, "web")}, fw.Spec.WatchedPaths)
+	assert.True(t, eventTime.Equal(&fw.Status.LastEventTime), "status should be untouched")
+}
+
+func TestCreateFileWatchUpdateDryRunDiff(t *testing.T) {
+	f := newServerFixture(t)
+
+	create := func(args ...string) (string, error) {
+		out := bytes.NewBuffer(nil)
+		cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out})
+		c := cmd.register()
+		err := c.Flags().Parse(append([]string{"--allow-missing"}, args...))
+		require.NoError(t, err)
+		err = cmd.run(f.ctx, c.Flags().Args())
+		return out.String(), err
+	}
+
+	_, err := create("my-watch", "src", "lib")
+	require.NoError(t, err)
+
+	cwd, _ := os.Getwd()
+	out, err := create("--update", "--dry-run=client", "my-watch", "src", "web")
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`--- filewatch.tilt.dev/my-watch (existing)
++++ filewatch.tilt.dev/my-watch (updated)
+@@ -1,3 +1,3 @@
+ watchedPaths:
+ - %s
+-- %s
++- %s
+`, filepath.Join(cwd, "src"), filepath.Join(cwd, "lib"), filepath.Join(cwd, "web")), out)
+
+	// Nothing changed on the server.
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(cwd, "src"), filepath.Join(cwd, "lib")}, fw.Spec.WatchedPaths)
+
+	out, err = create("--update", "--dry-run=client", "my-watch", "src", "lib")
+	require.NoError(t, err)
+	assert.Empty(t, out, "no diff when the specs match")
+
+	out, err = create("--update", "--dry-run=client", "new-watch", "src")
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`--- filewatch.tilt.dev/new-watch (existing)
++++ filewatch.tilt.dev/new-watch (updated)
+@@ -0,0 +1,2 @@
++watchedPaths:
++- %s
+`, filepath.Join(cwd, "src")), out)
+}
+
+func TestCreateFileWatchEnsure(t *testing.T) {
+	f := newServerFixture(t)
+
+	create := func(args ...string) string {
+		out := bytes.NewBuffer(nil)
+		cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out})
+		c := cmd.register()
+		err := c.Flags().Parse(append([]string{"--allow-missing", "--ensure"}, args...))
+		require.NoError(t, err)
+		err = cmd.run(f.ctx, c.Flags().Args())
+		require.NoError(t, err)
+		return out.String()
+	}
+	get := func() v1alpha1.FileWatch {
+		var fw v1alpha1.FileWatch
+		err := f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+		require.NoError(t, err)
+		return fw
+	}
+
+	assert.Equal(t, "filewatch.tilt.dev/my-watch created\n", create("--label", "team=web", "my-watch", "src"))
+	created := get()
+
+	assert.Equal(t, "filewatch.tilt.dev/my-watch unchanged\n", create("my-watch", "src"))
+	assert.Equal(t, "filewatch.tilt.dev/my-watch unchanged\n", create("--label", "team=web", "my-watch", "src"))
+	assert.Equal(t, created.ResourceVersion, get().ResourceVersion)
+
+	assert.Equal(t, "filewatch.tilt.dev/my-watch updated\n", create("--label", "env=dev", "my-watch", "src"))
+	assert.Equal(t, map[string]string{"team": "web", "env": "dev"}, get().Labels)
+
+	assert.Equal(t, "filewatch.tilt.dev/my-watch updated\n", create("my-watch", "web"))
+	cwd, _ := os.Getwd()
+	assert.Equal(t, []string{filepath.Join(cwd, "web")}, get().Spec.WatchedPaths)
+
+	out := create("-o", "yaml", "my-watch", "web")
+	assert.Contains(t, out, "name: my-watch")
+	assert.Contains(t, out, filepath.Join(cwd, "web"))
+}
+
+func TestCreateFileWatchCreatedVerb(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--created-verb=registered", "--dry-run=client", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(newServerFixture(t).ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, "filewatch.tilt.dev/my-watch registered (dry run)\n", out.String())
+}
+
+func TestCreateFileWatchCreatedVerbEmpty(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--created-verb= ", "--dry-run=client", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(newServerFixture(t).ctx, c.Flags().Args())
+	assert.EqualError(t, err, "--created-verb cannot be empty")
+}
+
+func TestCreateFileWatchEnsureUpdate(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--ensure", "--update", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = c.ValidateFlagGroups()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "[ensure update] were all set")
+}
+
+func TestCreateFileWatchUpdateMergesLabels(t *testing.T) {
+	f := newServerFixture(t)
+
+	create := func(args ...string) {
+		cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+		c := cmd.register()
+		err := c.Flags().Parse(append([]string{"--allow-missing"}, args...))
+		require.NoError(t, err)
+		err = cmd.run(f.ctx, c.Flags().Args())
+		require.NoError(t, err)
+	}
+
+	create("--label", "team=web", "--label", "env=dev", "my-watch", "src")
+	create("--update", "--label", "env=prod", "my-watch", "src")
+
+	var fw v1alpha1.FileWatch
+	err := f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "web", "env": "prod"}, fw.Labels)
+}
+
+func TestCreateFileWatchDebounce(t *testing.T) {
+	f := newServerFixture(t)
+
+	out := bytes.NewBuffer(nil)
+	streams := genericclioptions.IOStreams{Out: out}
+
+	cmd := newCreateFileWatchCmd(streams)
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--debounce=250ms", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+	assert.Equal(t, 250*time.Millisecond, fw.Spec.DebounceDuration.Duration)
+}
+
+func TestCreateFileWatchMaxEvents(t *testing.T) {
+	f := newServerFixture(t)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--max-events=1", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), fw.Spec.MaxEvents)
+}
+
+func TestCreateFileWatchMaxEventsDefault(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), fw.Spec.MaxEvents)
+}
+
+func TestCreateFileWatchMaxEventsTooSmall(t *testing.T) {
+	for _, value := range []string{"0", "-1"} {
+		t.Run(value, func(t *testing.T) {
+			cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+			c := cmd.register()
+			err := c.Flags().Parse([]string{"--max-events=" + value, "--allow-missing", "my-watch", "src"})
+			require.NoError(t, err)
+
+			_, err = cmd.object(c.Flags().Args())
+			assert.EqualError(t, err, "--max-events must be at least 1, got "+value)
+		})
+	}
+}
+
+func TestCreateFileWatchHeartbeat(t *testing.T) {
+	f := newServerFixture(t)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--heartbeat=30s", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, fw.Spec.HeartbeatInterval.Duration)
+}
+
+func TestCreateFileWatchHeartbeatNotPositive(t *testing.T) {
+	for _, value := range []string{"0s", "-1s"} {
+		t.Run(value, func(t *testing.T) {
+			cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+			c := cmd.register()
+			err := c.Flags().Parse([]string{"--heartbeat=" + value, "--allow-missing", "my-watch", "src"})
+			require.NoError(t, err)
+
+			_, err = cmd.object(c.Flags().Args())
+			assert.EqualError(t, err, "--heartbeat must be positive, got "+value)
+		})
+	}
+}
+
+func TestParseRateLimit(t *testing.T) {
+	events, window, err := parseRateLimit("5/10s")
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), events)
+	assert.Equal(t, 10*time.Second, window)
+
+	for _, tc := range []struct {
+		value         string
+		expectedError string
+	}{
+		{"5", `--rate-limit must be N/DURATION (like 5/10s), got "5"`},
+		{"10s", `--rate-limit must be N/DURATION (like 5/10s), got "10s"`},
+		{"/10s", `--rate-limit events must be a whole number of at least 1, got ""`},
+		{"five/10s", `--rate-limit events must be a whole number of at least 1, got "five"`},
+		{"1.5/10s", `--rate-limit events must be a whole number of at least 1, got "1.5"`},
+		{"0/10s", `--rate-limit events must be a whole number of at least 1, got "0"`},
+		{"-1/10s", `--rate-limit events must be a whole number of at least 1, got "-1"`},
+		{"9999999999/10s", `--rate-limit events must be a whole number of at least 1, got "9999999999"`},
+		{"5/", `--rate-limit window must be a positive duration (like 10s), got ""`},
+		{"5/10", `--rate-limit window must be a positive duration (like 10s), got "10"`},
+		{"5/0s", `--rate-limit window must be a positive duration (like 10s), got "0s"`},
+		{"5/-10s", `--rate-limit window must be a positive duration (like 10s), got "-10s"`},
+		{"5/10s/1m", `--rate-limit window must be a positive duration (like 10s), got "10s/1m"`},
+	} {
+		_, _, err := parseRateLimit(tc.value)
+		assert.EqualError(t, err, tc.expectedError, tc.value)
+	}
+}
+
+func TestCreateFileWatchRateLimit(t *testing.T) {
+	f := newServerFixture(t)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--rate-limit=5/10s", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), fw.Spec.RateLimitEvents)
+	assert.Equal(t, 10*time.Second, fw.Spec.RateLimitWindow.Duration)
+}
+
+func TestCreateFileWatchJSONPatch(t *testing.T) {
+	f := newServerFixture(t)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{
+		`--json-patch=[{"op":"add","path":"/spec/maxEvents","value":10},{"op":"add","path":"/metadata/labels","value":{"team":"web"}}]`,
+		"--allow-missing", "my-watch", "src",
+	})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+	assert.Equal(t, int32(10), fw.Spec.MaxEvents)
+	assert.Equal(t, map[string]string{"team": "web"}, fw.Labels)
+}
+
+func TestCreateFileWatchJSONPatchInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		patch         string
+		expectedError string
+	}{
+		{`{"op":"add"}`, "--json-patch must be a JSON array of patch operations: "},
+		{`[{"op":"add"`, "--json-patch must be a JSON array of patch operations: "},
+		{`[{"op":"frobnicate","path":"/spec/maxEvents","value":1}]`, "--json-patch: Unexpected kind: frobnicate"},
+		{`[{"op":"add","path":"/spec/maxEvents","value":"ten"}]`, "--json-patch: "},
+		{`[{"op":"add","path":"/spec/maxEvent","value":10}]`, `--json-patch: strict decoding error: unknown field "spec.maxEvent"`},
+	} {
+		t.Run(tc.patch, func(t *testing.T) {
+			cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+			c := cmd.register()
+			err := c.Flags().Parse([]string{"--json-patch=" + tc.patch, "--allow-missing", "my-watch", "src"})
+			require.NoError(t, err)
+
+			_, err = cmd.object(c.Flags().Args())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedError)
+		})
+	}
+}
+
+func TestCreateFileWatchJSONPatchMalformedBeforeConnecting(t *testing.T) {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--json-patch=not json", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	// There's no tilt session, so the command would fail differently
+	// if it connected.
+	err = cmd.run(ctx, c.Flags().Args())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--json-patch must be a JSON array of patch operations")
+	assert.Equal(t, exitCodeValidation, exitCode(err))
+}
+
+func TestCreateFileWatchURL(t *testing.T) {
+	f := newServerFixture(t)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--url=https://example.com/a.tar.gz", "--url=http://localhost:8000/b", "my-watch"})
+	require.NoError(t, err)
+	require.NoError(t, c.ValidateArgs(c.Flags().Args()))
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+	assert.Equal(t, v1alpha1.FileWatchModeURL, fw.Spec.Mode)
+	assert.Equal(t, []string{"https://example.com/a.tar.gz", "http://localhost:8000/b"}, fw.Spec.URLs)
+	assert.Empty(t, fw.Spec.WatchedPaths)
+	assert.Equal(t, time.Minute, fw.Spec.PollInterval.Duration)
+}
+
+func TestCreateFileWatchURLPollInterval(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--url=https://example.com/a", "--poll-interval=5m", "my-watch"})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, fw.Spec.PollInterval.Duration)
+}
+
+func TestCreateFileWatchURLInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		args          []string
+		expectedError string
+	}{
+		{[]string{"--url=https://example.com/a", "my-watch", "src"},
+			"--url can't be used with PATHS, got src (use separate FileWatches for URLs and local paths)"},
+		{[]string{"--url=example.com/a", "my-watch"}, `--url must be an http or https URL, got "example.com/a"`},
+		{[]string{"--url=file:///etc/hosts", "my-watch"}, `--url must be an http or https URL, got "file:///etc/hosts"`},
+		{[]string{"--url=https://example.com/a", "--poll-interval=0s", "my-watch"}, "--poll-interval must be positive, got 0s"},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+			c := cmd.register()
+			err := c.Flags().Parse(tc.args)
+			require.NoError(t, err)
+
+			_, err = cmd.object(c.Flags().Args())
+			assert.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestCreateFileWatchURLWithPathFlag(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--url=https://example.com/a", "--ignore=*.tmp", "my-watch"})
+	require.NoError(t, err)
+
+	err = c.ValidateFlagGroups()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "[ignore url] were all set")
+}
+
+func TestCreateFileWatchNonRecursive(t *testing.T) {
+	f := newServerFixture(t)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--recursive=false", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+	assert.True(t, fw.Spec.NonRecursive)
+}
+
+func TestCreateFileWatchRecursiveDefault(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	assert.False(t, fw.Spec.NonRecursive)
+}
+
+func TestCreateFileWatchNonRecursiveFileWarning(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.WriteFile(filepath.Join("src", "main.go"), "package main")
+	f.WriteFile("Makefile", "all:")
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: errOut})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--recursive=false", "my-watch", "src", "Makefile"})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	assert.True(t, fw.Spec.NonRecursive)
+	assert.Equal(t,
+		fmt.Sprintf("Warning: %s is a file, so --recursive=false doesn't change how it's watched\n",
+			canonicalPath(f.JoinPath("Makefile"))),
+		errOut.String())
+}
+
+func TestCreateFileWatchFollowSymlinks(t *testing.T) {
+	if filepath.Separator == '\\' {
+		t.Skip("no user-space symlinks on windows")
+	}
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.WriteFile(filepath.Join("real", "main.go"), "package main")
+	require.NoError(t, os.Symlink(f.JoinPath("real"), f.JoinPath("src")))
+	cwd, _ := filepath.EvalSymlinks(f.Path())
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--follow-symlinks", "my-watch", "src"})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	assert.True(t, fw.Spec.FollowSymlinks)
+	assert.Equal(t, []string{filepath.Join(cwd, "src")}, fw.Spec.WatchedPaths)
+}
+
+func TestCreateFileWatchResolvesSymlinksByDefault(t *testing.T) {
+	if filepath.Separator == '\\' {
+		t.Skip("no user-space symlinks on windows")
+	}
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.WriteFile(filepath.Join("real", "main.go"), "package main")
+	require.NoError(t, os.Symlink(f.JoinPath("real"), f.JoinPath("src")))
+	cwd, _ := filepath.EvalSymlinks(f.Path())
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"my-watch", "src"})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	assert.False(t, fw.Spec.FollowSymlinks)
+	assert.Equal(t, []string{filepath.Join(cwd, "real")}, fw.Spec.WatchedPaths)
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	since, err := parseSince("5m", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-5*time.Minute), since)
+
+	since, err = parseSince("2021-05-31T08:30:00Z", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 5, 31, 8, 30, 0, 0, time.UTC), since)
+
+	since, err = parseSince("2021-05-31T08:30:00-04:00", now)
+	require.NoError(t, err)
+	assert.True(t, time.Date(2021, 5, 31, 12, 30, 0, 0, time.UTC).Equal(since))
+
+	_, err = parseSince("-5m", now)
+	assert.EqualError(t, err, "--since must not be negative, got -5m")
+
+	for _, value := range []string{"yesterday", "5", "2021-05-31"} {
+		_, err = parseSince(value, now)
+		assert.EqualError(t, err, fmt.Sprintf(
+			"--since must be a duration (like 5m) or an RFC3339 timestamp (like 2006-01-02T15:04:05Z), got %q", value))
+	}
+}
+
+func TestCreateFileWatchSince(t *testing.T) {
+	f := newServerFixture(t)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--since=2021-05-31T08:30:00Z", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+	assert.True(t, time.Date(2021, 5, 31, 8, 30, 0, 0, time.UTC).Equal(fw.Spec.Since.Time))
+}
+
+func TestCreateFileWatchSinceDuration(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--since=5m", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	before := time.Now()
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	assert.WithinDuration(t, before.Add(-5*time.Minute), fw.Spec.Since.Time, time.Second)
+}
+
+func TestCreateFileWatchSinceInvalid(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--since=yesterday", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	_, err = cmd.object(c.Flags().Args())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `got "yesterday"`)
+}
+
+func TestCreateFileWatchNegativeDebounce(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--debounce=-1s", "my-watch", "src"})
+	require.NoError(t, err)
+
+	_, err = cmd.object(c.Flags().Args())
+	assert.EqualError(t, err, "--debounce must not be negative, got -1s")
+}
+
+func TestCreateFileWatchDryRunClient(t *testing.T) {
+	// No server fixture: a client dry run shouldn't connect to the tilt session.
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+
+	out := bytes.NewBuffer(nil)
+	streams := genericclioptions.IOStreams{Out: out}
+
+	cmd := newCreateFileWatchCmd(streams)
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--dry-run=client", "--allow-missing", "-o", "yaml", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var fw v1alpha1.FileWatch
+	err = yaml.Unmarshal(out.Bytes(), &fw)
+	require.NoError(t, err)
+	cwd, _ := os.Getwd()
+	assert.Equal(t, "FileWatch", fw.Kind)
+	assert.Equal(t, "my-watch", fw.Name)
+	assert.Equal(t, []string{filepath.Join(cwd, "src")}, fw.Spec.WatchedPaths)
+}
+
+func TestCreateFileWatchDryRunClientWide(t *testing.T) {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+
+	out := bytes.NewBuffer(nil)
+	streams := genericclioptions.IOStreams{Out: out}
+
+	cmd := newCreateFileWatchCmd(streams)
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--dry-run=client", "--allow-missing", "-o", "wide", "--ignore", "*.tmp", "my-watch", "src", "web"})
+	require.NoError(t, err)
+
+	err = cmd.run(ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	// Nothing has watched the paths yet, so there's no status.
+	cwd, _ := os.Getwd()
+	assert.Equal(t, []string{"NAME", "PATHS", "IGNORES", "BASE", "STATUS"}, strings.Fields(strings.Split(out.String(), "\n")[0]))
+	assert.Equal(t, []string{"my-watch", "2", "1", cwd, "<none>"}, strings.Fields(strings.Split(out.String(), "\n")[1]))
+}
+
+func TestCreateFileWatchTimings(t *testing.T) {
+	f := newServerFixture(t)
+
+	run := func(args ...string) string {
+		errOut := bytes.NewBuffer(nil)
+		cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: errOut})
+		c := cmd.register()
+		err := c.Flags().Parse(append([]string{"--allow-missing"}, args...))
+		require.NoError(t, err)
+		err = cmd.run(f.ctx, c.Flags().Args())
+		require.NoError(t, err)
+		return errOut.String()
+	}
+
+	errOut := run("--timings", "my-watch", "src")
+	lines := strings.Split(strings.TrimSpace(errOut), "\n")
+	require.Len(t, lines, 4, errOut)
+	for i, step := range []string{"client setup", "API version check", "path resolution", "create"} {
+		assert.True(t, strings.HasPrefix(lines[i], fmt.Sprintf("Timing: %s took ", step)), "line %d: %s", i, lines[i])
+		_, err := time.ParseDuration(strings.TrimPrefix(lines[i], fmt.Sprintf("Timing: %s took ", step)))
+		assert.NoError(t, err)
+	}
+
+	errOut = run("other-watch", "src")
+	assert.NotContains(t, errOut, "Timing:")
+}
+
+func TestCreateFileWatchDryRunClientName(t *testing.T) {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+
+	out := bytes.NewBuffer(nil)
+	streams := genericclioptions.IOStreams{Out: out}
+
+	cmd := newCreateFileWatchCmd(streams)
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--dry-run=client", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, "filewatch.tilt.dev/my-watch created (dry run)\n", out.String())
+}
+
+func TestCreateFileWatchDryRunServer(t *testing.T) {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--dry-run=server", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(ctx, c.Flags().Args())
+	assert.EqualError(t, err, "--dry-run=server is not supported by the tilt session. Use --dry-run=client")
+}
+
+func TestCreateFileWatchDryRunInvalid(t *testing.T) {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--dry-run=maybe", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(ctx, c.Flags().Args())
+	assert.EqualError(t, err, `--dry-run must be one of (none, client), got "maybe"`)
+}
+
+func TestCreateFileWatchDryRunWait(t *testing.T) {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--dry-run=client", "--wait", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(ctx, c.Flags().Args())
+	assert.EqualError(t, err, "--wait can't be used with --dry-run")
+}
+
+func TestCreateFileWatchPoll(t *testing.T) {
+	f := newServerFixture(t)
+
+	out := bytes.NewBuffer(nil)
+	streams := genericclioptions.IOStreams{Out: out}
+
+	cmd := newCreateFileWatchCmd(streams)
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--poll", "--poll-interval=2s", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+	assert.Equal(t, v1alpha1.FileWatchModePoll, fw.Spec.Mode)
+	assert.Equal(t, 2*time.Second, fw.Spec.PollInterval.Duration)
+}
+
+func TestCreateFileWatchPollDefaultInterval(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--poll", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, v1alpha1.FileWatchModePoll, fw.Spec.Mode)
+	assert.Equal(t, time.Second, fw.Spec.PollInterval.Duration)
+}
+
+func TestCreateFileWatchNoPoll(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, v1alpha1.FileWatchMode(""), fw.Spec.Mode)
+	assert.Equal(t, time.Duration(0), fw.Spec.PollInterval.Duration)
+}
+
+func TestCreateFileWatchPollIntervalNotPositive(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--poll", "--poll-interval=0s", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	_, err = cmd.object(c.Flags().Args())
+	assert.EqualError(t, err, "--poll-interval must be positive, got 0s")
+}
+
+func TestCreateFileWatchPollIntervalWithoutPoll(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--poll-interval=2s", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	_, err = cmd.object(c.Flags().Args())
+	assert.EqualError(t, err, "--poll-interval requires --poll")
+}
+
+func TestCreateFileWatchLabelsAndAnnotations(t *testing.T) {
+	f := newServerFixture(t)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{
+		"--label", "team=web",
+		"--label", "tilt.dev/ad-hoc=true",
+		"--annotation", "owner=jane@example.com",
+		"--annotation", "note=watch=everything, carefully",
+		"--allow-missing",
+		"my-watch", "src",
+	})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "web", "tilt.dev/ad-hoc": "true"}, fw.Labels)
+	assert.Equal(t, map[string]string{
+		"owner": "jane@example.com",
+		"note":  "watch=everything, carefully",
+	}, fw.Annotations)
+}
+
+func TestCreateFileWatchPatch(t *testing.T) {
+	existing, err := toUnstructured(&v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-watch", ResourceVersion: "7"},
+		Spec: v1alpha1.FileWatchSpec{
+			WatchedPaths: []string{"/src"},
+			Ignores:      []v1alpha1.IgnoreDef{{BasePath: "/src", Patterns: []string{"node_modules"}}},
+		},
+	})
+	require.NoError(t, err)
+	client := dynamicfake.NewSimpleDynamicClient(v1alpha1.NewScheme())
+	_, err = client.Resource((&v1alpha1.FileWatch{}).GetGroupVersionResource()).Create(context.Background(), existing, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	out := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out, ErrOut: bytes.NewBuffer(nil)})
+	cmd.helper.dynamicClient = client
+	c := cmd.register()
+	err = c.Flags().Parse([]string{"--patch", "--add-ignore", "*.log", "--add-ignore", "node_modules", "my-watch"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, "filewatch.tilt.dev/my-watch patched\n", out.String())
+
+	var patches []string
+	for _, action := range client.Actions() {
+		if patch, ok := action.(k8stesting.PatchAction); ok {
+			assert.Equal(t, types.MergePatchType, patch.GetPatchType())
+			patches = append(patches, string(patch.GetPatch()))
+		}
+	}
+	assert.Equal(t, []string{
+		`{"metadata":{"resourceVersion":"7"},"spec":{"ignores":[{"basePath":"/src","patterns":["node_modules","*.log"]}]}}`,
+	}, patches)
+
+	obj, err := client.Resource((&v1alpha1.FileWatch{}).GetGroupVersionResource()).Get(ctx, "my-watch", metav1.GetOptions{})
+	require.NoError(t, err)
+	var fw v1alpha1.FileWatch
+	require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &fw))
+	assert.Equal(t, []string{"/src"}, fw.Spec.WatchedPaths)
+	assert.Equal(t, []string{"node_modules", "*.log"}, fw.Spec.Ignores[0].Patterns)
+}
+
+func TestCreateFileWatchPatchNoIgnores(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+
+	existing, err := toUnstructured(&v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-watch"},
+		Spec:       v1alpha1.FileWatchSpec{WatchedPaths: []string{"/src"}},
+	})
+	require.NoError(t, err)
+	client := dynamicfake.NewSimpleDynamicClient(v1alpha1.NewScheme())
+	_, err = client.Resource((&v1alpha1.FileWatch{}).GetGroupVersionResource()).Create(context.Background(), existing, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: bytes.NewBuffer(nil)})
+	cmd.helper.dynamicClient = client
+	c := cmd.register()
+	err = c.Flags().Parse([]string{"--patch", "--add-ignore", "*.log", "my-watch"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	obj, err := client.Resource((&v1alpha1.FileWatch{}).GetGroupVersionResource()).Get(ctx, "my-watch", metav1.GetOptions{})
+	require.NoError(t, err)
+	var fw v1alpha1.FileWatch
+	require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &fw))
+	cwd, _ := os.Getwd()
+	assert.Equal(t, []v1alpha1.IgnoreDef{{BasePath: cwd, Patterns: []string{"*.log"}}}, fw.Spec.Ignores)
+}
+
+func TestCreateFileWatchPatchNotFound(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(v1alpha1.NewScheme())
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: errOut})
+	cmd.helper.dynamicClient = client
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--patch", "--add-ignore", "*.log", "my-watch"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	require.Error(t, err)
+	assert.Equal(t, "no FileWatch named my-watch in the tilt session (use 'tilt create filewatch' without --patch to create it)", err.Error())
+}
+
+func TestCreateFileWatchPatchInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"--patch", "my-watch"}, "--patch and --add-ignore must be used together"},
+		{[]string{"--add-ignore", "*.log", "my-watch", "src"}, "--patch and --add-ignore must be used together"},
+		{[]string{"--patch", "--add-ignore", "*.log", "--dry-run=client", "my-watch"},
+			"--patch can't be used with --dry-run, since it needs the existing FileWatch"},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: bytes.NewBuffer(nil)})
+			cmd.helper.dynamicClient = dynamicfake.NewSimpleDynamicClient(v1alpha1.NewScheme())
+			c := cmd.register()
+			err := c.Flags().Parse(tc.args)
+			require.NoError(t, err)
+
+			ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+			err = cmd.run(ctx, c.Flags().Args())
+			require.Error(t, err)
+			assert.Equal(t, tc.err, err.Error())
+			assert.Equal(t, exitCodeValidation, exitCode(err))
+		})
+	}
+}
+
+func TestCreateFileWatchForKustomize(t *testing.T) {
+	f := newServerFixture(t)
+
+	out := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"-o", "yaml", "--for-kustomize", "--label", "team=web", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var obj map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(out.Bytes(), &obj))
+	assert.NotContains(t, obj, "status")
+	metadata := obj["metadata"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"name":   "my-watch",
+		"labels": map[string]interface{}{"team": "web"},
+	}, metadata)
+	assert.Equal(t, "FileWatch", obj["kind"])
+	assert.Contains(t, obj, "spec")
+
+	// The FileWatch in the session still has them.
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+	assert.NotEmpty(t, fw.ResourceVersion)
+	assert.NotEmpty(t, fw.UID)
+}
+
+func TestCreateFileWatchForKustomizeDryRun(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+
+	out := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--dry-run=client", "-o", "yaml", "--for-kustomize", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.NotContains(t, out.String(), "status:")
+	assert.NotContains(t, out.String(), "creationTimestamp")
+	assert.Contains(t, out.String(), "name: my-watch")
+}
+
+func TestCreateFileWatchForKustomizeInvalid(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--dry-run=client", "--for-kustomize", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	assert.EqualError(t, err, `--for-kustomize needs -o yaml or -o json, got ""`)
+	assert.Equal(t, exitCodeValidation, exitCode(err))
+}
+
+func TestExportableObject(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "tilt.dev/v1alpha1",
+		"kind":       "FileWatch",
+		"metadata": map[string]interface{}{
+			"name":              "my-watch",
+			"uid":               "1234",
+			"resourceVersion":   "7",
+			"generation":        int64(2),
+			"creationTimestamp": "2021-01-01T00:00:00Z",
+			"managedFields":     []interface{}{map[string]interface{}{"manager": "tilt"}},
+			"ownerReferences":   []interface{}{map[string]interface{}{"kind": "Cmd", "name": "server", "uid": "5678"}},
+			"annotations":       map[string]interface{}{"owner": "jane"},
+		},
+		"spec":   map[string]interface{}{"watchedPaths": []interface{}{"/src"}},
+		"status": map[string]interface{}{"monitorStartTime": "2021-01-01T00:00:00Z"},
+	}}
+
+	assert.Equal(t, map[string]interface{}{
+		"apiVersion": "tilt.dev/v1alpha1",
+		"kind":       "FileWatch",
+		"metadata": map[string]interface{}{
+			"name":        "my-watch",
+			"annotations": map[string]interface{}{"owner": "jane"},
+		},
+		"spec": map[string]interface{}{"watchedPaths": []interface{}{"/src"}},
+	}, exportableObject(obj).Object)
+	// The original is unchanged.
+	assert.Equal(t, "7", obj.GetResourceVersion())
+}
+
+func TestCreateFileWatchTTL(t *testing.T) {
+	f := newServerFixture(t)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--ttl=10m", "--annotation", "owner=jane", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	start := time.Now().Truncate(time.Second)
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+	assert.Equal(t, "jane", fw.Annotations["owner"])
+	expiresAt, err := time.Parse(time.RFC3339, fw.Annotations[v1alpha1.AnnotationExpiresAt])
+	require.NoError(t, err)
+	assert.False(t, expiresAt.Before(start.Add(10*time.Minute)), "expires at %s", expiresAt)
+	assert.False(t, expiresAt.After(time.Now().Add(10*time.Minute)), "expires at %s", expiresAt)
+}
+
+func TestCreateFileWatchTTLInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"--ttl=-1h"}, "--ttl must be at least 1s, got -1h0m0s"},
+		{[]string{"--ttl=500ms"}, "--ttl must be at least 1s, got 500ms"},
+		{[]string{"--ttl=1h", "--annotation", "tilt.dev/expires-at=2030-01-01T00:00:00Z"},
+			"--annotation tilt.dev/expires-at=2030-01-01T00:00:00Z conflicts with --ttl 1h0m0s"},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: bytes.NewBuffer(nil)})
+			c := cmd.register()
+			err := c.Flags().Parse(append(append([]string{}, tc.args...), "--dry-run=client", "--allow-missing", "my-watch", "src"))
+			require.NoError(t, err)
+
+			ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+			err = cmd.run(ctx, c.Flags().Args())
+			require.Error(t, err)
+			assert.Equal(t, tc.err, err.Error())
+			assert.Equal(t, exitCodeValidation, exitCode(err))
+		})
+	}
+}
+
+func TestCreateFileWatchDescription(t *testing.T) {
+	f := newServerFixture(t)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--description", "Regenerates the API clients", "--annotation", "owner=jane", "--allow-missing", "protos", "proto"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "protos"}, &fw)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"owner":                        "jane",
+		v1alpha1.AnnotationDescription: "Regenerates the API clients",
+	}, fw.Annotations)
+}
+
+func TestCreateFileWatchDescriptionConflict(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--description", "new", "--annotation", "tilt.dev/description=old", "--dry-run=client", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	require.Error(t, err)
+	assert.Equal(t, `--annotation tilt.dev/description=old conflicts with --description "new"`, err.Error())
+	assert.Equal(t, exitCodeValidation, exitCode(err))
+}
+
+func TestCreateFileWatchTrigger(t *testing.T) {
+	f := newServerFixture(t)
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: errOut})
+	var triggered []string
+	cmd.triggerResource = func(resource string) error {
+		triggered = append(triggered, resource)
+		return nil
+	}
+	c := cmd.register()
+	err := c.Flags().Parse([]string{
+		"--annotation", "tilt.dev/resource=web",
+		"--trigger",
+		"--allow-missing",
+		"my-watch", "src",
+	})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web"}, triggered)
+	assert.Equal(t, "Triggered update for resource: \"web\"\n", errOut.String())
+}
+
+func TestCreateFileWatchTriggerWithoutResource(t *testing.T) {
+	f := newServerFixture(t)
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: errOut})
+	cmd.triggerResource = func(resource string) error {
+		t.Fatalf("unexpected trigger of %q", resource)
+		return nil
+	}
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--trigger", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Contains(t, errOut.String(),
+		`Warning: not triggering FileWatch "my-watch", because it isn't associated with a resource`)
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+}
+
+func TestCreateFileWatchTriggerFails(t *testing.T) {
+	f := newServerFixture(t)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: bytes.NewBuffer(nil)})
+	cmd.triggerResource = func(resource string) error {
+		return fmt.Errorf("no resource found with name %q", resource)
+	}
+	c := cmd.register()
+	err := c.Flags().Parse([]string{
+		"--annotation", "tilt.dev/resource=web",
+		"--trigger",
+		"--allow-missing",
+		"my-watch", "src",
+	})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `triggering resource "web": no resource found with name "web"`)
+}
+
+func TestCreateFileWatchTriggerDryRun(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--trigger", "--dry-run=client", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--trigger can't be used with --dry-run")
+}
+
+func TestCreateFileWatchTriggerResource(t *testing.T) {
+	f := newServerFixture(t)
+	f.createUIResource("web",
+		v1alpha1.UIResourceTargetSpec{ID: "image:web-image", Type: v1alpha1.UIResourceTargetTypeImage},
+		v1alpha1.UIResourceTargetSpec{ID: "k8s:web", Type: v1alpha1.UIResourceTargetTypeKubernetes})
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--trigger-resource=web", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+	assert.Equal(t, "web", fw.Annotations[v1alpha1.AnnotationManifest])
+	assert.Equal(t, "k8s:web", fw.Annotations[v1alpha1.AnnotationTargetID])
+}
+
+func TestCreateFileWatchTriggerResourceInvalid(t *testing.T) {
+	f := newServerFixture(t)
+	f.createUIResource("(Tiltfile)")
+	f.createUIResource("web", v1alpha1.UIResourceTargetSpec{ID: "local:web", Type: v1alpha1.UIResourceTargetTypeLocal})
+
+	for _, tc := range []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--trigger-resource=api"}, "--trigger-resource api: no such resource in the tilt session"},
+		{[]string{"--trigger-resource=(Tiltfile)"},
+			"--trigger-resource (Tiltfile): the resource doesn't build or run anything, so file changes can't trigger it"},
+		{[]string{"--trigger-resource=web", "--annotation=tilt.dev/target-id=local:api"},
+			"--annotation tilt.dev/target-id=local:api conflicts with --trigger-resource web"},
+		{[]string{"--trigger-resource=web", "--dry-run=client"},
+			"--trigger-resource needs the running tilt session, so it can't be used with --dry-run=client"},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+			c := cmd.register()
+			err := c.Flags().Parse(append(append([]string{}, tc.args...), "--allow-missing", "my-watch", "src"))
+			require.NoError(t, err)
+
+			err = cmd.run(f.ctx, c.Flags().Args())
+			assert.EqualError(t, err, tc.expected)
+		})
+	}
+
+	var fws v1alpha1.FileWatchList
+	err := f.client.List(f.ctx, &fws)
+	require.NoError(t, err)
+	assert.Empty(t, fws.Items)
+}
+
+func TestCreateFileWatchInferPathsFrom(t *testing.T) {
+	f := newServerFixture(t)
+	f.createUIResource("web", v1alpha1.UIResourceTargetSpec{ID: "k8s:web", Type: v1alpha1.UIResourceTargetTypeKubernetes})
+	web := f.JoinPath("web")
+	lib := f.JoinPath("lib")
+	api := f.JoinPath("api")
+	f.MkdirAll("web")
+	f.MkdirAll("lib")
+	f.MkdirAll("api")
+	f.createResourceFileWatch("image:web", "web", web, lib)
+	f.createResourceFileWatch("configs:web", "web", lib)
+	f.createResourceFileWatch("image:api", "api", api)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--infer-paths-from=web", "web-sources"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "web-sources"}, &fw)
+	require.NoError(t, err)
+	assert.Equal(t, []string{canonicalPath(lib), canonicalPath(web)}, fw.Spec.WatchedPaths)
+}
+
+func TestCreateFileWatchInferPathsFromInvalid(t *testing.T) {
+	f := newServerFixture(t)
+	f.createUIResource("(Tiltfile)")
+
+	for _, tc := range []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--infer-paths-from=api"}, "--infer-paths-from api: no such resource in the tilt session"},
+		{[]string{"--infer-paths-from=(Tiltfile)"},
+			"--infer-paths-from (Tiltfile): the resource doesn't watch any paths, so there are none to infer"},
+		{[]string{"--infer-paths-from=(Tiltfile)", "--dry-run=client"},
+			"--infer-paths-from needs the running tilt session, so it can't be used with --dry-run=client"},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+			c := cmd.register()
+			err := c.Flags().Parse(append(append([]string{}, tc.args...), "my-watch"))
+			require.NoError(t, err)
+
+			err = cmd.run(f.ctx, c.Flags().Args())
+			assert.EqualError(t, err, tc.expected)
+		})
+	}
+}
+
+func TestCreateFileWatchMergeIgnoresFrom(t *testing.T) {
+	f := newServerFixture(t)
+	cwd, _ := os.Getwd()
+	err := f.client.Create(f.ctx, &v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{Name: "other-watch"},
+		Spec: v1alpha1.FileWatchSpec{
+			WatchedPaths: []string{cwd},
+			Ignores: []v1alpha1.IgnoreDef{
+				{BasePath: cwd, Patterns: []string{"node_modules"}},
+				{BasePath: "web", Patterns: []string{"dist"}},
+				{BasePath: "/elsewhere", Patterns: []string{"tmp"}},
+				{BasePath: cwd, Patterns: []string{"*.log"}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err = c.Flags().Parse([]string{"--merge-ignores-from=other-watch", "--ignore=build", "--allow-missing", "my-watch", "src", "web"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+	assert.Equal(t, []v1alpha1.IgnoreDef{
+		{BasePath: cwd, Patterns: []string{"node_modules", "*.log", "build"}},
+		{BasePath: filepath.Join(cwd, "web"), Patterns: []string{"dist"}},
+	}, fw.Spec.Ignores)
+
+	// The other FileWatch is unchanged.
+	var other v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "other-watch"}, &other)
+	require.NoError(t, err)
+	assert.Len(t, other.Spec.Ignores, 4)
+}
+
+func TestCreateFileWatchMergeIgnoresFromInvalid(t *testing.T) {
+	f := newServerFixture(t)
+
+	for _, tc := range []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--merge-ignores-from=other-watch"}, "--merge-ignores-from other-watch: no FileWatch named other-watch in the tilt session"},
+		{[]string{"--merge-ignores-from=other-watch", "--dry-run=client"},
+			"--merge-ignores-from needs the running tilt session, so it can't be used with --dry-run=client"},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+			c := cmd.register()
+			err := c.Flags().Parse(append(append([]string{}, tc.args...), "--allow-missing", "my-watch", "src"))
+			require.NoError(t, err)
+
+			err = cmd.run(f.ctx, c.Flags().Args())
+			assert.EqualError(t, err, tc.expected)
+		})
+	}
+}
+
+func (f *serverFixture) createResourceFileWatch(name, resource string, paths ...string) {
+	err := f.client.Create(f.ctx, &v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{v1alpha1.AnnotationManifest: resource},
+		},
+		Spec: v1alpha1.FileWatchSpec{WatchedPaths: paths},
+	})
+	require.NoError(f.T(), err)
+}
+
+func (f *serverFixture) createUIResource(name string, specs ...v1alpha1.UIResourceTargetSpec) {
+	err := f.client.Create(f.ctx, &v1alpha1.UIResource{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     v1alpha1.UIResourceStatus{Specs: specs},
+	})
+	require.NoError(f.T(), err)
+}
+
+func TestCreateFileWatchAnalytics(t *testing.T) {
+	f := newServerFixture(t)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--allow-missing", "--ignore", "*.tmp", "--ignore", "build", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, []analytics.CountEvent{
+		{Name: "cmd.create-filewatch", Tags: map[string]string{"paths": "1", "ignores": "2-5"}, N: 1},
+	}, f.analytics.Counts)
+}
+
+func TestCountBucket(t *testing.T) {
+	for n, expected := range map[int]string{
+		0:   "0",
+		1:   "1",
+		2:   "2-5",
+		5:   "2-5",
+		6:   "6+",
+		100: "6+",
+	} {
+		assert.Equal(t, expected, countBucket(n), "countBucket(%d)", n)
+	}
+}
+
+func TestCreateFileWatchNoAnalytics(t *testing.T) {
+	f := newServerFixture(t)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--no-analytics", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Empty(t, f.analytics.Counts)
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+}
+
+func TestCreateFileWatchVerbose(t *testing.T) {
+	f := newServerFixture(t)
+	f.Chdir()
+	f.MkdirAll("src")
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: errOut})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--ignore", "node_modules", "my-watch", "src"})
+	require.NoError(t, err)
+
+	verbose = true
+	t.Cleanup(func() { verbose = false })
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	cwd, _ := filepath.EvalSymlinks(f.Path())
+	assert.Regexp(t, `^Tilt API server: https://\S+:\d+\n`, errOut.String())
+	assert.Contains(t, errOut.String(), "Bearer token: <redacted>\n")
+	assert.Contains(t, errOut.String(), fmt.Sprintf("FileWatch my-watch watches:\n  %s\n", filepath.Join(cwd, "src")))
+	assert.Contains(t, errOut.String(), fmt.Sprintf("Ignoring:\n  %s: node_modules\n", cwd))
+	assert.NotContains(t, errOut.String(), "corgi-charge")
+}
+
+func TestCreateFileWatchNotVerbose(t *testing.T) {
+	f := newServerFixture(t)
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: errOut})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Empty(t, errOut.String())
+}
+
+func TestCreateFileWatchDisabled(t *testing.T) {
+	f := newServerFixture(t)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--disabled", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+	assert.Equal(t, &v1alpha1.DisableSource{
+		ConfigMap: &v1alpha1.ConfigMapDisableSource{Name: "filewatch-my-watch-disable", Key: "isDisabled"},
+	}, fw.Spec.DisableSource)
+	assert.Equal(t, "true", f.disableConfigMapValue("filewatch-my-watch-disable"))
+
+	enable := newEnableCmd()
+	ec := enable.register()
+	require.NoError(t, ec.Flags().Parse([]string{"my-watch"}))
+	require.NoError(t, enable.run(f.ctx, ec.Flags().Args()))
+	assert.Equal(t, "false", f.disableConfigMapValue("filewatch-my-watch-disable"))
+
+	disable := newDisableCmd()
+	dc := disable.register()
+	require.NoError(t, dc.Flags().Parse([]string{"my-watch"}))
+	require.NoError(t, disable.run(f.ctx, dc.Flags().Args()))
+	assert.Equal(t, "true", f.disableConfigMapValue("filewatch-my-watch-disable"))
+}
+
+func TestCreateFileWatchDisabledAlreadyExists(t *testing.T) {
+	f := newServerFixture(t)
+
+	// An enabled FileWatch that was created with --disabled earlier.
+	source := disableSourceForFileWatch("my-watch")
+	require.NoError(t, f.client.Create(f.ctx, &v1alpha1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: source.ConfigMap.Name},
+		Data:       map[string]string{"isDisabled": "false"},
+	}))
+	require.NoError(t, f.client.Create(f.ctx, &v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-watch"},
+		Spec:       v1alpha1.FileWatchSpec{WatchedPaths: []string{f.Path()}, DisableSource: source},
+	}))
+	f.createFileWatch("other-watch")
+
+	for _, name := range []string{"my-watch", "other-watch"} {
+		cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+		c := cmd.register()
+		err := c.Flags().Parse([]string{"--disabled", "--allow-missing", name, "src"})
+		require.NoError(t, err)
+
+		err = cmd.run(f.ctx, c.Flags().Args())
+		require.Error(t, err)
+		assert.True(t, apierrors.IsAlreadyExists(err), "expected AlreadyExists, got: %v", err)
+	}
+
+	// The failed creates leave the existing FileWatch enabled, and don't
+	// leave a ConfigMap behind.
+	assert.Equal(t, "false", f.disableConfigMapValue(source.ConfigMap.Name))
+	var cm v1alpha1.ConfigMap
+	err := f.client.Get(f.ctx, types.NamespacedName{Name: "filewatch-other-watch-disable"}, &cm)
+	assert.True(t, apierrors.IsNotFound(err), "expected NotFound, got: %v", err)
+}
+
+func TestDisableResourceFileWatchByName(t *testing.T) {
+	f := newServerFixture(t)
+
+	// A FileWatch from the Tiltfile, which shares its resource's disable ConfigMap.
+	source := &v1alpha1.DisableSource{
+		ConfigMap: &v1alpha1.ConfigMapDisableSource{Name: "web-disable", Key: "isDisabled"},
+	}
+	require.NoError(t, f.client.Create(f.ctx, &v1alpha1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-disable"},
+		Data:       map[string]string{"isDisabled": "false"},
+	}))
+	require.NoError(t, f.client.Create(f.ctx, &v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{Name: "image:web"},
+		Spec:       v1alpha1.FileWatchSpec{WatchedPaths: []string{f.Path()}, DisableSource: source},
+	}))
+
+	disable := newDisableCmd()
+	dc := disable.register()
+	require.NoError(t, dc.Flags().Parse([]string{"image:web"}))
+	err := disable.run(f.ctx, dc.Flags().Args())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `no such resource "image:web"`)
+	assert.Equal(t, "false", f.disableConfigMapValue("web-disable"))
+}
+
+func TestCreateFileWatchDisabledDryRun(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+
+	out := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--disabled", "--dry-run=client", "-o", "yaml", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), `  disableSource:
+    configMap:
+      key: isDisabled
+      name: filewatch-my-watch-disable
+`)
+}
+
+func TestCreateFileWatchDisabledWait(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--disabled", "--wait", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--disabled can't be used with --wait or --trigger")
+}
+
+func TestCreateFileWatchOwner(t *testing.T) {
+	f := newServerFixture(t)
+	cm := &v1alpha1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "my-config"}}
+	require.NoError(t, f.client.Create(f.ctx, cm))
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--owner", "ConfigMap/my-config", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+	assert.Equal(t, []metav1.OwnerReference{{
+		APIVersion: "tilt.dev/v1alpha1",
+		Kind:       "ConfigMap",
+		Name:       "my-config",
+		UID:        cm.UID,
+	}}, fw.OwnerReferences)
+}
+
+func TestCreateFileWatchOwnerNotFound(t *testing.T) {
+	f := newServerFixture(t)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--owner", "configmaps/my-config", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	assert.EqualError(t, err, "--owner configmaps/my-config: not found in the tilt session")
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	assert.True(t, apierrors.IsNotFound(err), "expected no FileWatch, got: %v", err)
+}
+
+func TestCreateFileWatchInheritIgnores(t *testing.T) {
+	f := newServerFixture(t)
+	f.Chdir()
+	configs := &v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "configs-tiltfile",
+			Annotations: map[string]string{v1alpha1.AnnotationTargetID: "configs:(Tiltfile)"},
+		},
+		Spec: v1alpha1.FileWatchSpec{
+			WatchedPaths: []string{filepath.Join(f.Path(), "Tiltfile")},
+			Ignores: []v1alpha1.IgnoreDef{
+				{BasePath: f.Path(), Patterns: []string{"*.pyc"}},
+				{BasePath: filepath.Join(string(filepath.Separator), "elsewhere"), Patterns: []string{"tmp"}},
+			},
+		},
+	}
+	require.NoError(t, f.client.Create(f.ctx, configs))
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--inherit-ignores", "--ignore=*.log", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+	assert.Equal(t, []v1alpha1.IgnoreDef{
+		{BasePath: f.Path(), Patterns: []string{"*.pyc", "*.log"}},
+	}, fw.Spec.Ignores)
+}
+
+func TestCreateFileWatchInheritIgnoresUnavailable(t *testing.T) {
+	client := newFileWatchListClient()
+	client.PrependReactor("list", "filewatches", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("connection reset")
+	})
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: errOut})
+	cmd.helper.dynamicClient = client
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--inherit-ignores", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, "Warning: couldn't read the ignores of the tilt session, so none are inherited: connection reset\n", errOut.String())
+	assert.Empty(t, cmd.inheritedIgnores)
+}
+
+func TestCreateFileWatchConfirmHome(t *testing.T) {
+	for _, tc := range []struct {
+		answer  string
+		created bool
+	}{
+		{"y\n", true},
+		{"YES\n", true},
+		{"n\n", false},
+		{"", false},
+	} {
+		t.Run(tc.answer, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			client := dynamicfake.NewSimpleDynamicClient(v1alpha1.NewScheme())
+
+			errOut := bytes.NewBuffer(nil)
+			cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{
+				In: strings.NewReader(tc.answer), Out: bytes.NewBuffer(nil), ErrOut: errOut})
+			cmd.helper.dynamicClient = client
+			cmd.isTerminal = func(in io.Reader) bool { return true }
+			c := cmd.register()
+			err := c.Flags().Parse([]string{"my-watch", "~"})
+			require.NoError(t, err)
+
+			ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+			err = cmd.run(ctx, c.Flags().Args())
+			assert.Contains(t, errOut.String(), "Watching "+canonicalPath(home)+" can slow down the whole machine. Watch it anyway? [y/N] ")
+			if tc.created {
+				require.NoError(t, err)
+				assert.Len(t, client.Actions(), 1)
+			} else {
+				assert.EqualError(t, err, "not watching "+canonicalPath(home)+", since it wasn't confirmed (pass --yes to skip the confirmation)")
+				assert.Empty(t, client.Actions())
+			}
+		})
+	}
+}
+
+func TestCreateFileWatchConfirmYes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	client := dynamicfake.NewSimpleDynamicClient(v1alpha1.NewScheme())
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{
+		In: strings.NewReader(""), Out: bytes.NewBuffer(nil), ErrOut: errOut})
+	cmd.helper.dynamicClient = client
+	cmd.isTerminal = func(in io.Reader) bool { return true }
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--yes", "my-watch", "~"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.NotContains(t, errOut.String(), "Watch it anyway?")
+	assert.Len(t, client.Actions(), 1)
+}
+
+func TestCreateFileWatchConfirmNotTerminal(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	client := dynamicfake.NewSimpleDynamicClient(v1alpha1.NewScheme())
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{
+		In: strings.NewReader(""), Out: bytes.NewBuffer(nil), ErrOut: errOut})
+	cmd.helper.dynamicClient = client
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"my-watch", "~"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.NotContains(t, errOut.String(), "Watch it anyway?")
+	assert.Len(t, client.Actions(), 1)
+}
+
+func TestIsDangerousWatchPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	assert.True(t, isDangerousWatchPath(string(filepath.Separator)))
+	assert.True(t, isDangerousWatchPath(home))
+	assert.False(t, isDangerousWatchPath(filepath.Join(home, "src")))
+}
+
+func TestCreateFileWatchMalformedOwner(t *testing.T) {
+	for _, tc := range []struct {
+		owner    string
+		expected string
+	}{
+		{"my-config", `invalid --owner "my-config": must be KIND/NAME`},
+		{"configmap/", `invalid --owner "configmap/": must be KIND/NAME`},
+		{"pod/my-pod", `invalid --owner "pod/my-pod": unknown kind "pod"`},
+	} {
+		t.Run(tc.owner, func(t *testing.T) {
+			cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+			c := cmd.register()
+			err := c.Flags().Parse([]string{"--owner", tc.owner, "--dry-run=client", "--allow-missing", "my-watch", "src"})
+			require.NoError(t, err)
+
+			ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+			err = cmd.run(ctx, c.Flags().Args())
+			assert.EqualError(t, err, tc.expected)
+		})
+	}
+}
+
+func (f *serverFixture) disableConfigMapValue(name string) string {
+	var cm v1alpha1.ConfigMap
+	err := f.client.Get(f.ctx, types.NamespacedName{Name: name}, &cm)
+	require.NoError(f.T(), err)
+	return cm.Data["isDisabled"]
+}
+
+func TestCreateFileWatchMalformedLabels(t *testing.T) {
+	for _, tc := range []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--label", "team"}, `invalid --label "team": must be KEY=VALUE`},
+		{[]string{"--label", "=web"}, `invalid --label "=web": must be KEY=VALUE`},
+		{[]string{"--annotation", "owner"}, `invalid --annotation "owner": must be KEY=VALUE`},
+		{[]string{"--label", "bad key=web"}, `invalid --label key "bad key"`},
+		{[]string{"--label", "team=not a label value"}, `invalid --label value "not a label value"`},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			// No server fixture: malformed flags should fail before connecting to the tilt session.
+			ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+
+			cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+			c := cmd.register()
+			err := c.Flags().Parse(append(tc.args, "--allow-missing", "my-watch", "src"))
+			require.NoError(t, err)
+
+			err = cmd.run(ctx, c.Flags().Args())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestCreateFileWatchSync(t *testing.T) {
+	f := newServerFixture(t)
+	f.Chdir()
+	f.MkdirAll("src")
+	f.MkdirAll("static")
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--sync", "src:/app/src", "--sync", "static:/app/static/", "--sync-container", "web/app", "my-watch"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	cwd, _ := filepath.EvalSymlinks(f.Path())
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(cwd, "src"), filepath.Join(cwd, "static")}, fw.Spec.WatchedPaths)
+
+	var lu v1alpha1.LiveUpdate
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &lu)
+	require.NoError(t, err)
+	assert.Equal(t, "web", lu.Annotations[v1alpha1.AnnotationManifest])
+	assert.Equal(t, v1alpha1.LiveUpdateSpec{
+		BasePath: cwd,
+		Selector: v1alpha1.LiveUpdateSelector{
+			Kubernetes: &v1alpha1.LiveUpdateKubernetesSelector{DiscoveryName: "web", ApplyName: "web", ContainerName: "app"},
+		},
+		Sources: []v1alpha1.LiveUpdateSource{{FileWatch: "my-watch"}},
+		Syncs: []v1alpha1.LiveUpdateSync{
+			{LocalPath: filepath.Join(cwd, "src"), ContainerPath: "/app/src"},
+			{LocalPath: filepath.Join(cwd, "static"), ContainerPath: "/app/static"},
+		},
+	}, lu.Spec)
+
+	// With --update, the LiveUpdate is updated, too.
+	cmd = newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c = cmd.register()
+	err = c.Flags().Parse([]string{"--update", "--sync", "src:/srv", "--sync-service", "api", "my-watch"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &lu)
+	require.NoError(t, err)
+	assert.Equal(t, v1alpha1.LiveUpdateSelector{
+		DockerCompose: &v1alpha1.LiveUpdateDockerComposeSelector{Service: "api"},
+	}, lu.Spec.Selector)
+	assert.Equal(t, []v1alpha1.LiveUpdateSync{{LocalPath: filepath.Join(cwd, "src"), ContainerPath: "/srv"}}, lu.Spec.Syncs)
+}
+
+func TestCreateFileWatchSyncInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--sync", "src", "--sync-service", "api"}, `--sync must be LOCAL:REMOTE, e.g., ./src:/app/src, got "src"`},
+		{[]string{"--sync", "src:", "--sync-service", "api"}, `--sync must be LOCAL:REMOTE`},
+		{[]string{"--sync", ":/app", "--sync-service", "api"}, `--sync must be LOCAL:REMOTE`},
+		{[]string{"--sync", "src:app", "--sync-service", "api"}, `--sync "src:app": the container path app must be absolute`},
+		{[]string{"--sync", "src/*.go:/app", "--sync-service", "api"}, `--sync "src/*.go:/app": the local path can't be a glob`},
+		{[]string{"--sync", "src:/app"}, "--sync needs --sync-service or --sync-container"},
+		{[]string{"--sync", "src:/app", "--sync-container", "web"}, `--sync-container must be RESOURCE/CONTAINER, got "web"`},
+		{[]string{"--sync", "src:/app", "--sync-container", "web/"}, `--sync-container must be RESOURCE/CONTAINER, got "web/"`},
+		{[]string{"--sync-service", "api"}, "--sync-service can only be used with --sync"},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			// No server fixture: malformed flags should fail before connecting to the tilt session.
+			ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+
+			cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+			c := cmd.register()
+			err := c.Flags().Parse(append(tc.args, "--allow-missing", "my-watch", "src"))
+			require.NoError(t, err)
+
+			err = cmd.run(ctx, c.Flags().Args())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestParseSyncWindowsPath(t *testing.T) {
+	local, remote, err := parseSync(`C:\src:/app/src`)
+	require.NoError(t, err)
+	assert.Equal(t, `C:\src`, local)
+	assert.Equal(t, "/app/src", remote)
+}
+
+const fileWatchManifest = `
+apiVersion: tilt.dev/v1alpha1
+kind: FileWatch
+metadata:
+  name: src
+spec:
+  watchedPaths: [src]
+  ignores:
+  - patterns: ["*.tmp"]
+---
+apiVersion: tilt.dev/v1alpha1
+kind: FileWatch
+metadata:
+  name: web
+  labels:
+    team: web
+spec:
+  watchedPaths: [web]
+`
+
+func TestCreateFileWatchFromFile(t *testing.T) {
+	f := newServerFixture(t)
+	f.Chdir()
+	f.WriteFile("watches.yaml", fileWatchManifest)
+
+	out := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"-f", "watches.yaml", "--allow-missing", "--label", "env=dev"})
+	require.NoError(t, err)
+	require.NoError(t, c.Args(c, c.Flags().Args()))
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, "filewatch.tilt.dev/src created\nfilewatch.tilt.dev/web created\n2 created.\n", out.String())
+
+	cwd, _ := os.Getwd()
+	var src v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "src"}, &src)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(cwd, "src")}, src.Spec.WatchedPaths)
+	assert.Equal(t, cwd, src.Spec.Ignores[0].BasePath)
+	assert.Equal(t, map[string]string{"env": "dev"}, src.Labels)
+
+	var web v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "web"}, &web)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(cwd, "web")}, web.Spec.WatchedPaths)
+	assert.Equal(t, map[string]string{"team": "web", "env": "dev"}, web.Labels)
+}
+
+func TestCreateFileWatchFromFilePartialFailure(t *testing.T) {
+	f := newServerFixture(t)
+	f.Chdir()
+	f.WriteFile("watches.yaml", fileWatchManifest)
+	f.createFileWatch("src")
+
+	out := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"-f", "watches.yaml", "--allow-missing"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create 1 of 2 FileWatches:\n  src: ")
+	assert.Contains(t, err.Error(), "already exists")
+	assert.Equal(t, "filewatch.tilt.dev/web created\n1 created, 1 error.\n", out.String())
+
+	var web v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "web"}, &web)
+	require.NoError(t, err)
+}
+
+func TestCreateFileWatchFromFileUpdate(t *testing.T) {
+	f := newServerFixture(t)
+	f.createFileWatch("src")
+
+	out := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out, In: strings.NewReader(fileWatchManifest)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"-f", "-", "--allow-missing", "--update"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, "filewatch.tilt.dev/src updated\nfilewatch.tilt.dev/web created\n1 updated, 1 created.\n", out.String())
+
+	cwd, _ := os.Getwd()
+	var src v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "src"}, &src)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(cwd, "src")}, src.Spec.WatchedPaths)
+}
+
+func TestCreateFileWatchPrintResolved(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.TouchFiles([]string{"src/main.go", "src/util.go", "web/index.html"})
+	t.Setenv("WEB_DIR", "web")
+
+	out := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out, ErrOut: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{
+		"--print-resolved",
+		"--ignore=*.tmp", "--ignore=!keep.tmp",
+		"--ignore-for=web:dist",
+		"my-watch", "src/*.go", "$WEB_DIR",
+	})
+	require.NoError(t, err)
+
+	// Without a tilt session, any request to one would fail.
+	ctx, ma, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Empty(t, ma.Counts)
+
+	root := canonicalPath(f.Path())
+	assert.Equal(t, strings.ReplaceAll(filepath.FromSlash(`FileWatch my-watch
+watched paths:
+  ROOT/src/main.go
+  ROOT/src/util.go
+  ROOT/web
+ignores:
+  ROOT:
+    *.tmp
+    !keep.tmp
+  ROOT/web:
+    dist
+`), "ROOT", root), out.String())
+}
+
+func TestCreateFileWatchPreviewMatches(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.TouchFiles([]string{
+		"src/main.go", "src/main.tmp", "src/keep.tmp",
+		"web/index.html", "web/node_modules/react/index.js", "web/dist/app.js",
+	})
+
+	out := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out, ErrOut: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{
+		"--preview-matches",
+		"--ignore=*/*.tmp", "--ignore=!src/keep.tmp", "--ignore=web/node_modules",
+		"--ignore-for=web:dist",
+		"my-watch", "web", "src",
+	})
+	require.NoError(t, err)
+
+	// Without a tilt session, any request to one would fail.
+	ctx, ma, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Empty(t, ma.Counts)
+
+	root := canonicalPath(f.Path())
+	assert.Equal(t, strings.ReplaceAll(filepath.FromSlash(`ROOT/src/keep.tmp
+ROOT/src/main.go
+ROOT/web/index.html
+`), "ROOT", root), out.String())
+}
+
+func TestCreateFileWatchPreviewMatchesLimit(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.TouchFiles([]string{"src/a.go", "src/b.go", "src/c.go"})
+
+	out := bytes.NewBuffer(nil)
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out, ErrOut: errOut})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--preview-matches", "--preview-limit=2", "my-watch", "src"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	root := canonicalPath(f.Path())
+	assert.Equal(t, []string{filepath.Join(root, "src", "a.go"), filepath.Join(root, "src", "b.go")},
+		strings.Fields(out.String()))
+	assert.Equal(t, "Stopped after 2 files (use --preview-limit to see more)\n", errOut.String())
+}
+
+func TestMatchingFilesNonRecursive(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.TouchFiles([]string{"src/main.go", "src/lib/util.go"})
+
+	matches, truncated := matchingFiles(v1alpha1.FileWatchSpec{
+		WatchedPaths: []string{f.JoinPath("src"), f.JoinPath("src", "main.go")},
+		NonRecursive: true,
+	}, 10)
+	assert.False(t, truncated)
+	assert.Equal(t, []string{f.JoinPath("src", "main.go")}, matches)
+}
+
+func TestCreateFileWatchPrintResolvedFromFile(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.WriteFile("watches.yaml", fileWatchManifest)
+
+	out := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out, ErrOut: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--print-resolved", "--allow-missing", "-f", "watches.yaml"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	root := canonicalPath(f.Path())
+	assert.Equal(t, fmt.Sprintf(`FileWatch src
+watched paths:
+  %[1]s
+ignores:
+  %[2]s:
+    *.tmp
+
+FileWatch web
+watched paths:
+  %[3]s
+ignores: none
+`, filepath.Join(root, "src"), root, filepath.Join(root, "web")), out.String())
+}
+
+func TestCreateFileWatchPrintResolvedRelativeToTiltfile(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--print-resolved", "--relative-to=tiltfile", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	assert.EqualError(t, err, "--print-resolved can't be used with --relative-to=tiltfile, which needs the running tilt session")
+}
+
+func TestCreateFileWatchFromFileSummary(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.WriteFile("watches.yaml", fileWatchManifest)
+	f.WriteFile("watch.yaml", `
+apiVersion: tilt.dev/v1alpha1
+kind: FileWatch
+metadata:
+  name: src
+spec:
+  watchedPaths: [src]
+`)
+
+	for _, tc := range []struct {
+		args           []string
+		expectedOut    string
+		expectedErrOut string
+	}{
+		{[]string{"-f", "watches.yaml", "--no-summary"},
+			"filewatch.tilt.dev/src created (dry run)\nfilewatch.tilt.dev/web created (dry run)\n", ""},
+		{[]string{"-f", "watches.yaml", "-o", "name"},
+			"filewatch.tilt.dev/src\nfilewatch.tilt.dev/web\n", "2 created.\n"},
+		{[]string{"-f", "watches.yaml", "--quiet"}, "src\nweb\n", "2 created.\n"},
+		{[]string{"-f", "watch.yaml"}, "filewatch.tilt.dev/src created (dry run)\n", ""},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			out := bytes.NewBuffer(nil)
+			errOut := bytes.NewBuffer(nil)
+			cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out, ErrOut: errOut})
+			c := cmd.register()
+			err := c.Flags().Parse(append(append([]string{}, tc.args...), "--allow-missing", "--dry-run=client"))
+			require.NoError(t, err)
+
+			ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+			err = cmd.run(ctx, c.Flags().Args())
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedOut, out.String())
+			assert.Equal(t, tc.expectedErrOut, errOut.String())
+		})
+	}
+}
+
+func TestCreateResultsSummary(t *testing.T) {
+	results := &createResults{}
+	for _, op := range []string{"created", "unchanged", "created (dry run)", "created"} {
+		results.add(op)
+	}
+	results.addError()
+	assert.Equal(t, "3 created, 1 unchanged, 1 error.", results.summary())
+
+	results.addError()
+	assert.Equal(t, "3 created, 1 unchanged, 2 errors.", results.summary())
+
+	results = &createResults{}
+	results.addError()
+	assert.Equal(t, "1 error.", results.summary())
+}
+
+func TestCreateFileWatchFromFileWrongKind(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.WriteFile("watches.yaml", `
+apiVersion: tilt.dev/v1alpha1
+kind: Cmd
+metadata:
+  name: echo
+spec:
+  args: [echo, hi]
+`)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"-f", "watches.yaml"})
+	require.NoError(t, err)
+
+	_, err = cmd.fileObjects()
+	assert.EqualError(t, err, "watches.yaml: expected only FileWatch objects, got Cmd")
+}
+
+func TestCreateFileWatchFromFileRejectsArgs(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"-f", "watches.yaml", "my-watch", "src"})
+	require.NoError(t, err)
+	assert.Error(t, c.Args(c, c.Flags().Args()))
+}
+
+func TestCollapsePaths(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		paths    []string
+		expected []string
+		removed  []collapsedPath
+	}{
+		{"siblings", []string{"/src", "/web"}, []string{"/src", "/web"}, nil},
+		{"sibling with common prefix", []string{"/src", "/src2"}, []string{"/src", "/src2"}, nil},
+		{"nested", []string{"/src", "/src/sub"}, []string{"/src"},
+			[]collapsedPath{{path: "/src/sub", ancestor: "/src"}}},
+		{"nested first", []string{"/src/sub", "/src"}, []string{"/src"},
+			[]collapsedPath{{path: "/src/sub", ancestor: "/src"}}},
+		{"deeply nested", []string{"/src/a/b", "/src/a", "/src"}, []string{"/src"},
+			[]collapsedPath{{path: "/src/a/b", ancestor: "/src"}, {path: "/src/a", ancestor: "/src"}}},
+		{"duplicate", []string{"/src", "/src"}, []string{"/src"},
+			[]collapsedPath{{path: "/src", ancestor: "/src"}}},
+		{"nested and sibling", []string{"/src", "/web", "/src/sub"}, []string{"/src", "/web"},
+			[]collapsedPath{{path: "/src/sub", ancestor: "/src"}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, removed := collapsePaths(tc.paths)
+			assert.Equal(t, tc.expected, actual)
+			if tc.removed == nil {
+				tc.removed = []collapsedPath{}
+			}
+			assert.Equal(t, tc.removed, removed)
+		})
+	}
+}
+
+func TestCreateFileWatchCollapsesNestedPaths(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: errOut})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--allow-missing", "my-watch", "src", "src/sub", "web"})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+
+	cwd, _ := os.Getwd()
+	assert.Equal(t, []string{filepath.Join(cwd, "src"), filepath.Join(cwd, "web")}, fw.Spec.WatchedPaths)
+	assert.Equal(t, fmt.Sprintf("Not watching %s separately, because it's inside %s\n",
+		filepath.Join(cwd, "src", "sub"), filepath.Join(cwd, "src")), errOut.String())
+}
+
+func TestCreateFileWatchNoCollapse(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: errOut})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--allow-missing", "--no-collapse", "my-watch", "src", "src/sub"})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+
+	cwd, _ := os.Getwd()
+	assert.Equal(t, []string{filepath.Join(cwd, "src"), filepath.Join(cwd, "src", "sub")}, fw.Spec.WatchedPaths)
+	assert.Empty(t, errOut.String())
+}
+
+func TestCreateFileWatchSortPaths(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.TouchFiles([]string{"web/b.go", "src/a.go", "src/c.go"})
+	cwd, _ := filepath.EvalSymlinks(f.Path())
+
+	for _, tc := range []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{"insertion order", []string{"my-watch", "web", "src/*.go"}, []string{
+			filepath.Join(cwd, "web"),
+			filepath.Join(cwd, "src", "a.go"),
+			filepath.Join(cwd, "src", "c.go"),
+		}},
+		{"sorted", []string{"--sort-paths", "my-watch", "web", "src/*.go"}, []string{
+			filepath.Join(cwd, "src", "a.go"),
+			filepath.Join(cwd, "src", "c.go"),
+			filepath.Join(cwd, "web"),
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: bytes.NewBuffer(nil)})
+			c := cmd.register()
+			err := c.Flags().Parse(tc.args)
+			require.NoError(t, err)
+
+			fw, err := cmd.object(c.Flags().Args())
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, fw.Spec.WatchedPaths)
+		})
+	}
+}
+
+func TestCreateFileWatchEscapingPath(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.TouchFiles([]string{"project/src/a.go", "other/b.go"})
+	f.Chdir()
+	require.NoError(t, os.Chdir(f.JoinPath("project")))
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"my-watch", "src", "../other", "src/../../other/*.go"})
+	require.NoError(t, err)
+
+	cwd, _ := os.Getwd()
+	_, err = cmd.object(c.Flags().Args())
+	assert.EqualError(t, err, fmt.Sprintf("paths are outside of %s: ../other (%s), src/../../other/*.go (%s)\n"+
+		"(use --allow-escape to watch them anyway)",
+		cwd, filepath.Join(filepath.Dir(cwd), "other"), filepath.Join(filepath.Dir(cwd), "other", "*.go")))
+
+	// Paths that leave and come back are fine, and so are absolute ones.
+	_, err = cmd.paths([]string{"../project/src", f.JoinPath("other")})
+	require.NoError(t, err)
+}
+
+func TestCreateFileWatchAllowEscape(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.TouchFiles([]string{"project/src/a.go", "other/b.go"})
+	f.Chdir()
+	require.NoError(t, os.Chdir(f.JoinPath("project")))
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--allow-escape", "my-watch", "src", "../other"})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+
+	root, _ := filepath.EvalSymlinks(f.Path())
+	assert.Equal(t, []string{
+		filepath.Join(root, "project", "src"),
+		filepath.Join(root, "other"),
+	}, fw.Spec.WatchedPaths)
+}
+
+func TestCreateFileWatchGlob(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.TouchFiles([]string{"src/a.go", "src/b.txt", "src/sub/c.go", "web/d.go"})
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	paths, err := cmd.paths([]string{"src/*.txt", "src/**/*.go", f.JoinPath("web", "*.go")})
+	require.NoError(t, err)
+
+	cwd, _ := filepath.EvalSymlinks(f.Path())
+	assert.Equal(t, []string{
+		filepath.Join(cwd, "src", "b.txt"),
+		filepath.Join(cwd, "src", "a.go"),
+		filepath.Join(cwd, "src", "sub", "c.go"),
+		filepath.Join(cwd, "web", "d.go"),
+	}, paths)
+}
+
+func TestCreateFileWatchGlobMaxPaths(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.TouchFiles([]string{"src/a.go", "src/b.go", "src/sub/c.go", "src/sub/d.go"})
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	require.NoError(t, c.Flags().Parse([]string{"--max-paths=3"}))
+
+	_, err := cmd.paths([]string{"src/*.go", "src/**/*.go"})
+	assert.EqualError(t, err, `path pattern "src/**/*.go" matches 4 paths, more than the limit of 3`+"\n"+
+		`(watch a directory that contains them instead, make the pattern more specific, or pass a higher --max-paths)`)
+
+	require.NoError(t, c.Flags().Parse([]string{"--max-paths=4"}))
+	paths, err := cmd.paths([]string{"src/**/*.go"})
+	require.NoError(t, err)
+	assert.Len(t, paths, 4)
+
+	require.NoError(t, c.Flags().Parse([]string{"--max-paths=0"}))
+	paths, err = cmd.paths([]string{"src/**/*.go"})
+	require.NoError(t, err)
+	assert.Len(t, paths, 4)
+}
+
+func TestCreateFileWatchGlobNoMatch(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.TouchFiles([]string{"src/a.go"})
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	_, err := cmd.paths([]string{"src/*.js"})
+	assert.EqualError(t, err, `path pattern "src/*.js" did not match any files`)
+
+	_, err = cmd.paths([]string{"web/**/*.go"})
+	assert.EqualError(t, err, `path pattern "web/**/*.go" did not match any files`)
+}
+
+func TestCreateFileWatchFromSpec(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.MkdirAll("src")
+	f.MkdirAll("web")
+	f.WriteFile("spec.yaml", `
+watchedPaths: [src]
+ignores:
+- patterns: [src/vendor]
+debounceDuration: 1s
+`)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--from-spec", "spec.yaml", "my-watch", "web"})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+
+	cwd, _ := filepath.EvalSymlinks(f.Path())
+	assert.Equal(t, []string{
+		filepath.Join(cwd, "src"),
+		filepath.Join(cwd, "web"),
+	}, fw.Spec.WatchedPaths)
+	assert.Equal(t, []v1alpha1.IgnoreDef{
+		{BasePath: cwd, Patterns: []string{"src/vendor"}},
+	}, fw.Spec.Ignores)
+	assert.Equal(t, time.Second, fw.Spec.DebounceDuration.Duration)
+}
+
+func TestCreateFileWatchPathsFromStdin(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.MkdirAll("src")
+	f.MkdirAll("web")
+	f.MkdirAll("docs")
+
+	in := bytes.NewBufferString(`
+# generated by find
+src
+  web
+
+`)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{In: in})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--paths-from", "-", "my-watch", "docs"})
+	require.NoError(t, err)
+	require.NoError(t, c.Args(c, c.Flags().Args()))
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+
+	cwd, _ := filepath.EvalSymlinks(f.Path())
+	assert.Equal(t, []string{
+		filepath.Join(cwd, "docs"),
+		filepath.Join(cwd, "src"),
+		filepath.Join(cwd, "web"),
+	}, fw.Spec.WatchedPaths)
+}
+
+func TestCreateFileWatchPathsFromFile(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.MkdirAll("src")
+	f.WriteFile("paths.txt", "src\n")
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--paths-from", "paths.txt", "my-watch"})
+	require.NoError(t, err)
+	require.NoError(t, c.Args(c, c.Flags().Args()))
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+
+	cwd, _ := filepath.EvalSymlinks(f.Path())
+	assert.Equal(t, []string{filepath.Join(cwd, "src")}, fw.Spec.WatchedPaths)
+}
+
+func TestCreateFileWatchPathsFromEmpty(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{In: bytes.NewBufferString("# nothing\n\n")})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--paths-from", "-", "my-watch"})
+	require.NoError(t, err)
+
+	_, err = cmd.object(c.Flags().Args())
+	assert.EqualError(t, err, "no paths to watch: specify PATHS, --paths-from, or watchedPaths in --from-spec")
+}
+
+func TestCreateFileWatchPathsFromRequiresName(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--paths-from", "-"})
+	require.NoError(t, err)
+	assert.Error(t, c.Args(c, c.Flags().Args()))
+}
+
+func TestExpandHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	for _, tc := range []struct {
+		path     string
+		expected string
+	}{
+		{"~", home},
+		{"~/", home},
+		{"~/.config/app", filepath.Join(home, ".config", "app")},
+		{"~user/src", "~user/src"},
+		{"src/~/a", "src/~/a"},
+		{"src~", "src~"},
+		{"/abs/path", "/abs/path"},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			actual, err := expandHome(tc.path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestCreateFileWatchExpandsHome(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	home := f.JoinPath("home")
+	f.MkdirAll("home/.config/app")
+	f.MkdirAll("~/literal")
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"my-watch", "~/.config/app", "./~/literal"})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+
+	cwd, _ := filepath.EvalSymlinks(f.Path())
+	assert.Equal(t, []string{
+		filepath.Join(cwd, "home", ".config", "app"),
+		filepath.Join(cwd, "~", "literal"),
+	}, fw.Spec.WatchedPaths)
+}
+
+func TestCreateFileWatchExpandsEnv(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.MkdirAll("src/app")
+	t.Setenv("TILT_TEST_SRC", "src")
+	t.Setenv("TILT_TEST_IGNORE", "node_modules")
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{
+		"--ignore", "${TILT_TEST_IGNORE}",
+		"--ignore-for", "$TILT_TEST_SRC:build",
+		"my-watch", "$TILT_TEST_SRC/app",
+	})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+
+	cwd, _ := filepath.EvalSymlinks(f.Path())
+	assert.Equal(t, []string{filepath.Join(cwd, "src", "app")}, fw.Spec.WatchedPaths)
+	assert.Equal(t, []v1alpha1.IgnoreDef{
+		{BasePath: cwd, Patterns: []string{"node_modules"}},
+		{BasePath: filepath.Join(cwd, "src"), Patterns: []string{"build"}},
+	}, fw.Spec.Ignores)
+}
+
+func TestCreateFileWatchExpandEnvUndefined(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	t.Setenv("TILT_TEST_UNDEFINED", "")
+	require.NoError(t, os.Unsetenv("TILT_TEST_UNDEFINED"))
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"my-watch", "$TILT_TEST_UNDEFINED/src"})
+	require.NoError(t, err)
+
+	_, err = cmd.object(c.Flags().Args())
+	assert.EqualError(t, err, `path "$TILT_TEST_UNDEFINED/src" uses undefined environment variables: $TILT_TEST_UNDEFINED (use --no-expand to use it as is)`)
+
+	cmd = newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c = cmd.register()
+	err = c.Flags().Parse([]string{"--ignore", "${TILT_TEST_UNDEFINED}", "my-watch", "."})
+	require.NoError(t, err)
+
+	_, err = cmd.object(c.Flags().Args())
+	assert.EqualError(t, err, `--ignore "${TILT_TEST_UNDEFINED}" uses undefined environment variables: $TILT_TEST_UNDEFINED (use --no-expand to use it as is)`)
+}
+
+func TestCreateFileWatchExpandEnvEmpty(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	t.Setenv("TILT_TEST_EMPTY", "")
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"my-watch", "$TILT_TEST_EMPTY"})
+	require.NoError(t, err)
+
+	_, err = cmd.object(c.Flags().Args())
+	assert.EqualError(t, err, `path "$TILT_TEST_EMPTY" expands to nothing, because its environment variables are empty (use --no-expand to use it as is)`)
+}
+
+func TestCreateFileWatchNoExpand(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.MkdirAll("$literal")
+	t.Setenv("literal", "src")
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--no-expand", "--ignore", "$tmp", "my-watch", "$literal"})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+
+	cwd, _ := filepath.EvalSymlinks(f.Path())
+	assert.Equal(t, []string{filepath.Join(cwd, "$literal")}, fw.Spec.WatchedPaths)
+	assert.Equal(t, []string{"$tmp"}, fw.Spec.Ignores[0].Patterns)
+}
+
+func TestCreateFileWatchNoCwdJoin(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	t.Setenv("literal", "src")
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--no-cwd-join", "my-watch", "src/../web", "$literal", "~/missing", "/abs/*.go"})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"src/../web", "$literal", "~/missing", "/abs/*.go"}, fw.Spec.WatchedPaths)
+}
+
+func TestCreateFileWatchRequireAbsolute(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.MkdirAll("src")
+
+	for _, args := range [][]string{
+		{"--require-absolute", "my-watch", f.JoinPath("src"), "src"},
+		{"--require-absolute", "--no-cwd-join", "my-watch", "/src", "src"},
+	} {
+		cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+		c := cmd.register()
+		err := c.Flags().Parse(args)
+		require.NoError(t, err)
+
+		_, err = cmd.object(c.Flags().Args())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `--require-absolute: "src" is not an absolute path`)
+	}
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--require-absolute", "--no-cwd-join", "my-watch", "/src"})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/src"}, fw.Spec.WatchedPaths)
+}
+
+func TestCreateFileWatchRequireAbsoluteExpanded(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.MkdirAll("home/src")
+	t.Setenv("HOME", f.JoinPath("home"))
+	t.Setenv("USERPROFILE", f.JoinPath("home"))
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--require-absolute", "--no-collapse", "my-watch", "~/src", "$HOME/src"})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	src := canonicalPath(f.JoinPath("home", "src"))
+	assert.Equal(t, []string{src, src}, fw.Spec.WatchedPaths)
+}
+
+func TestDedupePatterns(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		patterns []string
+		expected []string
+	}{
+		{"no repeats", []string{"a", "b"}, []string{"a", "b"}},
+		{"repeat", []string{"node_modules", "build", "node_modules"}, []string{"node_modules", "build"}},
+		{"trims whitespace", []string{" a", "a ", "\tb\n"}, []string{"a", "b"}},
+		{"drops empty", []string{"", "  ", "a"}, []string{"a"}},
+		{"negation is distinct", []string{"a", "!a"}, []string{"a", "!a"}},
+		{"repeated negation", []string{"*.log", "!keep.log", "!keep.log"}, []string{"*.log", "!keep.log"}},
+		{"repeat after negation", []string{"a", "!b", "a"}, []string{"a", "!b", "a"}},
+		{"negation repeat after pattern", []string{"!a", "b", "!a"}, []string{"!a", "b", "!a"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, dedupePatterns(tc.patterns))
+		})
+	}
+}
+
+func TestCreateFileWatchDedupesIgnores(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.MkdirAll("src")
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{
+		"--ignore", "node_modules",
+		"--ignore", " node_modules ",
+		"--ignore-for", "src:vendor",
+		"--ignore-for", "src:vendor",
+		"my-watch", "src",
+	})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+
+	cwd, _ := filepath.EvalSymlinks(f.Path())
+	assert.Equal(t, []v1alpha1.IgnoreDef{
+		{BasePath: cwd, Patterns: []string{"node_modules"}},
+		{BasePath: filepath.Join(cwd, "src"), Patterns: []string{"vendor"}},
+	}, fw.Spec.Ignores)
+}
+
+func TestCreateFileWatchFromSpecStdinOverrides(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.MkdirAll("src")
+
+	in := bytes.NewBufferString(`
+watchedPaths: [src]
+ignores:
+- patterns: [src/vendor]
+debounceDuration: 1s
+`)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{In: in})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{
+		"--from-spec", "-",
+		"--ignore", "src/node_modules",
+		"--debounce", "2s",
+		"my-watch",
+	})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+
+	cwd, _ := filepath.EvalSymlinks(f.Path())
+	assert.Equal(t, []string{filepath.Join(cwd, "src")}, fw.Spec.WatchedPaths)
+	assert.Equal(t, []v1alpha1.IgnoreDef{
+		{BasePath: cwd, Patterns: []string{"src/node_modules"}},
+	}, fw.Spec.Ignores)
+	assert.Equal(t, 2*time.Second, fw.Spec.DebounceDuration.Duration)
+}
+
+func TestCreateFileWatchResolvesSymlinks(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.MkdirAll("real")
+	f.WriteSymlink("real", "link")
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	cmd.allowMissing = true
+	paths, err := cmd.paths([]string{"link", "missing"})
+	require.NoError(t, err)
+
+	cwd, _ := os.Getwd()
+	realPath, err := filepath.EvalSymlinks(f.JoinPath("real"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		realPath,
+		filepath.Join(cwd, "missing"),
+	}, paths)
+}
+
+func TestCreateFileWatchIgnoreFile(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.WriteFile(".dockerignore", `
+# build output
+build
+
+node_modules
+`)
+	f.WriteFile(".gitignore", "*.log\n")
+	f.MkdirAll("src")
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{
+		"--ignore", "tmp",
+		"--ignore-file", ".dockerignore",
+		"--ignore-file", ".gitignore",
+		"my-watch", "src",
+	})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+
+	cwd, _ := filepath.EvalSymlinks(f.Path())
+	assert.Equal(t, []v1alpha1.IgnoreDef{
+		{BasePath: cwd, Patterns: []string{"build", "node_modules", "*.log", "tmp"}},
+	}, fw.Spec.Ignores)
+}
+
+func TestCreateFileWatchIgnoreFileMissing(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.MkdirAll("src")
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--ignore-file", ".dockerignore", "my-watch", "src"})
+	require.NoError(t, err)
+
+	_, err = cmd.object(c.Flags().Args())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "reading --ignore-file")
+		assert.Contains(t, err.Error(), ".dockerignore")
+	}
+}
+
+func TestCreateFileWatchIgnoreBase(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.MkdirAll("repo/services/web")
+	f.Chdir()
+	f.WriteFile("repo/.dockerignore", "*.log\n")
+
+	require.NoError(t, os.Chdir(f.JoinPath("repo", "services")))
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{
+		"--ignore-base", "..",
+		"--ignore", "services/web/node_modules",
+		"--ignore-file", "../.dockerignore",
+		"my-watch", "web",
+	})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+
+	repo, _ := filepath.EvalSymlinks(f.JoinPath("repo"))
+	assert.Equal(t, []v1alpha1.IgnoreDef{
+		{BasePath: repo, Patterns: []string{"*.log", "services/web/node_modules"}},
+	}, fw.Spec.Ignores)
+}
+
+func TestCreateFileWatchIgnoreBaseInvalid(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.MkdirAll("src")
+	f.WriteFile("file.txt", "")
+
+	for _, tc := range []struct {
+		base string
+		err  string
+	}{
+		{"missing", "--ignore-base missing does not exist"},
+		{"file.txt", "--ignore-base must be a directory, got file.txt"},
+	} {
+		t.Run(tc.base, func(t *testing.T) {
+			cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+			c := cmd.register()
+			err := c.Flags().Parse([]string{"--ignore-base", tc.base, "my-watch", "src"})
+			require.NoError(t, err)
+
+			_, err = cmd.object(c.Flags().Args())
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestCreateFileWatchFromEnvPrefix(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.MkdirAll("src")
+	t.Setenv("TILT_TEST_IGNORE_B", "dist")
+	t.Setenv("TILT_TEST_IGNORE_A", "node_modules")
+	t.Setenv("TILT_TEST_IGNORE_C", "")
+	t.Setenv("TILT_TEST_OTHER", "src")
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{
+		"--from-env-prefix", "TILT_TEST_IGNORE_",
+		"--ignore", "!dist/keep",
+		"my-watch", "src",
+	})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+
+	cwd, _ := filepath.EvalSymlinks(f.Path())
+	assert.Equal(t, []v1alpha1.IgnoreDef{
+		{BasePath: cwd, Patterns: []string{"node_modules", "dist", "!dist/keep"}},
+	}, fw.Spec.Ignores)
+}
+
+func TestCreateFileWatchFromEnvPrefixNoMatches(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.MkdirAll("src")
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{ErrOut: errOut})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--from-env-prefix", "TILT_TEST_NOTHING_", "my-watch", "src"})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	assert.Empty(t, fw.Spec.Ignores)
+	assert.Contains(t, errOut.String(), `Warning: no environment variables start with --from-env-prefix "TILT_TEST_NOTHING_", so it ignores nothing`)
+}
+
+func TestEnvPrefixPatterns(t *testing.T) {
+	env := []string{
+		"CI_IGNORE_2=build",
+		"PATH=/usr/bin",
+		"CI_IGNORE_10= *.log ",
+		"CI_IGNORE_1=node_modules",
+		"CI_IGNORE_EMPTY=",
+		"CI_IGNORE=tmp",
+		"ci_ignore_lower=lower",
+	}
+	// Sorted by name, so CI_IGNORE_10 comes before CI_IGNORE_2.
+	assert.Equal(t, []string{"tmp", "node_modules", "*.log", "build"}, envPrefixPatterns("CI_IGNORE", env))
+	assert.Equal(t, []string{"node_modules", "*.log", "build"}, envPrefixPatterns("CI_IGNORE_", env))
+	assert.Empty(t, envPrefixPatterns("NOPE_", env))
+}
+
+func TestCreateFileWatchIgnoreFor(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.MkdirAll("frontend")
+	f.MkdirAll("backend")
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{
+		"--ignore", "*.log",
+		"--ignore-for", "frontend:node_modules",
+		"--ignore-for", "backend:target",
+		"--ignore-for", "frontend:dist",
+		"my-watch", "frontend", "backend",
+	})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+
+	cwd, _ := filepath.EvalSymlinks(f.Path())
+	assert.Equal(t, []v1alpha1.IgnoreDef{
+		{BasePath: cwd, Patterns: []string{"*.log"}},
+		{BasePath: filepath.Join(cwd, "backend"), Patterns: []string{"target"}},
+		{BasePath: filepath.Join(cwd, "frontend"), Patterns: []string{"node_modules", "dist"}},
+	}, fw.Spec.Ignores)
+}
+
+func TestCreateFileWatchIgnoreGlob(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.MkdirAll("src")
+	f.MkdirAll("build-1/out")
+	f.MkdirAll("build-2")
+	f.TouchFiles([]string{"build-notes.txt"})
+	outside := tempdir.NewTempDirFixture(t)
+	outside.MkdirAll("gen-a")
+	outside.MkdirAll("lib")
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{
+		"--ignore", "node_modules",
+		"--ignore-glob", "build-*",
+		"--ignore-glob", outside.JoinPath("gen-*"),
+		"my-watch", ".", outside.Path(),
+	})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+
+	cwd, _ := filepath.EvalSymlinks(f.Path())
+	outsideDir, _ := filepath.EvalSymlinks(outside.Path())
+	assert.Equal(t, []v1alpha1.IgnoreDef{
+		{BasePath: cwd, Patterns: []string{"node_modules", "build-1", "build-2", "build-notes.txt"}},
+		{BasePath: outsideDir, Patterns: []string{"node_modules", "gen-a"}},
+	}, fw.Spec.Ignores)
+}
+
+func TestCreateFileWatchIgnoreGlobNoMatches(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.MkdirAll("src")
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{ErrOut: errOut})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--ignore-glob", "build-*", "my-watch", "src"})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	assert.Empty(t, fw.Spec.Ignores)
+	assert.Contains(t, errOut.String(), `Warning: --ignore-glob "build-*" doesn't match any paths, so it ignores nothing`)
+}
+
+func TestEscapeIgnorePattern(t *testing.T) {
+	if filepath.Separator == '\\' {
+		t.Skip() // patterns can't escape characters on Windows
+	}
+
+	assert.Equal(t, "build-1/out", escapeIgnorePattern("build-1/out"))
+	assert.Equal(t, `a\*b\?c/\[d\]`, escapeIgnorePattern("a*b?c/[d]"))
+	assert.Equal(t, `\!important`, escapeIgnorePattern("!important"))
+	assert.Equal(t, "not!negated", escapeIgnorePattern("not!negated"))
+
+	matcher, err := dockerignore.NewDockerPatternMatcher("/project", []string{escapeIgnorePattern("a*b")})
+	require.NoError(t, err)
+	matches, err := matcher.Matches("/project/a*b")
+	require.NoError(t, err)
+	assert.True(t, matches)
+	matches, err = matcher.Matches("/project/axb")
+	require.NoError(t, err)
+	assert.False(t, matches)
+}
+
+func TestCreateFileWatchIgnoreForInvalid(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.MkdirAll("frontend")
+
+	for _, value := range []string{"node_modules", "frontend:", ":node_modules"} {
+		cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+		c := cmd.register()
+		err := c.Flags().Parse([]string{"--ignore-for", value, "my-watch", "frontend"})
+		require.NoError(t, err)
+
+		_, err = cmd.object(c.Flags().Args())
+		assert.EqualError(t, err, fmt.Sprintf("invalid --ignore-for %q: must be PATH:PATTERN", value))
+	}
+}
+
+func TestCreateFileWatchFilesAndDirectories(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.TouchFiles([]string{"config/app.yaml", "config/other.yaml", "src/main.go"})
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"my-watch", "config/app.yaml", "src"})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+
+	// The file is watched on its own, not via its parent directory.
+	cwd, _ := filepath.EvalSymlinks(f.Path())
+	assert.Equal(t, []string{
+		filepath.Join(cwd, "config", "app.yaml"),
+		filepath.Join(cwd, "src"),
+	}, fw.Spec.WatchedPaths)
+}
+
+func TestCreateFileWatchIgnoreNegation(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.TouchFiles([]string{"build/keep", "build/other"})
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--ignore=build", "--ignore=!build/keep", "my-watch", "."})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	require.Equal(t, []string{"build", "!build/keep"}, fw.Spec.Ignores[0].Patterns)
+
+	matcher := ignore.CreateFileChangeFilter(fw.Spec.Ignores)
+	cwd, _ := filepath.EvalSymlinks(f.Path())
+	ignored, err := matcher.Matches(filepath.Join(cwd, "build", "other"))
+	require.NoError(t, err)
+	assert.True(t, ignored)
+
+	ignored, err = matcher.Matches(filepath.Join(cwd, "build", "keep"))
+	require.NoError(t, err)
+	assert.False(t, ignored)
+}
+
+func TestCreateFileWatchIgnoreMultipleRoots(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.TouchFiles([]string{"repo/src/main.go", "repo/node_modules/a", "vendor/tree/node_modules/b", "vendor/tree/lib.go"})
+	f.Chdir()
+	require.NoError(t, os.Chdir(f.JoinPath("repo")))
+	base, _ := filepath.EvalSymlinks(f.Path())
+	repo := filepath.Join(base, "repo")
+	vendored := filepath.Join(base, "vendor", "tree")
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--ignore=node_modules", "my-watch", ".", vendored})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, []v1alpha1.IgnoreDef{
+		{BasePath: repo, Patterns: []string{"node_modules"}},
+		{BasePath: vendored, Patterns: []string{"node_modules"}},
+	}, fw.Spec.Ignores)
+
+	matcher := ignore.CreateFileChangeFilter(fw.Spec.Ignores)
+	for path, expected := range map[string]bool{
+		filepath.Join(repo, "node_modules", "a"):     true,
+		filepath.Join(vendored, "node_modules", "b"): true,
+		filepath.Join(repo, "src", "main.go"):        false,
+		filepath.Join(vendored, "lib.go"):            false,
+	} {
+		ignored, err := matcher.Matches(path)
+		require.NoError(t, err)
+		assert.Equal(t, expected, ignored, path)
+	}
+}
+
+func TestCreateFileWatchFromGitignore(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.TouchFiles([]string{".git/HEAD", "app/main.go", "app/keep.log", "app/lib/api.go"})
+	f.WriteFile(".gitignore", "# build output\n*.log\n/build/\nnode_modules/\n")
+	f.WriteFile("app/.gitignore", "tmp/\n!keep.log\n/dist\n")
+	f.WriteFile("app/lib/.gitignore", "*.gen.go\n")
+	// Inside an ignored directory, so it isn't read.
+	f.WriteFile("app/node_modules/pkg/.gitignore", "index.js\n")
+	f.Chdir()
+	require.NoError(t, os.Chdir("app"))
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--from-gitignore", "--ignore=*.tmp", "my-watch", "."})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	root := canonicalPath(f.Path())
+	assert.Equal(t, []v1alpha1.IgnoreDef{
+		{BasePath: filepath.Join(root, "app"), Patterns: []string{"*.tmp"}},
+		{BasePath: root, Patterns: []string{
+			"**/*.log", "build", "**/node_modules",
+			"app/**/tmp", "!app/**/keep.log", "app/dist",
+			"app/lib/**/*.gen.go",
+		}},
+	}, fw.Spec.Ignores)
+
+	matcher := ignore.CreateFileChangeFilter(fw.Spec.Ignores)
+	for path, expected := range map[string]bool{
+		"app/main.go":               false,
+		"app/debug.log":             true,
+		"app/keep.log":              false,
+		"app/tmp/x":                 true,
+		"app/dist/app.js":           true,
+		"app/lib/dist/x":            false,
+		"app/lib/api.gen.go":        true,
+		"app/node_modules/pkg/x.js": true,
+	} {
+		ignored, err := matcher.Matches(filepath.Join(root, path))
+		require.NoError(t, err)
+		assert.Equal(t, expected, ignored, path)
+	}
+}
+
+func TestCreateFileWatchFromGitignoreNotARepo(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--from-gitignore", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	_, err = cmd.object(c.Flags().Args())
+	assert.EqualError(t, err, fmt.Sprintf("--from-gitignore: %s isn't in a git repository", f.Path()))
+}
+
+func TestGitignorePattern(t *testing.T) {
+	for line, expected := range map[string]string{
+		"":             "",
+		"# comment":    "",
+		"   ":          "",
+		"/":            "",
+		"*.log":        "**/*.log",
+		"*.log  \r":    "**/*.log",
+		"build/":       "**/build",
+		"/build":       "build",
+		"docs/*.md":    "docs/*.md",
+		"**/cache":     "**/cache",
+		"!keep.log":    "!**/keep.log",
+		"!/dist/keep":  "!dist/keep",
+		"\\#notes":     "**/#notes",
+		"\\!important": "**/\\!important",
+	} {
+		pattern, ok := gitignorePattern(line)
+		assert.Equal(t, expected != "", ok, "%q", line)
+		assert.Equal(t, expected, pattern, "%q", line)
+	}
+}
+
+func TestCreateFileWatchExcludeHidden(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.TouchFiles([]string{"src/main.go", "src/.DS_Store", ".git/HEAD", ".github/workflows/ci.yaml", "node_modules/x"})
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{
+		"--exclude-hidden",
+		"--ignore=node_modules",
+		"--ignore=!**/.github",
+		"my-watch", ".",
+	})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	require.Equal(t, []string{"**/.*", "node_modules", "!**/.github"}, fw.Spec.Ignores[0].Patterns)
+
+	matcher := ignore.CreateFileChangeFilter(fw.Spec.Ignores)
+	cwd, _ := filepath.EvalSymlinks(f.Path())
+	for path, expected := range map[string]bool{
+		"src/main.go":               false,
+		"src/.DS_Store":             true,
+		".git/HEAD":                 true,
+		".github/workflows/ci.yaml": false,
+		"node_modules/x":            true,
+	} {
+		ignored, err := matcher.Matches(filepath.Join(cwd, path))
+		require.NoError(t, err)
+		assert.Equal(t, expected, ignored, path)
+	}
+}
+
+func TestCreateFileWatchOnlyExt(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.TouchFiles([]string{"main.go", "api/api.proto", "web/index.html", "vendor/dep.go", "README.md"})
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--only-ext=.go,.proto", "--ignore=vendor", "my-watch", "."})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	require.Len(t, fw.Spec.Ignores, 1)
+	require.Equal(t, []string{"**/*", "!**/*.go", "!**/*.proto", "vendor"}, fw.Spec.Ignores[0].Patterns)
+
+	matcher := ignore.CreateFileChangeFilter(fw.Spec.Ignores)
+	cwd, _ := filepath.EvalSymlinks(f.Path())
+	for path, expected := range map[string]bool{
+		"main.go":        false,
+		"api/api.proto":  false,
+		"web/index.html": true,
+		"vendor/dep.go":  true,
+		"README.md":      true,
+	} {
+		ignored, err := matcher.Matches(filepath.Join(cwd, path))
+		require.NoError(t, err)
+		assert.Equal(t, expected, ignored, path)
+	}
+
+	// Directories still need to be watched for the files inside them.
+	skip, err := matcher.MatchesEntireDir(filepath.Join(cwd, "api"))
+	require.NoError(t, err)
+	assert.False(t, skip)
+}
+
+func TestOnlyExtPatterns(t *testing.T) {
+	patterns, err := onlyExtPatterns(nil)
+	require.NoError(t, err)
+	assert.Empty(t, patterns)
+
+	patterns, err = onlyExtPatterns([]string{".go", " .tar.gz "})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"**/*", "!**/*.go", "!**/*.tar.gz"}, patterns)
+
+	for _, ext := range []string{"go", ".", "", "*.go", ".g[o]", "src/.go"} {
+		_, err := onlyExtPatterns([]string{ext})
+		assert.EqualError(t, err,
+			fmt.Sprintf("invalid --only-ext %q: must be a dot followed by the extension, like .go", strings.TrimSpace(ext)))
+	}
+}
+
+func TestCreateFileWatchInvalidIgnore(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.MkdirAll("src")
+
+	for _, tc := range []struct {
+		pattern string
+		err     string
+	}{
+		{"src/[", `invalid ignore pattern "src/[": syntax error in pattern`},
+		{"**/[z-a]", `invalid ignore pattern "**/[z-a]": syntax error in pattern`},
+		{"!", `invalid ignore pattern "!": negation must be followed by a pattern`},
+	} {
+		t.Run(tc.pattern, func(t *testing.T) {
+			cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+			c := cmd.register()
+			err := c.Flags().Parse([]string{"--ignore", tc.pattern, "my-watch", "src"})
+			require.NoError(t, err)
+
+			_, err = cmd.object(c.Flags().Args())
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestCreateFileWatchMissingPaths(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.MkdirAll("src")
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"my-watch", "src", "web", "docs"})
+	require.NoError(t, err)
+
+	_, err = cmd.object(c.Flags().Args())
+	cwd, _ := os.Getwd()
+	assert.EqualError(t, err, fmt.Sprintf(
+		"paths do not exist: web (%s), docs (%s)\n(use --allow-missing to watch them anyway)",
+		filepath.Join(cwd, "web"), filepath.Join(cwd, "docs")))
+}
+
+func TestCreateFileWatchAllowMissing(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--allow-missing", "my-watch", "web"})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+
+	cwd, _ := os.Getwd()
+	assert.Equal(t, []string{filepath.Join(cwd, "web")}, fw.Spec.WatchedPaths)
+}
+
+func TestCreateFileWatchErrorFormatJSON(t *testing.T) {
+	f := newServerFixture(t)
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: errOut})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--error-format", "json", "--dry-run", "client", "--wait", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.Error(t, err)
+
+	var envelope errorEnvelope
+	require.NoError(t, json.Unmarshal(errOut.Bytes(), &envelope))
+	assert.Equal(t, errorEnvelope{
+		Kind:    errorKindValidation,
+		Message: "--wait can't be used with --dry-run",
+	}, envelope)
+}
+
+func TestCreateFileWatchErrorFormatJSONConflict(t *testing.T) {
+	f := newServerFixture(t)
+	f.createFileWatch("my-watch")
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: errOut})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--error-format", "json", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.Error(t, err)
+	assert.True(t, apierrors.IsAlreadyExists(err))
+
+	var envelope errorEnvelope
+	require.NoError(t, json.Unmarshal(errOut.Bytes(), &envelope))
+	assert.Equal(t, errorKindConflict, envelope.Kind)
+	assert.Equal(t, int32(409), envelope.Code)
+	assert.Contains(t, envelope.Message, `"my-watch" already exists`)
+}
+
+func TestCreateFileWatchErrorFormatText(t *testing.T) {
+	f := newServerFixture(t)
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: errOut})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--dry-run", "client", "--wait", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.Error(t, err)
+	assert.Equal(t, "--wait can't be used with --dry-run", err.Error())
+	assert.Equal(t, "Error: --wait can't be used with --dry-run\n", errOut.String())
+}
+
+func TestCreateFileWatchQuietErrors(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+
+	for _, tc := range []struct {
+		args     []string
+		expected string
+	}{
+		{nil, "Error: paths do not exist: src (" + filepath.Join(f.Path(), "src") + ")\n(use --allow-missing to watch them anyway)\n"},
+		{[]string{"--quiet-errors"}, "Error: paths do not exist: src (" + filepath.Join(f.Path(), "src") + ")\n"},
+		{[]string{"--quiet-errors", "--error-format=json"},
+			`{"kind":"validation","message":"paths do not exist: src (` + filepath.Join(f.Path(), "src") + `)"}` + "\n"},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			errOut := bytes.NewBuffer(nil)
+			cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: errOut})
+			c := cmd.register()
+			err := c.Flags().Parse(append(append([]string{}, tc.args...), "--dry-run=client", "my-watch", "src"))
+			require.NoError(t, err)
+
+			ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+			err = cmd.run(ctx, c.Flags().Args())
+			require.Error(t, err)
+			assert.Equal(t, exitCodeValidation, exitCode(err))
+			assert.Equal(t, tc.expected, errOut.String())
+		})
+	}
+}
+
+func TestCreateFileWatchOutputStaysInStreams(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+
+	// Any write to the real stdout or stderr ends up in these pipes.
+	stdout, stderr := captureStdio(t)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	run := func(args ...string) (string, string, error) {
+		out := bytes.NewBuffer(nil)
+		errOut := bytes.NewBuffer(nil)
+		cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out, ErrOut: errOut})
+		c := cmd.register()
+		err := c.Flags().Parse(args)
+		require.NoError(t, err)
+		err = cmd.run(ctx, c.Flags().Args())
+		return out.String(), errOut.String(), err
+	}
+
+	verbose = true
+	t.Cleanup(func() { verbose = false })
+	out, errOut, err := run("--dry-run=client", "--allow-missing", "--ignore-glob=missing-*", "my-watch", "src")
+	require.NoError(t, err)
+	assert.Contains(t, out, "filewatch.tilt.dev/my-watch created (dry run)")
+	assert.Contains(t, errOut, `Warning: --ignore-glob "missing-*" doesn't match any paths`)
+	assert.Contains(t, errOut, "FileWatch my-watch watches:")
+
+	out, errOut, err = run("--dry-run=client", "my-watch", "src")
+	require.Error(t, err)
+	assert.Empty(t, out)
+	assert.Contains(t, errOut, "Error: paths do not exist")
+
+	assert.Empty(t, stdout())
+	assert.Empty(t, stderr())
+}
+
+// Replaces os.Stdout and os.Stderr with pipes until the test ends.
+// The returned functions restore them, and return what was written.
+func captureStdio(t *testing.T) (stdout func() string, stderr func() string) {
+	capture := func(file **os.File) func() string {
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		orig := *file
+		*file = w
+		var once sync.Once
+		var written []byte
+		read := func() string {
+			once.Do(func() {
+				*file = orig
+				_ = w.Close()
+				written, _ = io.ReadAll(r)
+				_ = r.Close()
+			})
+			return string(written)
+		}
+		t.Cleanup(func() { read() })
+		return read
+	}
+	return capture(&os.Stdout), capture(&os.Stderr)
+}
+
+func TestCreateFileWatchErrorFormatInvalid(t *testing.T) {
+	f := newServerFixture(t)
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--error-format", "xml", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `--error-format must be one of (text, json), got "xml"`)
+}
+
+func TestCreateFileWatchGenerateName(t *testing.T) {
+	f := newServerFixture(t)
+
+	out := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--generate-name", "--allow-missing", "src", "web"})
+	require.NoError(t, err)
+	require.NoError(t, c.Args(c, c.Flags().Args()))
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	var list v1alpha1.FileWatchList
+	err = f.client.List(f.ctx, &list)
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	fw := list.Items[0]
+	assert.Equal(t, "fw-", fw.GenerateName)
+	assert.True(t, strings.HasPrefix(fw.Name, "fw-"))
+	assert.Greater(t, len(fw.Name), len("fw-"))
+	assert.Len(t, fw.Spec.WatchedPaths, 2)
+	assert.Equal(t, fmt.Sprintf("filewatch.tilt.dev/%s created\n", fw.Name), out.String())
+}
+
+func TestCreateFileWatchGenerateNamePrefix(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--generate-name=debug-", "--allow-missing", "src"})
+	require.NoError(t, err)
+	require.NoError(t, c.Args(c, c.Flags().Args()))
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, "", fw.Name)
+	assert.Equal(t, "debug-", fw.GenerateName)
+	assert.Len(t, fw.Spec.WatchedPaths, 1)
+}
+
+func TestCreateFileWatchGenerateNameArgs(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--generate-name"})
+	require.NoError(t, err)
+	assert.Error(t, c.Args(c, c.Flags().Args()))
+
+	cmd = newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c = cmd.register()
+	err = c.Flags().Parse([]string{"src"})
+	require.NoError(t, err)
+	assert.Error(t, c.Args(c, c.Flags().Args()))
+}
+
+func TestCreateFileWatchGenerateNameUpdate(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--generate-name", "--update", "src"})
+	require.NoError(t, err)
+
+	err = c.ValidateFlagGroups()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "[generate-name update] were all set")
+}
+
+func TestCreateFileWatchReplace(t *testing.T) {
+	f := newCreateHelperFixture(t)
+	errOut := bytes.NewBuffer(nil)
+	f.helper.streams.ErrOut = errOut
+
+	existing := f.fileWatch("my-watch")
+	existing.Status.LastEventTime = metav1.NewMicroTime(time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC))
+	u, err := toUnstructured(existing)
+	require.NoError(t, err)
+	_, err = f.client.Resource(existing.GetGroupVersionResource()).Create(context.Background(), u, metav1.CreateOptions{})
+	require.NoError(t, err)
+	f.client.ClearActions()
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	cmd.helper = f.helper
+	cmd.replace = true
+	fw := f.fileWatch("my-watch")
+	fw.Spec.WatchedPaths = []string{"/web"}
+	result, err := cmd.createOrUpdate(context.Background(), fw)
+	require.NoError(t, err)
+
+	var verbs []string
+	for _, action := range f.client.Actions() {
+		verbs = append(verbs, action.GetVerb())
+	}
+	assert.Equal(t, []string{"create", "delete", "create"}, verbs)
+
+	var replaced v1alpha1.FileWatch
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(result.Object, &replaced)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/web"}, replaced.Spec.WatchedPaths)
+	assert.True(t, replaced.Status.LastEventTime.IsZero(), "status history should be gone")
+	assert.Equal(t, "replaced", f.helper.printFlags.NamePrintFlags.Operation)
+	assert.Contains(t, errOut.String(), "Warning: replacing FileWatch my-watch, so its status and file event history are lost")
+}
+
+func TestCreateFileWatchReplaceDeleteFails(t *testing.T) {
+	f := newCreateHelperFixture(t)
+	f.helper.streams.ErrOut = bytes.NewBuffer(nil)
+	gr := (&v1alpha1.FileWatch{}).GetGroupVersionResource().GroupResource()
+	f.client.PrependReactor("create", "filewatches", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewAlreadyExists(gr, "my-watch")
+	})
+	f.client.PrependReactor("delete", "filewatches", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(gr, "my-watch", fmt.Errorf("not allowed"))
+	})
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	cmd.helper = f.helper
+	cmd.replace = true
+	_, err := cmd.createOrUpdate(context.Background(), f.fileWatch("my-watch"))
+	assert.True(t, apierrors.IsForbidden(err), "expected the delete error, got: %v", err)
+}
+
+func TestCreateFileWatchReplaceWithUpdate(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--replace", "--update", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = c.ValidateFlagGroups()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "[replace update] were all set")
+}
+
+func TestCreateFileWatchGenerateNameTaken(t *testing.T) {
+	f := newCreateHelperFixture(t)
+	attempts := 0
+	f.client.PrependReactor("create", "filewatches", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		attempts++
+		if attempts < 3 {
+			gr := (&v1alpha1.FileWatch{}).GetGroupVersionResource().GroupResource()
+			return true, nil, apierrors.NewAlreadyExists(gr, "taken")
+		}
+		return false, nil, nil
+	})
+
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	cmd.helper = f.helper
+	fw := &v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: "fw-"},
+		Spec:       v1alpha1.FileWatchSpec{WatchedPaths: []string{"/src"}},
+	}
+	result, err := cmd.createOrUpdate(context.Background(), fw)
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.True(t, strings.HasPrefix(result.GetName(), "fw-"))
+}
+
+func TestCreateFileWatchLargeDirWarning(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.TouchFiles([]string{"big/a", "big/b", "big/c", "big/node_modules/d", "small/a"})
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{ErrOut: errOut})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--large-dir-threshold=2", "my-watch", "big", "small"})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	assert.Len(t, fw.Spec.WatchedPaths, 2)
+
+	big, _ := filepath.EvalSymlinks(f.JoinPath("big"))
+	assert.Equal(t, fmt.Sprintf("Warning: %s has more than 2 files, so watching it may slow down tilt. "+
+		"Consider ignoring the parts you don't need with --ignore.\n", big), errOut.String())
+}
+
+func TestCreateFileWatchLargeDirIgnored(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.TouchFiles([]string{"big/a", "big/node_modules/b", "big/node_modules/c"})
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{ErrOut: errOut})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--large-dir-threshold=2", "--ignore=big/node_modules", "my-watch", "big"})
+	require.NoError(t, err)
+
+	_, err = cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	assert.Empty(t, errOut.String())
+}
+
+func TestCreateFileWatchLargeDirThresholdDisabled(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.TouchFiles([]string{"big/a", "big/b"})
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{ErrOut: errOut})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--large-dir-threshold=0", "my-watch", "big"})
+	require.NoError(t, err)
+
+	_, err = cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	assert.Empty(t, errOut.String())
+
+	cmd = newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c = cmd.register()
+	err = c.Flags().Parse([]string{"--large-dir-threshold=-1", "my-watch", "big"})
+	require.NoError(t, err)
+
+	_, err = cmd.object(c.Flags().Args())
+	assert.EqualError(t, err, "--large-dir-threshold must not be negative, got -1")
+}
+
+func TestCountFiles(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	for i := 0; i < 10; i++ {
+		f.TouchFiles([]string{filepath.Join("tree", strconv.Itoa(i%3), strconv.Itoa(i))})
+	}
+	matcher := ignore.CreateFileChangeFilter(nil)
+
+	assert.Equal(t, 10, countFiles(f.JoinPath("tree"), matcher, 100))
+	assert.Equal(t, 4, countFiles(f.JoinPath("tree"), matcher, 4))
+	assert.Equal(t, 1, countFiles(f.JoinPath("tree", "0", "0"), matcher, 100))
+	assert.Equal(t, 0, countFiles(f.JoinPath("missing"), matcher, 100))
+}
+
+func TestCreateFileWatchEdit(t *testing.T) {
+	f := newServerFixture(t)
+
+	out := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out, ErrOut: bytes.NewBuffer(nil)})
+	var original string
+	cmd.editObject = func(b []byte) ([]byte, error) {
+		original = string(b)
+		return bytes.Replace(b, []byte("name: my-fw"), []byte("name: edited-fw"), 1), nil
+	}
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--edit", "--allow-missing", "my-fw", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	assert.Contains(t, original, "# Edit the FileWatch to create.")
+	assert.Contains(t, original, "kind: FileWatch")
+	assert.NotContains(t, original, "status")
+	assert.NotContains(t, original, "creationTimestamp")
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "edited-fw"}, &fw)
+	require.NoError(t, err)
+	assert.Len(t, fw.Spec.WatchedPaths, 1)
+	assert.Equal(t, "filewatch.tilt.dev/edited-fw created\n", out.String())
+}
+
+func TestCreateFileWatchEditUnchanged(t *testing.T) {
+	f := newServerFixture(t)
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: errOut})
+	cmd.editObject = func(b []byte) ([]byte, error) { return b, nil }
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--edit", "--allow-missing", "my-fw", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Contains(t, errOut.String(), "Edit cancelled: the file wasn't changed")
+
+	var list v1alpha1.FileWatchList
+	require.NoError(t, f.client.List(f.ctx, &list))
+	assert.Empty(t, list.Items)
+}
+
+func TestCreateFileWatchEditEmptied(t *testing.T) {
+	f := newServerFixture(t)
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: errOut})
+	cmd.editObject = func(b []byte) ([]byte, error) { return []byte("# just a comment\n\n"), nil }
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--edit", "--allow-missing", "my-fw", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Contains(t, errOut.String(), "Edit cancelled: the file is empty")
+
+	var list v1alpha1.FileWatchList
+	require.NoError(t, f.client.List(f.ctx, &list))
+	assert.Empty(t, list.Items)
+}
+
+func TestCreateFileWatchEditInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		edited   string
+		expected string
+	}{
+		{"bad yaml", "metadata: [", "reading edited FileWatch"},
+		{"unknown field", "metadata:\n  name: my-fw\nspec:\n  watchedPaths: [/src]\n  bogus: true\n", `unknown field "bogus"`},
+		{"no name", "spec:\n  watchedPaths: [/src]\n", "metadata.name cannot be empty"},
+		{"no paths", "metadata:\n  name: my-fw\n", "spec.watchedPaths cannot be empty"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newServerFixture(t)
+			cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: bytes.NewBuffer(nil)})
+			cmd.editObject = func(b []byte) ([]byte, error) { return []byte(tc.edited), nil }
+			c := cmd.register()
+			err := c.Flags().Parse([]string{"--edit", "--allow-missing", "my-fw", "src"})
+			require.NoError(t, err)
+
+			err = cmd.run(f.ctx, c.Flags().Args())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestCreateFileWatchFollowLogs(t *testing.T) {
+	f := newServerFixture(t)
+	ctx, cancel := context.WithCancel(f.ctx)
+	defer cancel()
+
+	out := bytes.NewBuffer(nil)
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out, ErrOut: errOut})
+	lines := []string{
+		"filewatch my-watch: watching /src\n",
+		"filewatch my-watch-2: watching /web\n",
+		"building web\n",
+		"filewatch my-watch: too many files\n",
+	}
+	calls := 0
+	cmd.streamLogs = func(ctx context.Context, handler server.ViewHandler) error {
+		calls++
+		if calls == 1 {
+			// Disconnect after the first two lines.
+			require.NoError(t, handler.Handle(logView(lines[:2])))
+			return fmt.Errorf("connection refused")
+		}
+		// The session re-sends everything after reconnecting.
+		require.NoError(t, handler.Handle(logView(lines)))
+		cancel()
+		return nil
+	}
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--follow-logs", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, "filewatch.tilt.dev/my-watch created\n"+
+		"filewatch my-watch: watching /src\n"+
+		"filewatch my-watch: too many files\n", out.String())
+	assert.Contains(t, errOut.String(), "Streaming logs: connection refused\nConnection lost. Reconnecting in 250ms...\n")
+}
+
+func TestCreateFileWatchFollowLogsDryRun(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--follow-logs", "--dry-run=client", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(newServerFixture(t).ctx, c.Flags().Args())
+	assert.EqualError(t, err, "--follow-logs can't be used with --dry-run")
+}
+
+func TestCreateFileWatchWaitForChange(t *testing.T) {
+	f := newServerFixture(t)
+	ctx, cancel := context.WithCancel(f.ctx)
+	defer cancel()
+
+	out := bufsync.NewThreadSafeBuffer()
+	errOut := bufsync.NewThreadSafeBuffer()
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out, ErrOut: errOut})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--wait-for-change", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- cmd.run(ctx, c.Flags().Args())
+	}()
+
+	errOut.AssertEventuallyContains(t, "Waiting for file changes in FileWatch my-watch. Press Ctrl-C to stop.\n", time.Second)
+	t1 := metav1.NewMicroTime(time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC))
+	t2 := metav1.NewMicroTime(time.Date(2021, time.March, 4, 5, 6, 8, 0, time.UTC))
+	f.recordFileEvent("my-watch", t1, "/src/a.go", "/src/b.go")
+	out.AssertEventuallyContains(t, "2021-03-04T05:06:07Z\t/src/b.go\n", time.Second)
+	f.recordFileEvent("my-watch", t2, "/src/c.go")
+	out.AssertEventuallyContains(t, "2021-03-04T05:06:08Z\t/src/c.go\n", time.Second)
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for --wait-for-change to exit")
+	}
+
+	// Earlier events aren't printed again when later ones are added.
+	assert.Equal(t, "filewatch.tilt.dev/my-watch created\n"+
+		"2021-03-04T05:06:07Z\t/src/a.go\n"+
+		"2021-03-04T05:06:07Z\t/src/b.go\n"+
+		"2021-03-04T05:06:08Z\t/src/c.go\n", out.String())
+}
+
+func TestCreateFileWatchWaitForChangeAttach(t *testing.T) {
+	f := newServerFixture(t)
+	f.createFileWatch("my-watch")
+	f.recordFileEvent("my-watch", metav1.NewMicroTime(time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC)), "/src/a.go")
+	ctx, cancel := context.WithCancel(f.ctx)
+	defer cancel()
+
+	out := bufsync.NewThreadSafeBuffer()
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out, ErrOut: bufsync.NewThreadSafeBuffer()})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--wait-for-change", "--attach=my-watch"})
+	require.NoError(t, err)
+	require.NoError(t, c.Args(c, c.Flags().Args()))
+
+	done := make(chan error)
+	go func() {
+		done <- cmd.run(ctx, c.Flags().Args())
+	}()
+
+	out.AssertEventuallyContains(t, "2021-03-04T05:06:07Z\t/src/a.go\n", time.Second)
+	f.recordFileEvent("my-watch", metav1.NewMicroTime(time.Date(2021, time.March, 4, 5, 6, 8, 0, time.UTC)), "/src/b.go")
+	out.AssertEventuallyContains(t, "2021-03-04T05:06:08Z\t/src/b.go\n", time.Second)
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for --wait-for-change to exit")
+	}
+	assert.NotContains(t, out.String(), "created")
+}
+
+func TestCreateFileWatchWaitForChangeJSON(t *testing.T) {
+	f := newServerFixture(t)
+	f.createFileWatch("my-watch")
+	ctx, cancel := context.WithCancel(f.ctx)
+	defer cancel()
+
+	out := bufsync.NewThreadSafeBuffer()
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out, ErrOut: bufsync.NewThreadSafeBuffer()})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--wait-for-change", "--attach=my-watch", "--output-events-as=json"})
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- cmd.run(ctx, c.Flags().Args())
+	}()
+
+	f.recordFileEvent("my-watch", metav1.NewMicroTime(time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC)), "/src/a.go", "/src/b.go")
+	out.AssertEventuallyContains(t, `"path":"/src/b.go"`, time.Second)
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for --wait-for-change to exit")
+	}
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+
+	var changes []fileChangeJSON
+	decoder := json.NewDecoder(strings.NewReader(out.String()))
+	for decoder.More() {
+		var change fileChangeJSON
+		require.NoError(t, decoder.Decode(&change))
+		changes = append(changes, change)
+	}
+	assert.Equal(t, []fileChangeJSON{
+		{Name: "my-watch", Path: "/src/a.go", Time: "2021-03-04T05:06:07.000000Z", ResourceVersion: fw.ResourceVersion},
+		{Name: "my-watch", Path: "/src/b.go", Time: "2021-03-04T05:06:07.000000Z", ResourceVersion: fw.ResourceVersion},
+	}, changes)
+}
+
+func TestCreateFileWatchWaitForChangeInvalid(t *testing.T) {
+	f := newServerFixture(t)
+
+	for _, tc := range []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--wait-for-change", "--attach=no-such-watch"}, "no FileWatch named no-such-watch in the tilt session"},
+		{[]string{"--attach=my-watch"}, "--attach can only be used with --wait-for-change"},
+		{[]string{"--wait-for-change", "--dry-run=client", "--allow-missing", "my-watch", "src"},
+			"--wait-for-change can't be used with --dry-run"},
+		{[]string{"--output-events-as=json", "--allow-missing", "my-watch", "src"},
+			"--output-events-as can only be used with --wait-for-change"},
+		{[]string{"--wait-for-change", "--output-events-as=yaml", "--allow-missing", "my-watch", "src"},
+			`--output-events-as must be one of (text, json), got "yaml"`},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: bytes.NewBuffer(nil)})
+			c := cmd.register()
+			err := c.Flags().Parse(tc.args)
+			require.NoError(t, err)
+
+			err = cmd.run(f.ctx, c.Flags().Args())
+			assert.EqualError(t, err, tc.expected)
+		})
+	}
+}
+
+func TestMentionsName(t *testing.T) {
+	for _, tc := range []struct {
+		text     string
+		expected bool
+	}{
+		{"src", true},
+		{"filewatch src: error", true},
+		{`closing notifier for "/src"`, true},
+		{"filewatch src-and-web: error", false},
+		{"filewatch web-src: error", false},
+		{"filewatch web-src, then src", true},
+		{"source", false},
+		{"", false},
+	} {
+		assert.Equal(t, tc.expected, mentionsName(tc.text, "src"), tc.text)
+	}
+}
+
+// A view of the session's log, with one global segment per line.
+func logView(lines []string) *proto_webview.View {
+	segments := []*proto_webview.LogSegment{}
+	for _, line := range lines {
+		segments = append(segments, &proto_webview.LogSegment{SpanId: "global", Text: line})
+	}
+	return &proto_webview.View{
+		LogList: &proto_webview.LogList{
+			Spans:        map[string]*proto_webview.LogSpan{"global": {}},
+			Segments:     segments,
+			ToCheckpoint: int32(len(segments)),
+		},
+	}
+}
+
+func TestCreateFileWatchIgnoreCase(t *testing.T) {
+	f := newServerFixture(t)
+	f.Chdir()
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: errOut})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--ignore-case", "--ignore=BUILD", "--ignore-for=web:DIST", "--allow-missing", "my-watch", "src", "web"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Empty(t, errOut.String())
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	require.NoError(t, err)
+	require.Len(t, fw.Spec.Ignores, 2)
+	for _, ignore := range fw.Spec.Ignores {
+		assert.True(t, ignore.IgnoreCase, ignore.BasePath)
+	}
+
+	matcher := ignore.CreateFileChangeFilter(fw.Spec.Ignores)
+	ignored, err := matcher.Matches(filepath.Join(fw.Spec.Ignores[0].BasePath, "build", "out.txt"))
+	require.NoError(t, err)
+	assert.True(t, ignored)
+}
+
+func TestCreateFileWatchIgnoreCaseDefault(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--ignore=BUILD", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	fw, err := cmd.object(c.Flags().Args())
+	require.NoError(t, err)
+	require.Len(t, fw.Spec.Ignores, 1)
+	assert.False(t, fw.Spec.Ignores[0].IgnoreCase)
+}
+
+func TestCreateFileWatchIgnoreCaseUnsupported(t *testing.T) {
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{ErrOut: errOut})
+	cmd.ignoreCase = true
+	fw := &v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-watch"},
+		Spec: v1alpha1.FileWatchSpec{
+			Ignores: []v1alpha1.IgnoreDef{{BasePath: "/src", Patterns: []string{"build"}, IgnoreCase: true}},
+		},
+	}
+
+	// A session that kept the field.
+	result, err := toUnstructured(fw)
+	require.NoError(t, err)
+	cmd.warnIfIgnoreCaseDropped(fw, result)
+	assert.Empty(t, errOut.String())
+
+	// A session that dropped it.
+	err = unstructured.SetNestedSlice(result.Object, []interface{}{
+		map[string]interface{}{"basePath": "/src", "patterns": []interface{}{"build"}},
+	}, "spec", "ignores")
+	require.NoError(t, err)
+	cmd.warnIfIgnoreCaseDropped(fw, result)
+	assert.Contains(t, errOut.String(), "Warning: the tilt session doesn't support --ignore-case")
+}
+
+func TestCreateFileWatchUnrelatedIgnores(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.Chdir()
+	f.MkdirAll("project/src")
+	f.MkdirAll("elsewhere")
+	src, _ := filepath.EvalSymlinks(f.JoinPath("project", "src"))
+
+	object := func(dir string, args ...string) string {
+		require.NoError(t, os.Chdir(dir))
+		errOut := bytes.NewBuffer(nil)
+		cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{ErrOut: errOut})
+		c := cmd.register()
+		require.NoError(t, c.Flags().Parse(args))
+		_, err := cmd.object(c.Flags().Args())
+		require.NoError(t, err)
+		return errOut.String()
+	}
+
+	// Run from the project, so the ignores apply.
+	assert.Empty(t, object(f.JoinPath("project"), "--ignore=build", "my-watch", src))
+
+	// Run from inside the watched path.
+	assert.Empty(t, object(src, "--ignore=build", "my-watch", src))
+
+	// Run from an unrelated directory.
+	elsewhere, _ := filepath.EvalSymlinks(f.JoinPath("elsewhere"))
+	errOut := object(elsewhere, "--ignore=build", "my-watch", src)
+	assert.Contains(t, errOut, fmt.Sprintf("Warning: the ignores relative to %s won't ignore anything", elsewhere))
+	assert.NotContains(t, errOut, fmt.Sprintf("relative to %s won't", src))
+
+	assert.Empty(t, object(elsewhere, "--ignore=build", "--no-warn-ignores", "my-watch", src))
+}
+
+func TestUnrelatedIgnores(t *testing.T) {
+	paths := []string{"/project/src", "/project/web/index.html"}
+	for _, tc := range []struct {
+		ignore    v1alpha1.IgnoreDef
+		unrelated bool
+	}{
+		{v1alpha1.IgnoreDef{BasePath: "/project"}, false},
+		{v1alpha1.IgnoreDef{BasePath: "/project/src"}, false},
+		{v1alpha1.IgnoreDef{BasePath: "/project/src/vendor"}, false},
+		{v1alpha1.IgnoreDef{BasePath: "/project/web"}, false},
+		{v1alpha1.IgnoreDef{BasePath: "/project/docs"}, true},
+		{v1alpha1.IgnoreDef{BasePath: "/elsewhere"}, true},
+		{v1alpha1.IgnoreDef{BasePath: "/Project"}, true},
+		{v1alpha1.IgnoreDef{BasePath: "/Project", IgnoreCase: true}, false},
+	} {
+		assert.Equal(t, tc.unrelated, len(unrelatedIgnores(paths, []v1alpha1.IgnoreDef{tc.ignore})) == 1, tc.ignore.BasePath)
+	}
+}
+
+func TestCreateFileWatchTimeout(t *testing.T) {
+	f := newServerFixture(t)
+
+	// A session that never answers, until the client gives up or the test ends.
+	release := make(chan struct{})
+	session := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer session.Close()
+	defer close(release)
+	client, err := dynamic.NewForConfig(&rest.Config{Host: session.URL})
+	require.NoError(t, err)
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: errOut})
+	cmd.helper.dynamicClient = client
+	c := cmd.register()
+	err = c.Flags().Parse([]string{"--timeout=100ms", "--error-format=json", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	start := time.Now()
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Contains(t, err.Error(), "timed out after 100ms (see --timeout)")
+	assert.Contains(t, errOut.String(), `"kind":"connection"`)
+}
+
+func TestCancelOnSignalStop(t *testing.T) {
+	ctx, stop := cancelOnSignal(context.Background())
+	assert.NoError(t, ctx.Err())
+	assert.Nil(t, stop())
+	assert.Error(t, ctx.Err())
+}
+
+func TestCreateFileWatchTimeoutNegative(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--timeout=-1s", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(newServerFixture(t).ctx, c.Flags().Args())
+	assert.EqualError(t, err, "--timeout must not be negative, got -1s")
+}
+
+func TestCreateFileWatchMaxPathsNegative(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--max-paths=-1", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(newServerFixture(t).ctx, c.Flags().Args())
+	assert.EqualError(t, err, "--max-paths must not be negative, got -1")
+}
+
+func TestCreateFileWatchTimeoutNotReached(t *testing.T) {
+	f := newServerFixture(t)
+
+	out := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--timeout=30s", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, "filewatch.tilt.dev/my-watch created\n", out.String())
+}
+
+func TestServedVersions(t *testing.T) {
+	client := fakeDiscovery("tilt.dev/v1beta1", "tilt.dev/v1alpha1")
+	versions, err := servedVersions(client, v1alpha1.SchemeGroupVersion.WithKind("FileWatch").GroupKind())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1beta1", "v1alpha1"}, versions)
+
+	versions, err = servedVersions(client, schema.GroupKind{Group: "tilt.dev", Kind: "Cmd"})
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+}
+
+func TestCreateFileWatchAPIVersionMatches(t *testing.T) {
+	f := newServerFixture(t)
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: errOut})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--strict-version", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.NotContains(t, errOut.String(), "Warning")
+
+	versions, err := servedVersions(cmd.helper.discoveryClient, v1alpha1.SchemeGroupVersion.WithKind("FileWatch").GroupKind())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1alpha1"}, versions)
+}
+
+func TestCreateFileWatchAPIVersionMismatch(t *testing.T) {
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{ErrOut: errOut})
+	cmd.helper.discoveryClient = fakeDiscovery("tilt.dev/v1beta1")
+
+	err := cmd.checkAPIVersion(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Warning: the tilt session serves FileWatches as tilt.dev/v1beta1, but this tilt CLI creates them as tilt.dev/v1alpha1, "+
+		"so the session may ignore some of their fields. Upgrade tilt so that they match, or pass --skip-version-check.\n", errOut.String())
+
+	cmd.strictVersion = true
+	err = cmd.checkAPIVersion(context.Background())
+	assert.EqualError(t, err, "the tilt session serves FileWatches as tilt.dev/v1beta1, but this tilt CLI creates them as tilt.dev/v1alpha1, "+
+		"so the session may ignore some of their fields (see --strict-version)")
+}
+
+func TestCreateFileWatchAPIVersionNotServed(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	cmd.helper.discoveryClient = fakeDiscovery()
+	cmd.strictVersion = true
+
+	err := cmd.checkAPIVersion(context.Background())
+	assert.EqualError(t, err, "the tilt session doesn't serve FileWatches, but this tilt CLI creates them as tilt.dev/v1alpha1 (see --strict-version)")
+}
+
+func TestCreateFileWatchSkipVersionCheck(t *testing.T) {
+	client := fakeDiscovery("tilt.dev/v1beta1")
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{})
+	cmd.helper.discoveryClient = client
+	cmd.skipVersionCheck = true
+
+	err := cmd.checkAPIVersion(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, client.Actions())
+}
+
+// A discovery client for a server that serves FileWatches at each of groupVersions.
+// The first is the group's preferred version.
+func fakeDiscovery(groupVersions ...string) *fakediscovery.FakeDiscovery {
+	resources := []*metav1.APIResourceList{}
+	for _, gv := range groupVersions {
+		resources = append(resources, &metav1.APIResourceList{
+			GroupVersion: gv,
+			APIResources: []metav1.APIResource{
+				{Name: "filewatches", Kind: "FileWatch"},
+				{Name: "filewatches/status", Kind: "FileWatch"},
+			},
+		})
+	}
+	return &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: resources}}
+}
+
+func TestCreateFileWatchFieldManager(t *testing.T) {
+	f := newServerFixture(t)
+
+	for _, tc := range []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"default-watch", nil, "tilt"},
+		{"custom-watch", []string{"--field-manager=my-tool"}, "my-tool"},
+	} {
+		cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+		c := cmd.register()
+		err := c.Flags().Parse(append(append([]string{}, tc.args...), "--allow-missing", tc.name, "src"))
+		require.NoError(t, err)
+		err = cmd.run(f.ctx, c.Flags().Args())
+		require.NoError(t, err)
+
+		var fw v1alpha1.FileWatch
+		err = f.client.Get(f.ctx, types.NamespacedName{Name: tc.name}, &fw)
+		require.NoError(t, err)
+		var managers []string
+		for _, entry := range fw.ManagedFields {
+			managers = append(managers, entry.Manager)
+		}
+		assert.Equal(t, []string{tc.expected}, managers, tc.name)
+	}
+}
+
+func TestCreateFileWatchEmptyFieldManager(t *testing.T) {
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--field-manager=", "--allow-missing", "--dry-run=client", "my-watch", "src"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	assert.EqualError(t, err, "--field-manager cannot be empty")
+}
+
+func TestCreateFileWatchFieldErrors(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(v1alpha1.NewScheme())
+	client.PrependReactor("create", "filewatches", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		gk := schema.GroupKind{Group: "tilt.dev", Kind: "FileWatch"}
+		return true, nil, apierrors.NewInvalid(gk, "my-watch", field.ErrorList{
+			field.Invalid(field.NewPath("spec", "ignores").Index(0).Child("patterns"), "[", "syntax error in pattern"),
+			field.Invalid(field.NewPath("spec", "debounceDuration"), "-1s", "must not be negative"),
+			field.Required(field.NewPath("spec", "unknownField"), ""),
+		})
+	})
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: errOut})
+	cmd.helper.dynamicClient = client
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	require.Error(t, err)
+	assert.True(t, apierrors.IsInvalid(err))
+	assert.Equal(t, exitCodeValidation, exitCode(err))
+	assert.Equal(t, `Error: FileWatch "my-watch" is invalid:
+  spec.ignores[0].patterns: Invalid value: "[": syntax error in pattern (see --ignore)
+  spec.debounceDuration: Invalid value: "-1s": must not be negative (see --debounce)
+  spec.unknownField: Required value
+`, errOut.String())
+}
+
+func TestExplainFieldErrorsOtherErrors(t *testing.T) {
+	gr := schema.GroupResource{Group: "tilt.dev", Resource: "filewatches"}
+	for _, err := range []error{
+		nil,
+		errors.New("boom"),
+		apierrors.NewAlreadyExists(gr, "my-watch"),
+		apierrors.NewInvalid(schema.GroupKind{Group: "tilt.dev", Kind: "FileWatch"}, "my-watch", nil),
+	} {
+		assert.Equal(t, err, explainFieldErrors(err))
+	}
+}
@@ -0,0 +1,49 @@
+//go:build !windows
+// +build !windows
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestCreateFileWatchCanceledBySignal(t *testing.T) {
+	f := newServerFixture(t)
+
+	// Keeps the test process alive if the signal arrives after the command's
+	// handler is removed.
+	guard := make(chan os.Signal, 1)
+	signal.Notify(guard, syscall.SIGINT)
+	defer signal.Stop(guard)
+
+	errOut := bytes.NewBuffer(nil)
+	cmd := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: errOut})
+	c := cmd.register()
+	// Nothing starts the watch, so --wait waits until it's interrupted.
+	err := c.Flags().Parse([]string{"--wait", "--allow-missing", "my-watch", "src"})
+	require.NoError(t, err)
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		_ = syscall.Kill(os.Getpid(), syscall.SIGINT)
+	}()
+
+	start := time.Now()
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, "canceled by interrupt", err.Error())
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, "Error: canceled by interrupt\n", errOut.String())
+}
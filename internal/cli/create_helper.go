@@ -2,80 +2,715 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 
 	"github.com/tilt-dev/tilt-apiserver/pkg/server/builder/resource"
+	"github.com/tilt-dev/wmclient/pkg/dirs"
+
+	cliclient "github.com/tilt-dev/tilt/internal/cli/client"
+	"github.com/tilt-dev/tilt/internal/filelock"
+	"github.com/tilt-dev/tilt/internal/hud/server"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
 )
 
 // Helper for human-friendly CLI for creating objects.
 //
 // See other create commands for usage examples.
 type createHelper struct {
-	streams       genericclioptions.IOStreams
-	printFlags    *genericclioptions.PrintFlags
-	dynamicClient dynamic.Interface
-	printer       printers.ResourcePrinter
+	streams         genericclioptions.IOStreams
+	printFlags      *genericclioptions.PrintFlags
+	restConfig      *rest.Config
+	dynamicClient   dynamic.Interface
+	discoveryClient discovery.DiscoveryInterface
+	printer         printers.ResourcePrinter
+
+	// When set, print only the name of the created object.
+	quiet bool
+
+	// When set, print only the name of the created object to stdout,
+	// and the usual output to stderr.
+	nameOnlyOnSuccess bool
+
+	// The verb printed after the name of a created object, e.g., "filewatch.tilt.dev/src created".
+	createdVerb string
+
+	// How many times to retry a create that fails with a transient error,
+	// and how long to wait before the first retry.
+	retries      int
+	retryBackoff time.Duration
+
+	// One of (none, client). With client, objects are only printed.
+	dryRun string
+
+	// The field manager to create objects as. If empty, the server
+	// names the manager after the client's user agent.
+	fieldManager string
+
+	// One of (text, json). With json, errors are printed as JSON objects.
+	errorFormat string
+
+	// When set, errors are printed without the hints on how to fix them.
+	quietErrors bool
+
+	// The printer for -o wide, for commands that support it.
+	widePrinter printers.ResourcePrinter
 }
 
+const (
+	dryRunNone   = "none"
+	dryRunClient = "client"
+)
+
+const (
+	errorFormatText = "text"
+	errorFormatJSON = "json"
+)
+
 func newCreateHelper(streams genericclioptions.IOStreams) *createHelper {
+	// Errors and warnings are always written to ErrOut, so that the command
+	// never bypasses its streams. Embedders that don't want them can omit it.
+	if streams.ErrOut == nil {
+		streams.ErrOut = io.Discard
+	}
 	return &createHelper{
-		streams:    streams,
-		printFlags: genericclioptions.NewPrintFlags("created"),
+		streams:      streams,
+		printFlags:   genericclioptions.NewPrintFlags("created"),
+		createdVerb:  "created",
+		retries:      3,
+		retryBackoff: 250 * time.Millisecond,
+		dryRun:       dryRunNone,
+		errorFormat:  errorFormatText,
 	}
 }
 
 func (h *createHelper) addFlags(cmd *cobra.Command) {
 	h.printFlags.AddFlags(cmd)
+	output := cmd.Flags().Lookup("output")
+	formats := "jsonl, "
+	if h.widePrinter != nil {
+		formats = "jsonl, wide, "
+	}
+	output.Usage = strings.Replace(output.Usage, "One of: (", "One of: ("+formats, 1)
+	cmd.Flags().StringVar(&h.createdVerb, "created-verb", h.createdVerb,
+		"The verb to print after the name of a created object, e.g., 'registered'. Useful when wrapping this command in another tool.")
 	addConnectServerFlags(cmd)
+	addAPIServerFlags(cmd)
+}
+
+// Adds the --dry-run flag, for commands that support previewing objects.
+func (h *createHelper) addDryRunFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&h.dryRun, "dry-run", h.dryRun,
+		"One of (none, client). With 'client', only print the object that would be created, without connecting to the tilt session.")
+}
+
+// Adds the --error-format flag, for commands that report errors with reportError.
+func (h *createHelper) addErrorFormatFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&h.errorFormat, "error-format", h.errorFormat,
+		"One of (text, json). With 'json', a failure is printed to stderr as a JSON object with kind, code, and message fields.")
+	cmd.Flags().BoolVar(&h.quietErrors, "quiet-errors", false,
+		"Print just the error of a failure, without the hints on how to fix it. The exit code is the same.")
+}
+
+// Returns a usage error if --error-format isn't a format that reportError knows.
+func (h *createHelper) checkErrorFormat() error {
+	switch h.errorFormat {
+	case errorFormatText, errorFormatJSON:
+		return nil
+	}
+	return usageErrorf("--error-format must be one of (text, json), got %q", h.errorFormat)
 }
 
 func (h *createHelper) interpretFlags(ctx context.Context) error {
-	printer, err := h.printFlags.ToPrinter()
+	switch h.dryRun {
+	case "", dryRunNone, dryRunClient:
+	case "server":
+		// The tilt apiserver's storage ignores the DryRun option,
+		// so a server dry run would create the object for real.
+		return fmt.Errorf("--dry-run=server is not supported by the tilt session. Use --dry-run=client")
+	default:
+		return fmt.Errorf("--dry-run must be one of (none, client), got %q", h.dryRun)
+	}
+
+	if strings.TrimSpace(h.createdVerb) == "" {
+		return fmt.Errorf("--created-verb cannot be empty")
+	}
+	err := h.setOperation(h.createdVerb)
 	if err != nil {
 		return err
 	}
-	h.printer = printer
 
-	dynamicClient, err := h.createDynamicClient(ctx)
+	if h.dryRun == dryRunClient {
+		return nil
+	}
+	return h.connect(ctx)
+}
+
+// Connects to the tilt session, unless already connected.
+//
+// A client dry run doesn't connect by default, but may connect to read
+// objects, e.g., to diff against them.
+func (h *createHelper) connect(ctx context.Context) error {
+	// Already connected, e.g., to a fake session in tests.
+	if h.dynamicClient != nil {
+		return nil
+	}
+
+	restConfig, err := newRESTConfig(ctx)
+	if err != nil {
+		return err
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
 	if err != nil {
 		return err
 	}
+	dir, err := dirs.UseTiltDevDir()
+	if err != nil {
+		return err
+	}
+	discoveryClient, err := cliclient.NewCachedDiscoveryClient(dir, restConfig)
+	if err != nil {
+		return err
+	}
+	h.restConfig = restConfig
 	h.dynamicClient = dynamicClient
+	h.discoveryClient = discoveryClient
 	return nil
 }
 
 func (h *createHelper) create(ctx context.Context, resourceObj resource.Object) error {
-	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(resourceObj)
+	result, err := h.createObject(ctx, resourceObj)
 	if err != nil {
 		return err
 	}
+	return h.print(result)
+}
+
+// Creates the object on the server, without printing it.
+//
+// Transient errors are retried up to --retries times, with exponential backoff.
+func (h *createHelper) createObject(ctx context.Context, resourceObj resource.Object) (*unstructured.Unstructured, error) {
+	if h.retries < 0 {
+		return nil, fmt.Errorf("--retries must not be negative, got %d", h.retries)
+	}
+
+	u, err := toUnstructured(resourceObj)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.dryRun == dryRunClient {
+		return u, nil
+	}
+
+	client := h.dynamicClient.Resource(resourceObj.GetGroupVersionResource())
+	backoff := h.retryBackoff
+	for attempt := 0; ; attempt++ {
+		result, err := client.Create(ctx, u, metav1.CreateOptions{FieldManager: h.fieldManager})
+		if err == nil || attempt >= h.retries || !isRetryableCreateError(err) {
+			return result, wrapNoSessionError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, wrapNoSessionError(err)
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
 
-	result, err := h.dynamicClient.Resource(resourceObj.GetGroupVersionResource()).
-		Create(ctx, &unstructured.Unstructured{Object: obj}, metav1.CreateOptions{})
+// Converts an object to unstructured form, filling in the apiVersion and kind,
+// so that callers only need to supply the object.
+func toUnstructured(resourceObj resource.Object) (*unstructured.Unstructured, error) {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(resourceObj)
 	if err != nil {
+		return nil, err
+	}
+
+	u := &unstructured.Unstructured{Object: obj}
+	if u.GetKind() == "" {
+		gvks, _, err := v1alpha1.NewScheme().ObjectKinds(resourceObj)
+		if err != nil {
+			return nil, err
+		}
+		u.SetGroupVersionKind(gvks[0])
+	}
+	return u, nil
+}
+
+// Whether a create error is likely transient, i.e., the server was unreachable
+// or failed internally. Errors in the request itself are never retried.
+func isRetryableCreateError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err) {
+		return true
+	}
+
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		return status.Status().Code >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// An error in how the command was invoked, rather than from the tilt session.
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string { return e.err.Error() }
+func (e usageError) Unwrap() error { return e.err }
+
+func usageErrorf(format string, args ...interface{}) error {
+	return usageError{err: fmt.Errorf(format, args...)}
+}
+
+// The categories of errors in --error-format=json.
+const (
+	errorKindValidation = "validation"
+	errorKindConnection = "connection"
+	errorKindConflict   = "conflict"
+	errorKindUnknown    = "unknown"
+)
+
+// The JSON object printed for an error with --error-format=json.
+type errorEnvelope struct {
+	Kind string `json:"kind"`
+
+	// The HTTP status code from the tilt session, if the error came from there.
+	Code int32 `json:"code,omitempty"`
+
+	Message string `json:"message"`
+}
+
+// The exit codes of a failed command, by the category of its error.
+const (
+	exitCodeUnknown    = 1
+	exitCodeValidation = 2
+	exitCodeConflict   = 3
+	exitCodeConnection = 4
+	exitCodeTimeout    = 5
+)
+
+// Categorizes an error as an exit code, so that scripts can tell failures apart.
+// Timeouts get their own code, though errorKind counts them as connection errors.
+func exitCode(err error) int {
+	var timeout timeoutError
+	var waitTimeout waitTimeoutError
+	if errors.As(err, &timeout) || errors.As(err, &waitTimeout) {
+		return exitCodeTimeout
+	}
+
+	switch errorKind(err) {
+	case errorKindValidation:
+		return exitCodeValidation
+	case errorKindConflict:
+		return exitCodeConflict
+	case errorKindConnection:
+		return exitCodeConnection
+	default:
+		return exitCodeUnknown
+	}
+}
+
+// Sets the exit code of a failed command to the one for its error's category.
+func withExitCode(err error) error {
+	if err == nil {
+		return nil
+	}
+	return exitCodeError{code: exitCode(err), err: err}
+}
+
+// Reports a failed command on the helper's streams, and sets its exit code.
+//
+// The error is printed here, rather than by the caller, so that all of the
+// command's output goes to its streams, and embedders can capture it.
+func (h *createHelper) fail(err error) error {
+	err = h.reportError(err)
+	if err == nil {
+		return nil
+	}
+	var printed printedError
+	if !errors.As(err, &printed) {
+		_, _ = fmt.Fprintf(h.streams.ErrOut, "Error: %s\n", h.errorMessage(err))
+		err = printedError{err: err}
+	}
+	return withExitCode(err)
+}
+
+// The message of an error, without its hints if --quiet-errors is set.
+func (h *createHelper) errorMessage(err error) string {
+	if !h.quietErrors {
+		return err.Error()
+	}
+
+	// Just the connection error, instead of the advice to run 'tilt up'.
+	var noSession noSessionError
+	if errors.As(err, &noSession) {
+		err = noSession.err
+	}
+	msg, _, _ := strings.Cut(err.Error(), "\n")
+	return strings.TrimSpace(errorHintPattern.ReplaceAllString(msg, ""))
+}
+
+// Matches the hints that errors end with to suggest a flag,
+// e.g., "(use --allow-missing to watch them anyway)".
+var errorHintPattern = regexp.MustCompile(` \((?:use|see|pass) [^()]*\)`)
+
+// Reports a failed command in the --error-format.
+//
+// With json, prints the error to stderr, and returns an error that
+// won't be printed again. Otherwise, returns the error for the usual
+// plain text output.
+func (h *createHelper) reportError(err error) error {
+	if err == nil || h.errorFormat != errorFormatJSON {
+		return err
+	}
+
+	envelope := errorEnvelope{Kind: errorKind(err), Message: h.errorMessage(err)}
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		envelope.Code = status.Status().Code
+	}
+
+	data, marshalErr := json.Marshal(envelope)
+	if marshalErr != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(h.streams.ErrOut, "%s\n", data)
+	return printedError{err: err}
+}
+
+// Categorizes an error, using the tilt session's status where possible.
+func errorKind(err error) string {
+	var usage usageError
+	var noSession noSessionError
+	var timeout timeoutError
+	switch {
+	case errors.As(err, &usage),
+		apierrors.IsInvalid(err),
+		apierrors.IsBadRequest(err):
+		return errorKindValidation
+	case errors.As(err, &noSession),
+		errors.As(err, &timeout),
+		utilnet.IsConnectionRefused(err),
+		utilnet.IsConnectionReset(err),
+		apierrors.IsServiceUnavailable(err),
+		apierrors.IsServerTimeout(err),
+		apierrors.IsTimeout(err):
+		return errorKindConnection
+	case apierrors.IsAlreadyExists(err),
+		apierrors.IsConflict(err):
+		return errorKindConflict
+	default:
+		return errorKindUnknown
+	}
+}
+
+// Returned when nothing is listening where we expect the tilt session,
+// usually because tilt isn't running.
+type noSessionError struct {
+	port int
+	err  error
+}
+
+func (e noSessionError) Error() string {
+	return fmt.Sprintf("no running Tilt session found on port %d; start Tilt with `tilt up` first\n(%v)", e.port, e.err)
+}
+
+func (e noSessionError) Unwrap() error {
+	return e.err
+}
+
+// Explains connection-refused errors in terms of the tilt session.
+// All other errors are returned unchanged.
+func wrapNoSessionError(err error) error {
+	var already noSessionError
+	if err == nil || !utilnet.IsConnectionRefused(err) || errors.As(err, &already) {
 		return err
 	}
 
+	port := webPortFlag
+	if apiPortFlag != 0 {
+		port = apiPortFlag
+	}
+	return noSessionError{port: port, err: err}
+}
+
+// Prints a created object.
+func (h *createHelper) print(result *unstructured.Unstructured) error {
+	if h.quiet {
+		_, err := fmt.Fprintln(h.streams.Out, result.GetName())
+		return err
+	}
+	if h.nameOnlyOnSuccess {
+		err := h.printer.PrintObj(result, h.streams.ErrOut)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(h.streams.Out, result.GetName())
+		return err
+	}
 	return h.printer.PrintObj(result, h.streams.Out)
 }
 
+// Changes the operation that the printer reports, e.g., "updated" instead of "created".
+//
+// Marks the operation as a dry run if --dry-run is set.
+func (h *createHelper) setOperation(operation string) error {
+	operation = strings.TrimSuffix(operation, " (dry run)")
+	if h.dryRun == dryRunClient {
+		operation += " (dry run)"
+	}
+	h.printFlags.NamePrintFlags.Operation = operation
+	printer, err := h.toPrinter()
+	if err != nil {
+		return err
+	}
+	h.printer = printer
+	return nil
+}
+
+// Extends the standard printers with the formats that only tilt supports.
+func (h *createHelper) toPrinter() (printers.ResourcePrinter, error) {
+	if h.printFlags.OutputFormat != nil && *h.printFlags.OutputFormat == "jsonl" {
+		return jsonLinesPrinter{}, nil
+	}
+	if h.printFlags.OutputFormat != nil && *h.printFlags.OutputFormat == "wide" && h.widePrinter != nil {
+		return h.widePrinter, nil
+	}
+	return h.printFlags.ToPrinter()
+}
+
+// Prints each object as a single line of compact JSON, for piping into tools like jq.
+type jsonLinesPrinter struct{}
+
+func (jsonLinesPrinter) PrintObj(obj runtime.Object, w io.Writer) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
 // Loads a dynamically typed tilt client.
-func (h *createHelper) createDynamicClient(ctx context.Context) (dynamic.Interface, error) {
-	getter, err := wireClientGetter(ctx)
+func newDynamicClient(ctx context.Context) (dynamic.Interface, error) {
+	config, err := newRESTConfig(ctx)
 	if err != nil {
 		return nil, err
 	}
+	return dynamic.NewForConfig(config)
+}
+
+// Loads the config for connecting to the tilt API server.
+//
+// With --api-port, connects to the API server on that port, rather than
+// the session registered for the web port. With --kube-context, connects
+// with that context of the user's kubeconfig instead. The --ca-cert,
+// --client-cert, --client-key, and --token flags override the registered
+// credentials.
+func newRESTConfig(ctx context.Context) (*rest.Config, error) {
+	var config *rest.Config
+	if apiKubeContextFlag != "" && apiPortFlag != 0 {
+		return nil, fmt.Errorf("--kube-context and --api-port can't be used together")
+	}
+	if apiKubeContextFlag != "" {
+		c, err := kubeContextConfig(apiKubeContextFlag, clientcmd.NewDefaultClientConfigLoadingRules())
+		if err != nil {
+			return nil, err
+		}
+		logger.Get(ctx).Debugf("Connecting to Tilt API server at %s (from --kube-context=%s)", c.Host, apiKubeContextFlag)
+		config = c
+	} else if apiPortFlag != 0 {
+		c, err := apiServerConfigForPort(apiHostFlag, apiPortFlag)
+		if err != nil {
+			return nil, err
+		}
+		logger.Get(ctx).Debugf("Connecting to Tilt API server at %s (from --api-port)", c.Host)
+		config = c
+	} else {
+		getter, err := wireClientGetter(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c, err := getter.ToRESTConfig()
+		if err != nil {
+			return nil, err
+		}
+		config = c
+	}
 
-	config, err := getter.ToRESTConfig()
+	err := applyAPIAuthOverrides(ctx, config, apiAuthOverrides{
+		caCert:     apiCACertFlag,
+		clientCert: apiClientCertFlag,
+		clientKey:  apiClientKeyFlag,
+		token:      apiTokenFlag,
+	})
 	if err != nil {
 		return nil, err
 	}
-	return dynamic.NewForConfig(config)
+	return config, nil
+}
+
+// Builds a REST config from the named context of the kubeconfig found
+// by the loading rules (usually $KUBECONFIG or ~/.kube/config).
+func kubeContextConfig(name string, rules *clientcmd.ClientConfigLoadingRules) (*rest.Config, error) {
+	loader := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules,
+		&clientcmd.ConfigOverrides{CurrentContext: name})
+	config, err := loader.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("--kube-context %s: %v", name, err)
+	}
+	return config, nil
+}
+
+// TLS and auth settings that replace the ones the session registered.
+// Empty fields leave the registered setting alone.
+type apiAuthOverrides struct {
+	caCert     string
+	clientCert string
+	clientKey  string
+	token      string
+}
+
+// Applies the overrides onto config.
+//
+// Certificates are read up front, so that a bad path is reported as a flag
+// error rather than a connection error. The token is never logged.
+func applyAPIAuthOverrides(ctx context.Context, config *rest.Config, o apiAuthOverrides) error {
+	if (o.clientCert == "") != (o.clientKey == "") {
+		return fmt.Errorf("--client-cert and --client-key must be set together")
+	}
+
+	applied := []string{}
+	if o.caCert != "" {
+		data, err := os.ReadFile(o.caCert)
+		if err != nil {
+			return fmt.Errorf("reading --ca-cert: %v", err)
+		}
+		config.TLSClientConfig.CAFile = ""
+		config.TLSClientConfig.CAData = data
+		config.TLSClientConfig.Insecure = false
+		applied = append(applied, fmt.Sprintf("--ca-cert=%s", o.caCert))
+	}
+
+	if o.clientCert != "" {
+		certData, err := os.ReadFile(o.clientCert)
+		if err != nil {
+			return fmt.Errorf("reading --client-cert: %v", err)
+		}
+		keyData, err := os.ReadFile(o.clientKey)
+		if err != nil {
+			return fmt.Errorf("reading --client-key: %v", err)
+		}
+		config.TLSClientConfig.CertFile = ""
+		config.TLSClientConfig.CertData = certData
+		config.TLSClientConfig.KeyFile = ""
+		config.TLSClientConfig.KeyData = keyData
+		applied = append(applied, fmt.Sprintf("--client-cert=%s", o.clientCert), "--client-key=<redacted>")
+	}
+
+	if o.token != "" {
+		config.BearerToken = o.token
+		config.BearerTokenFile = ""
+		applied = append(applied, "--token=<redacted>")
+	}
+
+	if len(applied) > 0 {
+		logger.Get(ctx).Debugf("Overriding Tilt API server credentials with %s", strings.Join(applied, " "))
+	}
+	return nil
+}
+
+// Builds a REST config for the API server listening on the given port.
+//
+// The server's token and certificate are only stored in the tilt config,
+// so we still look there for the session that registered this port.
+// If host is set, it replaces the registered host.
+func apiServerConfigForPort(host string, port int) (*rest.Config, error) {
+	if port < 0 || port > 65535 {
+		return nil, fmt.Errorf("--api-port must be between 0 and 65535, got %d", port)
+	}
+
+	dir, err := dirs.UseTiltDevDir()
+	if err != nil {
+		return nil, err
+	}
+
+	configAccess := server.ProvideConfigAccess(dir)
+	var config *clientcmdapi.Config
+	err = filelock.WithRLock(configAccess, func() error {
+		var e error
+		config, e = configAccess.GetStartingConfig()
+		return e
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(config.Clusters))
+	for name := range config.Clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	portString := strconv.Itoa(port)
+	for _, name := range names {
+		cluster := config.Clusters[name]
+		authInfo, ok := config.AuthInfos[name]
+		if !ok {
+			continue
+		}
+
+		serverURL, err := url.Parse(cluster.Server)
+		if err != nil || serverURL.Port() != portString {
+			continue
+		}
+
+		result := &rest.Config{
+			Host:        cluster.Server,
+			BearerToken: authInfo.Token,
+			TLSClientConfig: rest.TLSClientConfig{
+				CAData: cluster.CertificateAuthorityData,
+			},
+		}
+		if host != "" {
+			result.Host = fmt.Sprintf("%s://%s", serverURL.Scheme, net.JoinHostPort(host, portString))
+
+			// The certificate is still issued for the registered host.
+			result.TLSClientConfig.ServerName = serverURL.Hostname()
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("No tilt apiserver found on port %d", port)
 }
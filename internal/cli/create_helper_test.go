@@ -0,0 +1,452 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+func TestCreateHelperCreate(t *testing.T) {
+	f := newCreateHelperFixture(t)
+
+	err := f.helper.create(context.Background(), f.fileWatch("my-watch"))
+	require.NoError(t, err)
+	assert.Equal(t, "filewatch.tilt.dev/my-watch created\n", f.out.String())
+
+	obj, err := f.client.Resource(v1alpha1.SchemeGroupVersion.WithResource("filewatches")).
+		Get(context.Background(), "my-watch", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	var fw v1alpha1.FileWatch
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &fw)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/src"}, fw.Spec.WatchedPaths)
+}
+
+func TestCreateHelperQuiet(t *testing.T) {
+	f := newCreateHelperFixture(t)
+	f.helper.quiet = true
+
+	err := f.helper.create(context.Background(), f.fileWatch("my-watch"))
+	require.NoError(t, err)
+	assert.Equal(t, "my-watch\n", f.out.String())
+}
+
+func TestCreateHelperCreatedVerb(t *testing.T) {
+	f := newCreateHelperFixture(t)
+	f.helper.createdVerb = "registered"
+	require.NoError(t, f.helper.setOperation(f.helper.createdVerb))
+
+	err := f.helper.create(context.Background(), f.fileWatch("my-watch"))
+	require.NoError(t, err)
+	assert.Equal(t, "filewatch.tilt.dev/my-watch registered\n", f.out.String())
+}
+
+func TestCreateHelperJSONLines(t *testing.T) {
+	f := newCreateHelperFixture(t)
+	*f.helper.printFlags.OutputFormat = "jsonl"
+	printer, err := f.helper.toPrinter()
+	require.NoError(t, err)
+	f.helper.printer = printer
+
+	err = f.helper.create(context.Background(), f.fileWatch("my-watch"))
+	require.NoError(t, err)
+	err = f.helper.create(context.Background(), f.fileWatch("other-watch"))
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(f.out.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"name":"my-watch"`)
+	assert.Contains(t, lines[1], `"name":"other-watch"`)
+}
+
+func TestCreateHelperAlreadyExists(t *testing.T) {
+	f := newCreateHelperFixture(t)
+
+	err := f.helper.create(context.Background(), f.fileWatch("my-watch"))
+	require.NoError(t, err)
+	f.out.Reset()
+
+	err = f.helper.create(context.Background(), f.fileWatch("my-watch"))
+	assert.True(t, apierrors.IsAlreadyExists(err), "expected AlreadyExists, got: %v", err)
+	assert.Equal(t, "", f.out.String())
+}
+
+func TestCreateHelperRetries(t *testing.T) {
+	f := newCreateHelperFixture(t)
+	attempts := 0
+	f.client.PrependReactor("create", "filewatches", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		attempts++
+		if attempts <= 2 {
+			return true, nil, apierrors.NewServiceUnavailable("starting up")
+		}
+		return false, nil, nil
+	})
+
+	err := f.helper.create(context.Background(), f.fileWatch("my-watch"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, "filewatch.tilt.dev/my-watch created\n", f.out.String())
+}
+
+func TestCreateHelperRetriesExhausted(t *testing.T) {
+	f := newCreateHelperFixture(t)
+	f.helper.retries = 2
+	attempts := 0
+	f.client.PrependReactor("create", "filewatches", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		attempts++
+		return true, nil, apierrors.NewInternalError(fmt.Errorf("boom"))
+	})
+
+	err := f.helper.create(context.Background(), f.fileWatch("my-watch"))
+	assert.True(t, apierrors.IsInternalError(err), "expected InternalError, got: %v", err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestCreateHelperDoesNotRetryInvalid(t *testing.T) {
+	f := newCreateHelperFixture(t)
+	attempts := 0
+	f.client.PrependReactor("create", "filewatches", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		attempts++
+		return true, nil, apierrors.NewBadRequest("watchedPaths cannot be empty")
+	})
+
+	err := f.helper.create(context.Background(), f.fileWatch("my-watch"))
+	assert.True(t, apierrors.IsBadRequest(err), "expected BadRequest, got: %v", err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestCreateHelperRetryCanceled(t *testing.T) {
+	f := newCreateHelperFixture(t)
+	f.helper.retryBackoff = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f.client.PrependReactor("create", "filewatches", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		cancel()
+		return true, nil, apierrors.NewServiceUnavailable("starting up")
+	})
+
+	err := f.helper.create(ctx, f.fileWatch("my-watch"))
+	assert.True(t, apierrors.IsServiceUnavailable(err), "expected ServiceUnavailable, got: %v", err)
+}
+
+func TestCreateHelperNoSession(t *testing.T) {
+	f := newCreateHelperFixture(t)
+	refused := &net.OpError{Op: "dial", Err: &os.SyscallError{Syscall: "connect", Err: syscall.ECONNREFUSED}}
+	f.client.PrependReactor("create", "filewatches", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, refused
+	})
+
+	origPort := webPortFlag
+	webPortFlag = 10351
+	t.Cleanup(func() { webPortFlag = origPort })
+
+	err := f.helper.create(context.Background(), f.fileWatch("my-watch"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no running Tilt session found on port 10351; start Tilt with `tilt up` first")
+	assert.Equal(t, refused, errors.Unwrap(err))
+	assert.True(t, errors.Is(err, syscall.ECONNREFUSED))
+}
+
+func TestWrapNoSessionError(t *testing.T) {
+	assert.NoError(t, wrapNoSessionError(nil))
+
+	invalid := apierrors.NewBadRequest("watchedPaths cannot be empty")
+	assert.Equal(t, invalid, wrapNoSessionError(invalid))
+
+	refused := &net.OpError{Op: "dial", Err: &os.SyscallError{Syscall: "connect", Err: syscall.ECONNREFUSED}}
+	wrapped := wrapNoSessionError(refused)
+	assert.Equal(t, wrapped, wrapNoSessionError(wrapped), "should not wrap twice")
+}
+
+func TestIsRetryableCreateError(t *testing.T) {
+	gr := v1alpha1.Resource("filewatches")
+	for _, tc := range []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{"nil", nil, false},
+		{"connection refused", &net.OpError{Op: "dial", Err: &os.SyscallError{Syscall: "connect", Err: syscall.ECONNREFUSED}}, true},
+		{"wrapped connection refused", fmt.Errorf("post: %w", &net.OpError{Op: "dial", Err: &os.SyscallError{Syscall: "connect", Err: syscall.ECONNREFUSED}}), true},
+		{"internal error", apierrors.NewInternalError(fmt.Errorf("boom")), true},
+		{"service unavailable", apierrors.NewServiceUnavailable("starting up"), true},
+		{"already exists", apierrors.NewAlreadyExists(gr, "my-watch"), false},
+		{"invalid", apierrors.NewInvalid(v1alpha1.SchemeGroupVersion.WithKind("FileWatch").GroupKind(), "my-watch", nil), false},
+		{"bad request", apierrors.NewBadRequest("bad"), false},
+		{"other", fmt.Errorf("something else"), false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.retryable, isRetryableCreateError(tc.err))
+		})
+	}
+}
+
+type createHelperFixture struct {
+	helper *createHelper
+	client *dynamicfake.FakeDynamicClient
+	out    *bytes.Buffer
+}
+
+func newCreateHelperFixture(t *testing.T) *createHelperFixture {
+	out := bytes.NewBuffer(nil)
+	helper := newCreateHelper(genericclioptions.IOStreams{Out: out})
+	helper.retryBackoff = time.Millisecond
+
+	printer, err := helper.printFlags.ToPrinter()
+	require.NoError(t, err)
+	helper.printer = printer
+
+	client := dynamicfake.NewSimpleDynamicClient(v1alpha1.NewScheme())
+	helper.dynamicClient = client
+
+	return &createHelperFixture{
+		helper: helper,
+		client: client,
+		out:    out,
+	}
+}
+
+func (f *createHelperFixture) fileWatch(name string) *v1alpha1.FileWatch {
+	return &v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1alpha1.FileWatchSpec{
+			WatchedPaths: []string{"/src"},
+		},
+	}
+}
+
+func TestErrorKind(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Err: &os.SyscallError{Syscall: "connect", Err: syscall.ECONNREFUSED}}
+	gr := (&v1alpha1.FileWatch{}).GetGroupVersionResource().GroupResource()
+
+	assert.Equal(t, errorKindValidation, errorKind(usageErrorf("bad flag")))
+	assert.Equal(t, errorKindValidation, errorKind(apierrors.NewBadRequest("watchedPaths cannot be empty")))
+	assert.Equal(t, errorKindConnection, errorKind(refused))
+	assert.Equal(t, errorKindConnection, errorKind(wrapNoSessionError(refused)))
+	assert.Equal(t, errorKindConnection, errorKind(apierrors.NewServiceUnavailable("starting")))
+	assert.Equal(t, errorKindConflict, errorKind(apierrors.NewAlreadyExists(gr, "my-watch")))
+	assert.Equal(t, errorKindConflict, errorKind(apierrors.NewConflict(gr, "my-watch", fmt.Errorf("stale"))))
+	assert.Equal(t, errorKindUnknown, errorKind(fmt.Errorf("oops")))
+}
+
+func TestReportErrorJSON(t *testing.T) {
+	errOut := bytes.NewBuffer(nil)
+	h := newCreateHelper(genericclioptions.IOStreams{ErrOut: errOut})
+	h.errorFormat = errorFormatJSON
+
+	gr := (&v1alpha1.FileWatch{}).GetGroupVersionResource().GroupResource()
+	exists := apierrors.NewAlreadyExists(gr, "my-watch")
+	err := h.reportError(exists)
+	require.Error(t, err)
+	var printed printedError
+	assert.True(t, errors.As(err, &printed))
+	assert.True(t, apierrors.IsAlreadyExists(err))
+	assert.Equal(t,
+		`{"kind":"conflict","code":409,"message":"filewatches.tilt.dev \"my-watch\" already exists"}`+"\n",
+		errOut.String())
+
+	errOut.Reset()
+	err = h.reportError(fmt.Errorf("oops"))
+	require.Error(t, err)
+	assert.Equal(t, `{"kind":"unknown","message":"oops"}`+"\n", errOut.String())
+
+	assert.NoError(t, h.reportError(nil))
+}
+
+func TestReportErrorText(t *testing.T) {
+	errOut := bytes.NewBuffer(nil)
+	h := newCreateHelper(genericclioptions.IOStreams{ErrOut: errOut})
+
+	orig := fmt.Errorf("oops")
+	assert.Equal(t, orig, h.reportError(orig))
+	assert.Empty(t, errOut.String())
+}
+
+func TestQuietErrorMessage(t *testing.T) {
+	h := newCreateHelper(genericclioptions.IOStreams{})
+	h.quietErrors = true
+
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	assert.Equal(t, refused.Error(), h.errorMessage(noSessionError{port: 10350, err: refused}))
+	assert.Equal(t, `path "$SRC" uses undefined environment variables: $SRC`,
+		h.errorMessage(fmt.Errorf(`path "$SRC" uses undefined environment variables: $SRC (use --no-expand to use it as is)`)))
+	assert.Equal(t, "oops", h.errorMessage(fmt.Errorf("oops")))
+}
+
+func TestExitCode(t *testing.T) {
+	gr := (&v1alpha1.FileWatch{}).GetGroupVersionResource().GroupResource()
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	for _, tc := range []struct {
+		err      error
+		expected int
+	}{
+		{fmt.Errorf("oops"), exitCodeUnknown},
+		{usageErrorf("--timeout must not be negative, got -1s"), exitCodeValidation},
+		{apierrors.NewBadRequest("bad"), exitCodeValidation},
+		{apierrors.NewAlreadyExists(gr, "my-watch"), exitCodeConflict},
+		{apierrors.NewConflict(gr, "my-watch", fmt.Errorf("changed")), exitCodeConflict},
+		{noSessionError{port: 10350, err: refused}, exitCodeConnection},
+		{apierrors.NewServiceUnavailable("starting"), exitCodeConnection},
+		{timeoutError{timeout: time.Second, err: refused}, exitCodeTimeout},
+		{waitTimeoutError{timeout: time.Second, name: "my-watch"}, exitCodeTimeout},
+		{printedError{err: apierrors.NewAlreadyExists(gr, "my-watch")}, exitCodeConflict},
+	} {
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			assert.Equal(t, tc.expected, exitCode(tc.err))
+
+			var coded exitCodeError
+			err := withExitCode(tc.err)
+			require.True(t, errors.As(err, &coded))
+			assert.Equal(t, tc.expected, coded.code)
+			assert.Equal(t, tc.err.Error(), err.Error())
+		})
+	}
+	assert.NoError(t, withExitCode(nil))
+}
+
+func TestApplyAPIAuthOverrides(t *testing.T) {
+	dir := t.TempDir()
+	for name, contents := range map[string]string{"ca.crt": "ca", "client.crt": "cert", "client.key": "key"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0600))
+	}
+
+	out := bytes.NewBuffer(nil)
+	ctx := logger.WithLogger(context.Background(), logger.NewTestLogger(out))
+	config := &rest.Config{
+		BearerTokenFile: "/registered/token",
+		TLSClientConfig: rest.TLSClientConfig{
+			CAFile:   "/registered/ca.crt",
+			CertData: []byte("registered-cert"),
+			KeyData:  []byte("registered-key"),
+		},
+	}
+	err := applyAPIAuthOverrides(ctx, config, apiAuthOverrides{
+		caCert:     filepath.Join(dir, "ca.crt"),
+		clientCert: filepath.Join(dir, "client.crt"),
+		clientKey:  filepath.Join(dir, "client.key"),
+		token:      "s3cret",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "", config.CAFile)
+	assert.Equal(t, []byte("ca"), config.CAData)
+	assert.Equal(t, []byte("cert"), config.CertData)
+	assert.Equal(t, []byte("key"), config.KeyData)
+	assert.Equal(t, "s3cret", config.BearerToken)
+	assert.Equal(t, "", config.BearerTokenFile)
+
+	assert.Contains(t, out.String(), "--token=<redacted>")
+	assert.NotContains(t, out.String(), "s3cret")
+}
+
+func TestApplyAPIAuthOverridesNone(t *testing.T) {
+	config := &rest.Config{BearerToken: "registered"}
+	err := applyAPIAuthOverrides(context.Background(), config, apiAuthOverrides{})
+	require.NoError(t, err)
+	assert.Equal(t, &rest.Config{BearerToken: "registered"}, config)
+}
+
+func TestApplyAPIAuthOverridesErrors(t *testing.T) {
+	dir := t.TempDir()
+	cert := filepath.Join(dir, "client.crt")
+	require.NoError(t, os.WriteFile(cert, []byte("cert"), 0600))
+
+	for _, tc := range []struct {
+		name      string
+		overrides apiAuthOverrides
+		expected  string
+	}{
+		{"cert without key", apiAuthOverrides{clientCert: cert}, "--client-cert and --client-key must be set together"},
+		{"key without cert", apiAuthOverrides{clientKey: cert}, "--client-cert and --client-key must be set together"},
+		{"missing ca", apiAuthOverrides{caCert: filepath.Join(dir, "missing.crt")}, "reading --ca-cert"},
+		{"missing key", apiAuthOverrides{clientCert: cert, clientKey: filepath.Join(dir, "missing.key")}, "reading --client-key"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := applyAPIAuthOverrides(context.Background(), &rest.Config{}, tc.overrides)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+current-context: default
+clusters:
+- name: default
+  cluster:
+    server: https://default.example.com
+- name: tilt
+  cluster:
+    server: https://tilt.example.com:10350
+contexts:
+- name: default
+  context:
+    cluster: default
+    user: default
+- name: tilt
+  context:
+    cluster: tilt
+    user: tilt
+users:
+- name: default
+  user:
+    token: default-token
+- name: tilt
+  user:
+    token: tilt-token
+`
+
+func TestNewRESTConfigKubeContext(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(testKubeconfig), 0600))
+	t.Setenv("KUBECONFIG", kubeconfig)
+	apiKubeContextFlag = "tilt"
+	t.Cleanup(func() { apiKubeContextFlag = "" })
+
+	ctx := logger.WithLogger(context.Background(), logger.NewTestLogger(bytes.NewBuffer(nil)))
+	config, err := newRESTConfig(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "https://tilt.example.com:10350", config.Host)
+	assert.Equal(t, "tilt-token", config.BearerToken)
+}
+
+func TestNewRESTConfigKubeContextErrors(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(testKubeconfig), 0600))
+	t.Setenv("KUBECONFIG", kubeconfig)
+	t.Cleanup(func() {
+		apiKubeContextFlag = ""
+		apiPortFlag = 0
+	})
+
+	apiKubeContextFlag = "nope"
+	_, err := newRESTConfig(context.Background())
+	assert.EqualError(t, err, `--kube-context nope: context "nope" does not exist`)
+
+	apiPortFlag = 10351
+	_, err = newRESTConfig(context.Background())
+	assert.EqualError(t, err, "--kube-context and --api-port can't be used together")
+}
@@ -66,6 +66,8 @@ func (c *deleteCmd) register() *cobra.Command {
 
 	addConnectServerFlags(cmd)
 
+	addCommand(cmd, newDeleteFileWatchCmd(c.streams))
+
 	return cmd
 }
 
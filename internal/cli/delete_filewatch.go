@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	engineanalytics "github.com/tilt-dev/tilt/internal/engine/analytics"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// A human-friendly CLI for deleting file watches.
+//
+// The counterpart of createFileWatchCmd. It's registered as the filewatch
+// type of tilt delete, so it takes the same ways of picking FileWatches
+// as the generic command: names, a label selector, or --all.
+type deleteFileWatchCmd struct {
+	// Connects to the tilt session and reports errors, like it does for create.
+	helper     *createHelper
+	printFlags *genericclioptions.PrintFlags
+
+	ignoreNotFound bool
+	selector       string
+	all            bool
+	wait           bool
+}
+
+var _ tiltCmd = &deleteFileWatchCmd{}
+
+// How often --wait checks whether the deleted FileWatches are gone.
+const deleteFileWatchWaitInterval = 100 * time.Millisecond
+
+func newDeleteFileWatchCmd(streams genericclioptions.IOStreams) *deleteFileWatchCmd {
+	return &deleteFileWatchCmd{
+		helper:     newCreateHelper(streams),
+		printFlags: genericclioptions.NewPrintFlags("deleted"),
+	}
+}
+
+func (c *deleteFileWatchCmd) name() model.TiltSubcommand { return "delete" }
+
+func (c *deleteFileWatchCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "filewatch (NAME... | -l label | --all)",
+		DisableFlagsInUseLine: true,
+		Short:                 "Delete filewatches in a running tilt session",
+		Long: `Delete FileWatches in a running tilt session.
+
+Deletes the FileWatches with the given names, the ones with labels
+matching --selector, or with --all, every FileWatch.
+
+Errors are printed to stderr, with the same --error-format, --quiet-errors,
+and exit codes as 'tilt create filewatch'. See it for how to create one.
+`,
+		Aliases:           []string{"fw", "filewatches"},
+		ValidArgsFunction: completeFileWatchNames(cobra.ShellCompDirectiveNoFileComp),
+		Example: `tilt delete fw src-and-web
+
+tilt delete fw src web
+
+tilt delete fw -l ephemeral=true`,
+	}
+
+	cmd.Flags().BoolVar(&c.ignoreNotFound, "ignore-not-found", false,
+		"If a FileWatch does not exist, exit successfully instead of returning an error.")
+	cmd.Flags().StringVarP(&c.selector, "selector", "l", "",
+		"Selector (label query) to filter on, supports '=', '==', '!=', 'in', 'notin', and 'exists'. (e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().BoolVar(&c.all, "all", false,
+		"Delete all FileWatches.")
+	cmd.Flags().BoolVar(&c.wait, "wait", true,
+		"If true, wait for the FileWatches to be gone before returning.")
+
+	c.printFlags.AddFlags(cmd)
+	c.helper.addErrorFormatFlag(cmd)
+	addConnectServerFlags(cmd)
+	addAPIServerFlags(cmd)
+
+	return cmd
+}
+
+func (c *deleteFileWatchCmd) run(ctx context.Context, args []string) error {
+	a := analytics.Get(ctx)
+	cmdTags := engineanalytics.CmdTags(map[string]string{})
+	a.Incr("cmd.delete-filewatch", cmdTags.AsMap())
+	defer a.Flush(time.Second)
+
+	return c.helper.fail(c.delete(ctx, args))
+}
+
+// Checks the flags, and deletes the FileWatches they pick.
+func (c *deleteFileWatchCmd) delete(ctx context.Context, args []string) error {
+	err := c.helper.checkErrorFormat()
+	if err != nil {
+		return err
+	}
+	switch {
+	case c.all && c.selector != "":
+		return usageErrorf("--all and --selector can't be used together")
+	case (c.all || c.selector != "") && len(args) > 0:
+		return usageErrorf("FileWatch names can't be used with --all or --selector")
+	case !c.all && c.selector == "" && len(args) == 0:
+		return usageErrorf("no FileWatch name, --selector, or --all specified")
+	}
+	if c.selector != "" {
+		_, err := labels.Parse(c.selector)
+		if err != nil {
+			return usageErrorf("invalid --selector %q: %v", c.selector, err)
+		}
+	}
+
+	printer, err := c.printFlags.ToPrinter()
+	if err != nil {
+		return usageError{err: err}
+	}
+
+	err = c.helper.connect(ctx)
+	if err != nil {
+		return err
+	}
+	client := c.helper.dynamicClient.Resource((&v1alpha1.FileWatch{}).GetGroupVersionResource())
+
+	names := args
+	ignoreNotFound := c.ignoreNotFound
+	if len(names) == 0 {
+		names, err = c.listNames(ctx, client)
+		if err != nil {
+			return wrapNoSessionError(err)
+		}
+		// A FileWatch that's deleted after the list is already gone.
+		ignoreNotFound = true
+	}
+
+	var deleted []string
+	var failures []string
+	var lastErr error
+	for _, name := range names {
+		err := client.Delete(ctx, name, metav1.DeleteOptions{})
+		if err != nil {
+			if ignoreNotFound && apierrors.IsNotFound(err) {
+				continue
+			}
+			lastErr = wrapNoSessionError(err)
+			failures = append(failures, fmt.Sprintf("  %s: %v", name, lastErr))
+			continue
+		}
+		deleted = append(deleted, name)
+
+		// The delete API doesn't return the object, so print a stub with enough
+		// type info to identify it.
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(v1alpha1.SchemeGroupVersion.WithKind("FileWatch"))
+		obj.SetName(name)
+		err = printer.PrintObj(obj, c.helper.streams.Out)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.wait {
+		err = waitForFileWatchesDeleted(ctx, client, deleted)
+		if err != nil {
+			return wrapNoSessionError(err)
+		}
+	}
+
+	if len(failures) == 1 && len(names) == 1 {
+		return lastErr
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to delete %d of %d FileWatches:\n%s",
+			len(failures), len(names), strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+// The names of the FileWatches that --selector or --all picks.
+func (c *deleteFileWatchCmd) listNames(ctx context.Context, client dynamic.ResourceInterface) ([]string, error) {
+	list, err := client.List(ctx, metav1.ListOptions{LabelSelector: c.selector})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		names = append(names, item.GetName())
+	}
+	return names, nil
+}
+
+// Waits until none of the FileWatches with the given names exist, e.g.,
+// because a finalizer is still cleaning one up.
+func waitForFileWatchesDeleted(ctx context.Context, client dynamic.ResourceInterface, names []string) error {
+	for _, name := range names {
+		for {
+			_, err := client.Get(ctx, name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("waiting for FileWatch %s to be deleted: %v", name, ctx.Err())
+			case <-time.After(deleteFileWatchWaitInterval):
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestDeleteFileWatch(t *testing.T) {
+	f := newServerFixture(t)
+
+	err := f.client.Create(f.ctx, &v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-watch"},
+		Spec: v1alpha1.FileWatchSpec{
+			WatchedPaths: []string{f.Path()},
+		},
+	})
+	require.NoError(t, err)
+
+	out := bytes.NewBuffer(nil)
+	streams := genericclioptions.IOStreams{Out: out}
+
+	cmd := newDeleteFileWatchCmd(streams)
+	c := cmd.register()
+	err = c.Flags().Parse([]string{"my-watch"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), `filewatch.tilt.dev/my-watch deleted`)
+
+	var fw v1alpha1.FileWatch
+	err = f.client.Get(f.ctx, types.NamespacedName{Name: "my-watch"}, &fw)
+	if assert.Error(t, err) {
+		assert.True(t, apierrors.IsNotFound(err))
+	}
+}
+
+func TestDeleteFileWatchNotFound(t *testing.T) {
+	f := newServerFixture(t)
+
+	out := bytes.NewBuffer(nil)
+	streams := genericclioptions.IOStreams{Out: out}
+
+	cmd := newDeleteFileWatchCmd(streams)
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"my-watch"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	if assert.Error(t, err) {
+		assert.True(t, apierrors.IsNotFound(err))
+	}
+
+	cmd = newDeleteFileWatchCmd(streams)
+	c = cmd.register()
+	err = c.Flags().Parse([]string{"--ignore-not-found", "my-watch"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, "", out.String())
+}
+
+func TestDeleteFileWatchMultipleNames(t *testing.T) {
+	f := newServerFixture(t)
+	f.createFileWatch("src")
+	f.createFileWatch("web")
+	f.createFileWatch("docs")
+
+	out := bytes.NewBuffer(nil)
+	cmd := newDeleteFileWatchCmd(genericclioptions.IOStreams{Out: out})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"src", "web"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, "filewatch.tilt.dev/src deleted\nfilewatch.tilt.dev/web deleted\n", out.String())
+	assert.Equal(t, []string{"docs"}, f.fileWatchNames())
+}
+
+func TestDeleteFileWatchMultipleNamesNotFound(t *testing.T) {
+	f := newServerFixture(t)
+	f.createFileWatch("src")
+
+	out := bytes.NewBuffer(nil)
+	cmd := newDeleteFileWatchCmd(genericclioptions.IOStreams{Out: out})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"missing", "src"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to delete 1 of 2 FileWatches:\n  missing: ")
+	assert.Equal(t, "filewatch.tilt.dev/src deleted\n", out.String())
+	assert.Empty(t, f.fileWatchNames())
+}
+
+func TestDeleteFileWatchSelector(t *testing.T) {
+	f := newServerFixture(t)
+	for name, ephemeral := range map[string]string{"tmp-watch": "true", "src-watch": "false"} {
+		err := f.client.Create(f.ctx, &v1alpha1.FileWatch{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"ephemeral": ephemeral}},
+			Spec:       v1alpha1.FileWatchSpec{WatchedPaths: []string{f.Path()}},
+		})
+		require.NoError(t, err)
+	}
+
+	out := bytes.NewBuffer(nil)
+	cmd := newDeleteFileWatchCmd(genericclioptions.IOStreams{Out: out})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"-l", "ephemeral=true"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, "filewatch.tilt.dev/tmp-watch deleted\n", out.String())
+	assert.Equal(t, []string{"src-watch"}, f.fileWatchNames())
+}
+
+func TestDeleteFileWatchAll(t *testing.T) {
+	f := newServerFixture(t)
+	f.createFileWatch("src")
+	f.createFileWatch("web")
+
+	cmd := newDeleteFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--all"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Empty(t, f.fileWatchNames())
+}
+
+func TestDeleteFileWatchArgsInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		args     []string
+		expected string
+	}{
+		{nil, "no FileWatch name, --selector, or --all specified"},
+		{[]string{"--all", "src"}, "FileWatch names can't be used with --all or --selector"},
+		{[]string{"-l", "a=b", "src"}, "FileWatch names can't be used with --all or --selector"},
+		{[]string{"--all", "-l", "a=b"}, "--all and --selector can't be used together"},
+	} {
+		cmd := newDeleteFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+		c := cmd.register()
+		err := c.Flags().Parse(tc.args)
+		require.NoError(t, err)
+
+		ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+		err = cmd.run(ctx, c.Flags().Args())
+		assert.EqualError(t, err, tc.expected, "args: %v", tc.args)
+	}
+}
+
+func TestDeleteFileWatchNoSession(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(v1alpha1.NewScheme())
+	refused := &net.OpError{Op: "dial", Err: &os.SyscallError{Syscall: "connect", Err: syscall.ECONNREFUSED}}
+	client.PrependReactor("delete", "filewatches", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, refused
+	})
+
+	origPort := webPortFlag
+	t.Cleanup(func() { webPortFlag = origPort })
+
+	cmd := newDeleteFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil)})
+	cmd.helper.dynamicClient = client
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--port=10351", "my-watch"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no running Tilt session found on port 10351; start Tilt with `tilt up` first")
+}
+
+func TestDeleteFileWatchErrorsStayInStreams(t *testing.T) {
+	stdout, stderr := captureStdio(t)
+
+	client := dynamicfake.NewSimpleDynamicClient(v1alpha1.NewScheme())
+	refused := &net.OpError{Op: "dial", Err: &os.SyscallError{Syscall: "connect", Err: syscall.ECONNREFUSED}}
+	client.PrependReactor("delete", "filewatches", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, refused
+	})
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	for _, tc := range []struct {
+		args     []string
+		expected string
+		code     int
+	}{
+		{nil, "Error: no FileWatch name, --selector, or --all specified\n", exitCodeValidation},
+		{[]string{"--quiet-errors", "my-watch"}, "Error: dial: connect: connection refused\n", exitCodeConnection},
+		{[]string{"--error-format=json", "--all", "my-watch"},
+			`{"kind":"validation","message":"FileWatch names can't be used with --all or --selector"}` + "\n", exitCodeValidation},
+	} {
+		errOut := bytes.NewBuffer(nil)
+		cmd := newDeleteFileWatchCmd(genericclioptions.IOStreams{Out: bytes.NewBuffer(nil), ErrOut: errOut})
+		cmd.helper.dynamicClient = client
+		c := cmd.register()
+		err := c.Flags().Parse(tc.args)
+		require.NoError(t, err)
+
+		err = cmd.run(ctx, c.Flags().Args())
+		require.Error(t, err)
+		assert.Equal(t, tc.code, exitCode(err), "args: %v", tc.args)
+		assert.Equal(t, tc.expected, errOut.String(), "args: %v", tc.args)
+	}
+
+	assert.Empty(t, stdout())
+	assert.Empty(t, stderr())
+}
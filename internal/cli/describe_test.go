@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -31,3 +32,23 @@ func TestDescribe(t *testing.T) {
 
 	assert.Contains(t, out.String(), `Name:         my-sleep`)
 }
+
+func TestDescribeFileWatchDescription(t *testing.T) {
+	f := newServerFixture(t)
+
+	out := bytes.NewBuffer(nil)
+	create := newCreateFileWatchCmd(genericclioptions.IOStreams{Out: out})
+	c := create.register()
+	err := c.Flags().Parse([]string{"--allow-missing", "--description", "Regenerates the API clients", "protos", "proto"})
+	require.NoError(t, err)
+	err = create.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	streams, _, describeOut, _ := genericclioptions.NewTestIOStreams()
+	describe := newDescribeCmd(streams)
+	describe.register()
+
+	err = describe.run(f.ctx, []string{"filewatch", "protos"})
+	require.NoError(t, err)
+	assert.Contains(t, describeOut.String(), `tilt.dev/description: Regenerates the API clients`)
+}
@@ -35,7 +35,10 @@ func (c *disableCmd) register() *cobra.Command {
 tilt disable frontend backend
 
 # disables all resources
-tilt disable --all`,
+tilt disable --all
+
+# disables the FileWatch named 'src', created with 'tilt create filewatch --disabled'
+tilt disable src`,
 	}
 
 	cmd.Flags().StringSliceVarP(&c.labels, "labels", "l", c.labels, "Disable all resources with the specified labels")
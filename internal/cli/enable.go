@@ -8,7 +8,10 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
@@ -45,6 +48,9 @@ tilt enable --only frontend backend
 
 # enables all resources
 tilt enable --all
+
+# enables the FileWatch named 'src', created with 'tilt create filewatch --disabled'
+tilt enable src
 `,
 	}
 
@@ -120,10 +126,21 @@ func changeEnabledResources(
 		uirByName[uir.Name] = uir
 	}
 	selectedResourcesByName := make(map[string]bool)
+	var selectedFileWatches []*v1alpha1.ConfigMapDisableSource
 	for _, name := range selectedResources {
 		uir, ok := uirByName[name]
 		if !ok {
-			return fmt.Errorf("no such resource %q", name)
+			// FileWatches created on the command line with --disabled
+			// aren't resources, but can be enabled and disabled by name.
+			source, err := fileWatchDisableSource(ctx, cli, name)
+			if err != nil {
+				return err
+			}
+			if source == nil {
+				return fmt.Errorf("no such resource %q", name)
+			}
+			selectedFileWatches = append(selectedFileWatches, source)
+			continue
 		}
 		if len(uir.Status.DisableStatus.Sources) == 0 {
 			return fmt.Errorf("%s cannot be enabled or disabled", name)
@@ -163,19 +180,54 @@ func changeEnabledResources(
 			if source.ConfigMap == nil {
 				return fmt.Errorf("internal error: resource %s's DisableSource does not have a ConfigMap'", uir.Name)
 			}
-			cm := &v1alpha1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: source.ConfigMap.Name}}
-			_, err := controllerutil.CreateOrUpdate(ctx, cli, cm, func() error {
-				if cm.Data == nil {
-					cm.Data = make(map[string]string)
-				}
-				cm.Data[source.ConfigMap.Key] = strconv.FormatBool(!enable)
-				return nil
-			})
+			err := setDisabled(ctx, cli, *source.ConfigMap, !enable)
 			if err != nil {
 				return err
 			}
 		}
 	}
 
+	for _, source := range selectedFileWatches {
+		err := setDisabled(ctx, cli, *source, !opts.enable)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+// Writes whether an object is disabled to its disable ConfigMap,
+// creating the ConfigMap if needed.
+func setDisabled(ctx context.Context, cli client.Client, source v1alpha1.ConfigMapDisableSource, disabled bool) error {
+	cm := &v1alpha1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: source.Name}}
+	_, err := controllerutil.CreateOrUpdate(ctx, cli, cm, func() error {
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+		cm.Data[source.Key] = strconv.FormatBool(disabled)
+		return nil
+	})
+	return err
+}
+
+// Returns the disable ConfigMap of the FileWatch with the given name,
+// or nil if there's no such FileWatch or it wasn't created with --disabled.
+//
+// FileWatches from the Tiltfile share the disable ConfigMap of their
+// resource, which is enabled and disabled by the resource's name instead.
+func fileWatchDisableSource(ctx context.Context, cli client.Client, name string) (*v1alpha1.ConfigMapDisableSource, error) {
+	var fw v1alpha1.FileWatch
+	err := cli.Get(ctx, types.NamespacedName{Name: name}, &fw)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	own := disableSourceForFileWatch(name)
+	if !apiequality.Semantic.DeepEqual(fw.Spec.DisableSource, own) {
+		return nil, nil
+	}
+	return own.ConfigMap, nil
+}
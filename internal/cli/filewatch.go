@@ -0,0 +1,286 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/internal/ospath"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// BuildFileWatch resolves paths and ignores the same way as
+// `tilt create filewatch NAME PATHS --ignore=IGNORES`, and returns the FileWatch.
+//
+// Relative paths and ignore patterns are interpreted relative to cwd. If cwd is
+// empty, the current directory is used. Paths may be globs or start with ~, and
+// must exist. Paths inside other watched paths are dropped.
+func BuildFileWatch(name string, paths, ignores []string, cwd string) (*v1alpha1.FileWatch, error) {
+	b := NewFileWatchBuilder(name).WithBase(cwd)
+	for _, path := range paths {
+		b.AddPath(path)
+	}
+	for _, ignore := range ignores {
+		b.AddIgnore(ignore)
+	}
+	return b.Build()
+}
+
+// FileWatchBuilder builds a FileWatch one option at a time, e.g.,
+//
+//	fw, err := NewFileWatchBuilder("my-watch").AddPath("src").AddIgnore("*.tmp").WithBase(dir).Build()
+//
+// Paths and ignore patterns are resolved by Build, the same way as BuildFileWatch
+// resolves them. A spec passed to WithSpec is used as is, so its paths and ignores
+// must already be resolved.
+type FileWatchBuilder struct {
+	name         string
+	generateName string
+	base         string
+	paths        []string
+	ignores      []string
+	labels       map[string]string
+	annotations  map[string]string
+	spec         v1alpha1.FileWatchSpec
+}
+
+// NewFileWatchBuilder starts building a FileWatch with this name.
+func NewFileWatchBuilder(name string) *FileWatchBuilder {
+	return &FileWatchBuilder{name: name}
+}
+
+// AddPath adds a path to watch. It may be a glob or start with ~, and must exist.
+func (b *FileWatchBuilder) AddPath(path string) *FileWatchBuilder {
+	b.paths = append(b.paths, path)
+	return b
+}
+
+// AddIgnore adds a dockerignore-style pattern of paths to ignore.
+func (b *FileWatchBuilder) AddIgnore(pattern string) *FileWatchBuilder {
+	b.ignores = append(b.ignores, pattern)
+	return b
+}
+
+// WithBase sets the directory that relative paths and ignore patterns are
+// relative to. If it's not set, the current directory is used.
+func (b *FileWatchBuilder) WithBase(dir string) *FileWatchBuilder {
+	b.base = dir
+	return b
+}
+
+// WithGenerateName has the tilt session generate the name, starting with prefix,
+// for a FileWatch built without a name.
+func (b *FileWatchBuilder) WithGenerateName(prefix string) *FileWatchBuilder {
+	b.generateName = prefix
+	return b
+}
+
+// WithLabels sets the labels of the FileWatch.
+func (b *FileWatchBuilder) WithLabels(labels map[string]string) *FileWatchBuilder {
+	b.labels = labels
+	return b
+}
+
+// WithAnnotations sets the annotations of the FileWatch.
+func (b *FileWatchBuilder) WithAnnotations(annotations map[string]string) *FileWatchBuilder {
+	b.annotations = annotations
+	return b
+}
+
+// WithSpec sets the spec that the paths and ignores are added to.
+func (b *FileWatchBuilder) WithSpec(spec v1alpha1.FileWatchSpec) *FileWatchBuilder {
+	b.spec = spec
+	return b
+}
+
+// Build resolves the paths and ignores, and returns the FileWatch.
+//
+// It's an error if the FileWatch has no name (or name prefix), or nothing to watch.
+func (b *FileWatchBuilder) Build() (*v1alpha1.FileWatch, error) {
+	if b.name == "" && b.generateName == "" {
+		return nil, fmt.Errorf("FileWatch name cannot be empty")
+	}
+
+	spec := *b.spec.DeepCopy()
+	if len(b.paths) == 0 && len(spec.WatchedPaths) == 0 && len(spec.URLs) == 0 {
+		return nil, fmt.Errorf("no paths to watch")
+	}
+
+	if len(b.paths) > 0 || len(b.ignores) > 0 {
+		cwd, err := resolveCwd(b.base)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(b.paths) > 0 {
+			watchedPaths, err := ResolveFileWatchPaths(b.paths, cwd, false)
+			if err != nil {
+				return nil, err
+			}
+			spec.WatchedPaths, _ = collapsePaths(append(spec.WatchedPaths, watchedPaths...))
+		}
+		spec.Ignores = append(spec.Ignores, FileWatchIgnores(b.ignores, cwd, spec.WatchedPaths)...)
+	}
+
+	err := validateIgnores(spec.Ignores)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:         b.name,
+			GenerateName: b.generateName,
+			Labels:       b.labels,
+			Annotations:  b.annotations,
+		},
+		Spec: spec,
+	}, nil
+}
+
+// ResolveFileWatchPaths converts paths to the absolute, canonical paths to watch.
+//
+// Relative paths are interpreted relative to cwd, a leading ~ is expanded to the
+// home directory, and globs are expanded to the paths they match. Unless allowMissing
+// is set, every path that isn't a glob must exist.
+func ResolveFileWatchPaths(paths []string, cwd string, allowMissing bool) ([]string, error) {
+	return resolveFileWatchPaths(paths, cwd, allowMissing, true, 0)
+}
+
+// Like ResolveFileWatchPaths, but unless resolveSymlinks is set, keeps
+// symlinks as they are instead of resolving them to their targets.
+//
+// If maxMatches is positive, a glob that matches more paths than that is an error.
+func resolveFileWatchPaths(paths []string, cwd string, allowMissing, resolveSymlinks bool, maxMatches int) ([]string, error) {
+	canonical := canonicalPath
+	if !resolveSymlinks {
+		canonical = filepath.Clean
+	}
+
+	result := []string{}
+	missing := []string{}
+	for _, path := range paths {
+		absPath, err := expandHome(path)
+		if err != nil {
+			return nil, err
+		}
+		if !filepath.IsAbs(absPath) {
+			absPath = filepath.Join(cwd, absPath)
+		}
+
+		if !hasGlobMeta(path) {
+			if !allowMissing {
+				_, err := os.Stat(absPath)
+				if os.IsNotExist(err) {
+					missing = append(missing, fmt.Sprintf("%s (%s)", path, absPath))
+				} else if err != nil {
+					return nil, err
+				}
+			}
+			result = append(result, canonical(absPath))
+			continue
+		}
+
+		matches, err := expandGlob(absPath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path pattern %q: %v", path, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("path pattern %q did not match any files", path)
+		}
+		if maxMatches > 0 && len(matches) > maxMatches {
+			return nil, tooManyMatchesError{pattern: path, matches: len(matches), max: maxMatches}
+		}
+		for _, match := range matches {
+			result = append(result, canonical(match))
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, missingPathsError{paths: missing}
+	}
+	return result, nil
+}
+
+// FileWatchIgnores converts dockerignore-style patterns to the ignores of a
+// FileWatch of watchedPaths. Returns nil if there are no patterns.
+//
+// The patterns are relative to cwd. Watched paths outside of cwd get their
+// own IgnoreDef with the same patterns, relative to the watched path instead.
+func FileWatchIgnores(patterns []string, cwd string, watchedPaths []string) []v1alpha1.IgnoreDef {
+	patterns = dedupePatterns(patterns)
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	result := []v1alpha1.IgnoreDef{}
+	for _, root := range ignoreRoots(canonicalPath(cwd), watchedPaths) {
+		result = append(result, v1alpha1.IgnoreDef{
+			BasePath: root,
+			Patterns: append([]string{}, patterns...),
+		})
+	}
+	return result
+}
+
+// Returns the base paths for ignore patterns: cwd, followed by the roots of
+// the watched paths outside of it.
+//
+// The root of a watched directory is the directory itself, and the root of
+// a watched file is its parent. Roots inside other roots are dropped.
+func ignoreRoots(cwd string, watchedPaths []string) []string {
+	outside := []string{}
+	for _, path := range watchedPaths {
+		root := path
+		info, err := os.Stat(path)
+		if err == nil && !info.IsDir() {
+			root = filepath.Dir(path)
+		}
+		if !ospath.IsChild(cwd, root) {
+			outside = append(outside, root)
+		}
+	}
+	outside, _ = collapsePaths(outside)
+	return append([]string{cwd}, outside...)
+}
+
+// Returned when paths to watch don't exist.
+type missingPathsError struct {
+	paths []string
+}
+
+func (e missingPathsError) Error() string {
+	return fmt.Sprintf("paths do not exist: %s", strings.Join(e.paths, ", "))
+}
+
+func isMissingPathsError(err error) bool {
+	var missing missingPathsError
+	return errors.As(err, &missing)
+}
+
+// Returned when a glob matches more paths than is safe to watch.
+type tooManyMatchesError struct {
+	pattern string
+	matches int
+	max     int
+}
+
+func (e tooManyMatchesError) Error() string {
+	return fmt.Sprintf("path pattern %q matches %d paths, more than the limit of %d", e.pattern, e.matches, e.max)
+}
+
+func isTooManyMatchesError(err error) bool {
+	var tooMany tooManyMatchesError
+	return errors.As(err, &tooMany)
+}
+
+func resolveCwd(cwd string) (string, error) {
+	if cwd == "" {
+		return os.Getwd()
+	}
+	return filepath.Abs(cwd)
+}
@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestBuildFileWatch(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.MkdirAll("web/src")
+	f.MkdirAll("docs")
+	dir, err := filepath.EvalSymlinks(f.Path())
+	require.NoError(t, err)
+
+	fw, err := BuildFileWatch("my-watch", []string{"web", "web/src", "docs"}, []string{"node_modules", "node_modules", "*.tmp"}, f.Path())
+	require.NoError(t, err)
+	assert.Equal(t, "my-watch", fw.Name)
+	assert.Equal(t, []string{filepath.Join(dir, "web"), filepath.Join(dir, "docs")}, fw.Spec.WatchedPaths)
+	assert.Equal(t, []v1alpha1.IgnoreDef{
+		{BasePath: dir, Patterns: []string{"node_modules", "*.tmp"}},
+	}, fw.Spec.Ignores)
+}
+
+func TestBuildFileWatchMultipleRoots(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.MkdirAll("repo/web")
+	f.MkdirAll("mnt/vendor/a")
+	f.MkdirAll("mnt/vendor/b")
+	f.WriteFile("mnt/config.yaml", "")
+	dir, err := filepath.EvalSymlinks(f.Path())
+	require.NoError(t, err)
+
+	fw, err := BuildFileWatch("my-watch",
+		[]string{"web", "../mnt/vendor/a", "../mnt/vendor/b", "../mnt/config.yaml"},
+		[]string{"build"}, f.JoinPath("repo"))
+	require.NoError(t, err)
+
+	bases := []string{}
+	for _, ignore := range fw.Spec.Ignores {
+		bases = append(bases, ignore.BasePath)
+		assert.Equal(t, []string{"build"}, ignore.Patterns)
+	}
+	// config.yaml is a file, so its root is its parent, which covers the vendor dirs.
+	assert.Equal(t, []string{filepath.Join(dir, "repo"), filepath.Join(dir, "mnt")}, bases)
+}
+
+func TestIgnoreRoots(t *testing.T) {
+	assert.Equal(t, []string{"/repo"}, ignoreRoots("/repo", []string{"/repo", "/repo/web"}))
+	assert.Equal(t, []string{"/repo", "/vendor/a", "/vendor/b"},
+		ignoreRoots("/repo", []string{"/repo/web", "/vendor/a", "/vendor/b", "/vendor/a/lib"}))
+	assert.Equal(t, []string{"/repo/sub", "/repo"}, ignoreRoots("/repo/sub", []string{"/repo"}))
+}
+
+func TestBuildFileWatchGlob(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.MkdirAll("services/a")
+	f.MkdirAll("services/b")
+	dir, err := filepath.EvalSymlinks(f.Path())
+	require.NoError(t, err)
+
+	fw, err := BuildFileWatch("my-watch", []string{"services/*"}, nil, f.Path())
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "services", "a"), filepath.Join(dir, "services", "b")}, fw.Spec.WatchedPaths)
+	assert.Nil(t, fw.Spec.Ignores)
+}
+
+func TestBuildFileWatchErrors(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.MkdirAll("web")
+
+	_, err := BuildFileWatch("", []string{"web"}, nil, f.Path())
+	assert.EqualError(t, err, "FileWatch name cannot be empty")
+
+	_, err = BuildFileWatch("my-watch", nil, nil, f.Path())
+	assert.EqualError(t, err, "no paths to watch")
+
+	_, err = BuildFileWatch("my-watch", []string{"missing"}, nil, f.Path())
+	require.Error(t, err)
+	assert.True(t, isMissingPathsError(err))
+	assert.Contains(t, err.Error(), "paths do not exist: missing")
+
+	_, err = BuildFileWatch("my-watch", []string{"web"}, []string{"!"}, f.Path())
+	assert.EqualError(t, err, `invalid ignore pattern "!": negation must be followed by a pattern`)
+}
+
+func TestFileWatchBuilder(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.MkdirAll("web/src")
+	f.MkdirAll("docs")
+	dir, err := filepath.EvalSymlinks(f.Path())
+	require.NoError(t, err)
+
+	fw, err := NewFileWatchBuilder("my-watch").
+		AddPath("web").
+		AddPath("web/src").
+		AddPath("docs").
+		AddIgnore("node_modules").
+		AddIgnore("*.tmp").
+		WithBase(f.Path()).
+		WithLabels(map[string]string{"app": "web"}).
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, "my-watch", fw.Name)
+	assert.Equal(t, map[string]string{"app": "web"}, fw.Labels)
+	assert.Equal(t, []string{filepath.Join(dir, "web"), filepath.Join(dir, "docs")}, fw.Spec.WatchedPaths)
+	assert.Equal(t, []v1alpha1.IgnoreDef{
+		{BasePath: dir, Patterns: []string{"node_modules", "*.tmp"}},
+	}, fw.Spec.Ignores)
+}
+
+func TestFileWatchBuilderWithSpec(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.MkdirAll("web")
+	dir, err := filepath.EvalSymlinks(f.Path())
+	require.NoError(t, err)
+
+	spec := v1alpha1.FileWatchSpec{WatchedPaths: []string{"/already/resolved"}, MaxEvents: 10}
+	fw, err := NewFileWatchBuilder("").
+		WithGenerateName("fw-").
+		WithSpec(spec).
+		AddPath("web").
+		WithBase(f.Path()).
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, "fw-", fw.GenerateName)
+	assert.Equal(t, []string{"/already/resolved", filepath.Join(dir, "web")}, fw.Spec.WatchedPaths)
+	assert.Equal(t, int32(10), fw.Spec.MaxEvents)
+	assert.Equal(t, []string{"/already/resolved"}, spec.WatchedPaths, "the spec passed in is left as is")
+
+	fw, err = NewFileWatchBuilder("my-watch").WithSpec(spec).Build()
+	require.NoError(t, err)
+	assert.Equal(t, spec, fw.Spec)
+}
+
+func TestFileWatchBuilderErrors(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.MkdirAll("web")
+
+	_, err := NewFileWatchBuilder("").AddPath("web").WithBase(f.Path()).Build()
+	assert.EqualError(t, err, "FileWatch name cannot be empty")
+
+	_, err = NewFileWatchBuilder("my-watch").AddIgnore("*.tmp").WithBase(f.Path()).Build()
+	assert.EqualError(t, err, "no paths to watch")
+
+	_, err = NewFileWatchBuilder("my-watch").AddPath("missing").WithBase(f.Path()).Build()
+	assert.True(t, isMissingPathsError(err), "expected missing paths, got: %v", err)
+
+	_, err = NewFileWatchBuilder("my-watch").AddPath("web").AddIgnore("!").WithBase(f.Path()).Build()
+	assert.EqualError(t, err, `invalid ignore pattern "!": negation must be followed by a pattern`)
+}
+
+func TestResolveFileWatchPathsAllowMissing(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+
+	paths, err := ResolveFileWatchPaths([]string{"missing", "/abs/missing"}, f.Path(), true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{canonicalPath(f.JoinPath("missing")), "/abs/missing"}, paths)
+}
+
+func TestFileWatchIgnores(t *testing.T) {
+	assert.Nil(t, FileWatchIgnores(nil, "/src", []string{"/src"}))
+	assert.Nil(t, FileWatchIgnores([]string{"", "  "}, "/src", []string{"/src"}))
+	assert.Equal(t, []v1alpha1.IgnoreDef{{BasePath: "/src", Patterns: []string{"build"}}},
+		FileWatchIgnores([]string{" build ", "build"}, "/src", []string{"/src/web", "/src/docs"}))
+}
@@ -15,8 +15,17 @@ import (
 var defaultWebHost = "localhost"
 var defaultWebPort = model.DefaultWebPort
 var defaultNamespace = ""
+var defaultAPIHost = ""
+var defaultAPIPort = 0
 var webHostFlag = ""
 var webPortFlag = 0
+var apiHostFlag = ""
+var apiPortFlag = 0
+var apiCACertFlag = ""
+var apiClientCertFlag = ""
+var apiClientKeyFlag = ""
+var apiTokenFlag = ""
+var apiKubeContextFlag = ""
 var snapshotViewPortFlag = 0
 var namespaceOverride = ""
 
@@ -34,6 +43,20 @@ func readEnvDefaults() error {
 	if envHost != "" {
 		defaultWebHost = envHost
 	}
+
+	envAPIPort := os.Getenv("TILT_API_PORT")
+	if envAPIPort != "" {
+		port, err := strconv.Atoi(envAPIPort)
+		if err != nil {
+			return errors.Wrap(err, "parsing env TILT_API_PORT")
+		}
+		defaultAPIPort = port
+	}
+
+	envAPIHost := os.Getenv("TILT_API_HOST")
+	if envAPIHost != "" {
+		defaultAPIHost = envAPIHost
+	}
 	return nil
 }
 
@@ -54,6 +77,18 @@ func addConnectServerFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&webHostFlag, "host", defaultWebHost, "Host for the Tilt HTTP server. Only necessary if you started Tilt with --host. Overrides TILT_HOST env variable.")
 }
 
+// For commands that talk to the API server directly, bypassing the session
+// lookup by web port.
+func addAPIServerFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&apiPortFlag, "api-port", defaultAPIPort, "Port for the Tilt API server. Only necessary to target a specific session when running multiple Tilt instances. Overrides TILT_API_PORT env variable.")
+	cmd.Flags().StringVar(&apiHostFlag, "api-host", defaultAPIHost, "Host for the Tilt API server. Only used with --api-port. Defaults to the host the session registered. Overrides TILT_API_HOST env variable.")
+	cmd.Flags().StringVar(&apiCACertFlag, "ca-cert", "", "Path to a PEM-encoded CA certificate for verifying the Tilt API server, instead of the one the session registered. Useful for a remote or port-forwarded session.")
+	cmd.Flags().StringVar(&apiClientCertFlag, "client-cert", "", "Path to a PEM-encoded client certificate for authenticating to the Tilt API server. Requires --client-key.")
+	cmd.Flags().StringVar(&apiClientKeyFlag, "client-key", "", "Path to the PEM-encoded private key for --client-cert.")
+	cmd.Flags().StringVar(&apiTokenFlag, "token", "", "Bearer token for authenticating to the Tilt API server, instead of the one the session registered.")
+	cmd.Flags().StringVar(&apiKubeContextFlag, "kube-context", "", "A context in your kubeconfig to connect to the Tilt API server with, instead of the session registered for --port. Useful when the Tilt API is exposed through a cluster.")
+}
+
 // For commands that start a web server.
 func addStartServerFlags(cmd *cobra.Command) {
 	cmd.Flags().IntVar(&webPortFlag, "port", defaultWebPort, "Port for the Tilt HTTP server. Set to 0 to disable. Overrides TILT_PORT env variable.")
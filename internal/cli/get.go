@@ -36,6 +36,7 @@ import (
 )
 
 type getCmd struct {
+	streams genericclioptions.IOStreams
 	options *get.GetOptions
 	cmd     *cobra.Command
 }
@@ -45,6 +46,7 @@ var _ tiltCmd = &getCmd{}
 func newGetCmd(streams genericclioptions.IOStreams) *getCmd {
 	o := get.NewGetOptions("tilt", streams)
 	return &getCmd{
+		streams: streams,
 		options: o,
 	}
 }
@@ -68,6 +70,9 @@ func (c *getCmd) register() *cobra.Command {
 	cmd.Flags().StringVarP(&o.LabelSelector, "selector", "l", o.LabelSelector, "Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
 	cmd.Flags().StringVar(&o.FieldSelector, "field-selector", o.FieldSelector, "Selector (field query) to filter on, supports '=', '==', and '!='.(e.g. --field-selector key1=value1,key2=value2). The server only supports a limited number of field queries per type.")
 	addConnectServerFlags(cmd)
+
+	addCommand(cmd, newGetFileWatchCmd(c.streams))
+
 	return cmd
 }
 
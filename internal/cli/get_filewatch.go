@@ -0,0 +1,680 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	engineanalytics "github.com/tilt-dev/tilt/internal/engine/analytics"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// How long to wait before reconnecting a closed watch.
+const (
+	getFileWatchMinBackoff = 250 * time.Millisecond
+	getFileWatchMaxBackoff = 10 * time.Second
+)
+
+// A human-friendly CLI for inspecting file watches.
+//
+// By default, prints the most recent file event of each FileWatch,
+// rather than the generic table of tilt get. It has a name of its own,
+// so that 'tilt get filewatch' still gets that table.
+type getFileWatchCmd struct {
+	// Connects to the tilt session and reports errors, like it does for create.
+	helper     *createHelper
+	printFlags *genericclioptions.PrintFlags
+	printer    printers.ResourcePrinter
+
+	watch     bool
+	showPaths bool
+	selector  string
+
+	// With --output-events-as=json, prints each file change as a JSON line.
+	eventsFormat string
+
+	// The LastEventTime of each FileWatch we've printed, so that
+	// a watch only prints new file events.
+	lastEventTimes map[string]metav1.MicroTime
+}
+
+var _ tiltCmd = &getFileWatchCmd{}
+
+func newGetFileWatchCmd(streams genericclioptions.IOStreams) *getFileWatchCmd {
+	return &getFileWatchCmd{
+		helper:         newCreateHelper(streams),
+		printFlags:     genericclioptions.NewPrintFlags(""),
+		lastEventTimes: make(map[string]metav1.MicroTime),
+	}
+}
+
+func (c *getFileWatchCmd) name() model.TiltSubcommand { return "get" }
+
+func (c *getFileWatchCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "filewatch-changes [NAME]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Display the most recent file changes of filewatches in a running tilt session",
+		Long: `Display the most recent file changes of FileWatches in a running tilt session.
+
+For the generic table of FileWatches, with the usual flags of
+'tilt get', use 'tilt get filewatch' instead.
+
+Prints the name of each FileWatch, the time of its most recent
+file event, and the paths that changed in that event.
+
+With --watch, keeps running and prints a new line each time a
+FileWatch sees a file change. If the connection to the tilt
+session drops, it reconnects and resumes from the last change it saw.
+If it was gone too long for that, it catches up from a fresh list,
+without printing the events it already printed.
+
+With -o wide, prints a table of how many paths and ignore patterns
+each FileWatch has, the base paths of its ignores, and its status.
+
+With --show-paths, prints the paths that each FileWatch watches
+and the patterns that it ignores instead, after any glob and
+symlink expansion when it was created. A FileWatch created with
+--description shows its description, too.
+
+With --watch --output-events-as=json, prints one JSON object per line
+for each path that changes, with the name of the FileWatch, the path,
+the time of the change, and the FileWatch's resourceVersion, e.g.:
+
+  {"name":"src","path":"/app/src/a.go","time":"2021-03-04T05:06:07.000000Z","resourceVersion":"42"}
+
+With --selector, only gets the FileWatches with matching labels,
+e.g., the ones created with --label ephemeral=true.
+
+Errors are printed to stderr, with the same --error-format, --quiet-errors,
+and exit codes as 'tilt create filewatch'.
+`,
+		Aliases: []string{"fw-changes"},
+		Args:    cobra.MaximumNArgs(1),
+		Example: `tilt get fw-changes
+
+tilt get fw-changes src-and-web --watch
+
+tilt get fw-changes src-and-web --show-paths
+
+tilt get fw-changes -l ephemeral=true`,
+	}
+
+	cmd.Flags().BoolVarP(&c.watch, "watch", "w", false,
+		"After getting the FileWatches, watch for new file changes.")
+	cmd.Flags().BoolVar(&c.showPaths, "show-paths", false,
+		"Print the watched paths and ignores of each FileWatch, instead of its most recent file changes.")
+	cmd.Flags().StringVar(&c.eventsFormat, "output-events-as", "text",
+		"With --watch, how to print file changes. One of: (text, json). json prints a JSON object per changed path.")
+	cmd.Flags().StringVarP(&c.selector, "selector", "l", "",
+		"Selector (label query) to filter on, supports '=', '==', '!=', 'in', 'notin', and 'exists'. (e.g. -l key1=value1,key2=value2)")
+
+	c.printFlags.AddFlags(cmd)
+	output := cmd.Flags().Lookup("output")
+	output.Usage = strings.Replace(output.Usage, "One of: (", "One of: (wide, ", 1)
+	addConnectServerFlags(cmd)
+	addAPIServerFlags(cmd)
+	c.helper.addErrorFormatFlag(cmd)
+	cmd.MarkFlagsMutuallyExclusive("show-paths", "output")
+	cmd.MarkFlagsMutuallyExclusive("show-paths", "watch")
+	cmd.MarkFlagsMutuallyExclusive("output-events-as", "output")
+
+	return cmd
+}
+
+func (c *getFileWatchCmd) run(ctx context.Context, args []string) error {
+	a := analytics.Get(ctx)
+	cmdTags := engineanalytics.CmdTags(map[string]string{})
+	a.Incr("cmd.get-filewatch", cmdTags.AsMap())
+	defer a.Flush(time.Second)
+
+	return c.helper.fail(c.getAndWatch(ctx, args))
+}
+
+// Checks the flags, prints the FileWatches, and with --watch, their new file changes.
+func (c *getFileWatchCmd) getAndWatch(ctx context.Context, args []string) error {
+	err := c.helper.checkErrorFormat()
+	if err != nil {
+		return err
+	}
+	if c.selector != "" {
+		if len(args) > 0 {
+			return usageErrorf("--selector can't be used with a FileWatch name")
+		}
+		_, err := labels.Parse(c.selector)
+		if err != nil {
+			return usageErrorf("invalid --selector %q: %v", c.selector, err)
+		}
+	}
+
+	jsonEvents, err := isJSONEventsFormat(c.eventsFormat)
+	if err != nil {
+		return usageError{err: err}
+	}
+	if jsonEvents && !c.watch {
+		return usageErrorf("--output-events-as can only be used with --watch")
+	}
+
+	if jsonEvents {
+		c.printer = newFileChangesJSONPrinter()
+	} else if *c.printFlags.OutputFormat == "wide" {
+		c.printer = newFileWatchTablePrinter()
+	} else if *c.printFlags.OutputFormat != "" {
+		printer, err := c.printFlags.ToPrinter()
+		if err != nil {
+			return usageError{err: err}
+		}
+		c.printer = printer
+	}
+	if c.showPaths {
+		c.printer = fileWatchPathsPrinter{}
+	}
+
+	err = c.helper.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+	}
+
+	client := c.helper.dynamicClient.Resource((&v1alpha1.FileWatch{}).GetGroupVersionResource())
+	resourceVersion, err := c.get(ctx, client, name)
+	if err != nil {
+		return wrapNoSessionError(err)
+	}
+
+	if !c.watch {
+		return nil
+	}
+	return c.watchLoop(ctx, client, name, resourceVersion)
+}
+
+// Prints the current state of the FileWatch with the given name, or of all FileWatches by name.
+//
+// Returns the resource version to start watching from.
+func (c *getFileWatchCmd) get(ctx context.Context, client dynamic.ResourceInterface, name string) (string, error) {
+	if name != "" {
+		obj, err := client.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		return obj.GetResourceVersion(), c.print(obj)
+	}
+
+	list, err := client.List(ctx, c.listOptions(""))
+	if err != nil {
+		return "", err
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].GetName() < list.Items[j].GetName()
+	})
+	if table, ok := c.printer.(fileWatchTablePrinter); ok {
+		// Print the list as one table, so that its columns line up.
+		for i := range list.Items {
+			_, err := c.track(&list.Items[i])
+			if err != nil {
+				return "", err
+			}
+		}
+		return list.GetResourceVersion(), table.PrintObj(list, c.helper.streams.Out)
+	}
+	for i := range list.Items {
+		err := c.print(&list.Items[i])
+		if err != nil {
+			return "", err
+		}
+	}
+	return list.GetResourceVersion(), nil
+}
+
+// Prints each new file event until the context is canceled.
+//
+// If the server closes the watch, reconnects with exponential backoff,
+// resuming from the last resource version seen. If the server no longer
+// has the changes since then (410 Gone), starts over from a fresh list.
+func (c *getFileWatchCmd) watchLoop(ctx context.Context, client dynamic.ResourceInterface, name string, resourceVersion string) error {
+	backoff := getFileWatchMinBackoff
+	for {
+		opts := c.listOptions(resourceVersion)
+		opts.AllowWatchBookmarks = true
+		w, err := client.Watch(ctx, opts)
+		if err == nil {
+			var sawEvent bool
+			resourceVersion, sawEvent, err = c.consume(ctx, w, name, resourceVersion)
+			if sawEvent {
+				backoff = getFileWatchMinBackoff
+			}
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		if apierrors.IsGone(err) || apierrors.IsResourceExpired(err) {
+			resourceVersion, err = c.resync(ctx, client, name)
+			if err == nil {
+				continue
+			}
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(c.helper.streams.ErrOut, "Watching filewatches: %v\n", err)
+		}
+		_, _ = fmt.Fprintf(c.helper.streams.ErrOut, "Connection lost. Reconnecting in %s...\n", backoff)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > getFileWatchMaxBackoff {
+			backoff = getFileWatchMaxBackoff
+		}
+	}
+}
+
+// Reads events from the watch until it closes or the context is canceled.
+//
+// Returns the last resource version seen, and whether any events were seen at all.
+func (c *getFileWatchCmd) consume(ctx context.Context, w watch.Interface, name string, resourceVersion string) (string, bool, error) {
+	defer w.Stop()
+
+	sawEvent := false
+	for {
+		var event watch.Event
+		var ok bool
+		select {
+		case <-ctx.Done():
+			return resourceVersion, sawEvent, nil
+		case event, ok = <-w.ResultChan():
+		}
+		if !ok {
+			return resourceVersion, sawEvent, nil
+		}
+
+		sawEvent = true
+		if event.Type == watch.Error {
+			// Usually means our resource version is too old, which the
+			// caller handles by starting over from the current state.
+			return resourceVersion, sawEvent, apierrors.FromObject(event.Object)
+		}
+
+		obj, ok := event.Object.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		resourceVersion = obj.GetResourceVersion()
+		if event.Type == watch.Bookmark {
+			continue
+		}
+		if name != "" && obj.GetName() != name {
+			continue
+		}
+
+		if event.Type == watch.Deleted {
+			delete(c.lastEventTimes, obj.GetName())
+			continue
+		}
+
+		err := c.printNewEvent(obj)
+		if err != nil {
+			return resourceVersion, sawEvent, err
+		}
+	}
+}
+
+// Catches up after the server no longer has the changes since the last
+// resource version seen, e.g., after being disconnected for a long time.
+//
+// Lists the FileWatches again, and prints the ones with file events that
+// haven't been printed yet, instead of printing them all again.
+//
+// Returns the resource version to start watching from.
+func (c *getFileWatchCmd) resync(ctx context.Context, client dynamic.ResourceInterface, name string) (string, error) {
+	_, _ = fmt.Fprintf(c.helper.streams.ErrOut, "Missed some changes while disconnected. Catching up...\n")
+
+	list, err := client.List(ctx, c.listOptions(""))
+	if err != nil {
+		return "", err
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].GetName() < list.Items[j].GetName()
+	})
+
+	listed := make(map[string]bool, len(list.Items))
+	for i := range list.Items {
+		obj := &list.Items[i]
+		if name != "" && obj.GetName() != name {
+			continue
+		}
+		listed[obj.GetName()] = true
+		err := c.printNewEvent(obj)
+		if err != nil {
+			return "", err
+		}
+	}
+	// Forget the FileWatches deleted in the meantime.
+	for seen := range c.lastEventTimes {
+		if !listed[seen] {
+			delete(c.lastEventTimes, seen)
+		}
+	}
+	return list.GetResourceVersion(), nil
+}
+
+// The options to list and watch FileWatches with, filtered by --selector.
+func (c *getFileWatchCmd) listOptions(resourceVersion string) metav1.ListOptions {
+	return metav1.ListOptions{LabelSelector: c.selector, ResourceVersion: resourceVersion}
+}
+
+// Prints a FileWatch if it has a new file event, but not on every status update.
+func (c *getFileWatchCmd) printNewEvent(obj *unstructured.Unstructured) error {
+	var fw v1alpha1.FileWatch
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &fw)
+	if err != nil {
+		return err
+	}
+
+	lastEventTime := c.lastEventTimes[fw.Name]
+	if fw.Status.LastEventTime.IsZero() || lastEventTime.Equal(&fw.Status.LastEventTime) {
+		return nil
+	}
+	return c.print(obj)
+}
+
+// Remembers the LastEventTime of a FileWatch, so that a watch only
+// prints it again once it has a newer file event.
+func (c *getFileWatchCmd) track(obj *unstructured.Unstructured) (*v1alpha1.FileWatch, error) {
+	var fw v1alpha1.FileWatch
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &fw)
+	if err != nil {
+		return nil, err
+	}
+	c.lastEventTimes[fw.Name] = fw.Status.LastEventTime
+	return &fw, nil
+}
+
+// Prints a FileWatch, either with the --output printer or as a summary of its most recent file event.
+func (c *getFileWatchCmd) print(obj *unstructured.Unstructured) error {
+	fw, err := c.track(obj)
+	if err != nil {
+		return err
+	}
+
+	if c.printer != nil {
+		return c.printer.PrintObj(obj, c.helper.streams.Out)
+	}
+
+	lastEventTime := "<none>"
+	if !fw.Status.LastEventTime.IsZero() {
+		lastEventTime = fw.Status.LastEventTime.Format(time.RFC3339)
+	}
+
+	paths := "<none>"
+	if len(fw.Status.FileEvents) > 0 {
+		paths = strings.Join(fw.Status.FileEvents[len(fw.Status.FileEvents)-1].SeenFiles, ",")
+	}
+
+	_, err = fmt.Fprintf(c.helper.streams.Out, "%s\t%s\t%s\n", fw.Name, lastEventTime, paths)
+	return err
+}
+
+// Describes the paths that a FileWatch watches and ignores, one FileWatch
+// per block:
+//
+//	Name:            src-and-web
+//	Description:     Rebuilds the web app
+//	Watched Paths:   /home/me/app/src
+//	                 /home/me/app/web
+//	Ignores:         /home/me/app: node_modules, *.tmp
+type fileWatchPathsPrinter struct{}
+
+var _ printers.ResourcePrinter = fileWatchPathsPrinter{}
+
+func (fileWatchPathsPrinter) PrintObj(obj runtime.Object, out io.Writer) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("internal error: expected an unstructured FileWatch, got %T", obj)
+	}
+
+	var fw v1alpha1.FileWatch
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &fw)
+	if err != nil {
+		return err
+	}
+
+	w := printers.GetNewTabWriter(out)
+	_, _ = fmt.Fprintf(w, "Name:\t%s\n", fw.Name)
+	if description := fw.Annotations[v1alpha1.AnnotationDescription]; description != "" {
+		_, _ = fmt.Fprintf(w, "Description:\t%s\n", description)
+	}
+	printPathsBlock(w, "Watched Paths:", fw.Spec.WatchedPaths)
+
+	ignores := make([]string, 0, len(fw.Spec.Ignores))
+	for _, ignore := range fw.Spec.Ignores {
+		ignores = append(ignores, fmt.Sprintf("%s: %s", ignore.BasePath, strings.Join(ignore.Patterns, ", ")))
+	}
+	printPathsBlock(w, "Ignores:", ignores)
+	_, _ = fmt.Fprintln(w)
+	return w.Flush()
+}
+
+// Prints a label followed by one value per line, aligned in the second column.
+func printPathsBlock(w io.Writer, label string, values []string) {
+	if len(values) == 0 {
+		_, _ = fmt.Fprintf(w, "%s\t<none>\n", label)
+		return
+	}
+	for i, value := range values {
+		if i > 0 {
+			label = ""
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", label, value)
+	}
+}
+
+// Prints FileWatches as a table for -o wide, one row per FileWatch,
+// with the number of paths and ignores that each one resolved to:
+//
+//	NAME          PATHS   IGNORES   BASE           STATUS
+//	src-and-web   2       3         /home/me/app   Watching
+//
+// Prints a list as one table, so that its columns line up. Otherwise,
+// the header is only printed before the first row.
+type fileWatchTablePrinter struct {
+	table printers.ResourcePrinter
+}
+
+var _ printers.ResourcePrinter = fileWatchTablePrinter{}
+
+func newFileWatchTablePrinter() fileWatchTablePrinter {
+	return fileWatchTablePrinter{table: printers.NewTablePrinter(printers.PrintOptions{Wide: true})}
+}
+
+var fileWatchTableColumns = []metav1.TableColumnDefinition{
+	{Name: "Name", Type: "string", Format: "name"},
+	{Name: "Paths", Type: "integer", Description: "The number of watched paths, or URLs in url mode."},
+	{Name: "Ignores", Type: "integer", Description: "The number of ignore patterns. A base path without patterns counts as one."},
+	{Name: "Base", Type: "string", Description: "The base paths of the ignores."},
+	{Name: "Status", Type: "string"},
+}
+
+func (p fileWatchTablePrinter) PrintObj(obj runtime.Object, out io.Writer) error {
+	var items []unstructured.Unstructured
+	switch obj := obj.(type) {
+	case *unstructured.Unstructured:
+		items = append(items, *obj)
+	case *unstructured.UnstructuredList:
+		items = obj.Items
+	default:
+		return fmt.Errorf("internal error: expected an unstructured FileWatch, got %T", obj)
+	}
+
+	table := &metav1.Table{ColumnDefinitions: fileWatchTableColumns}
+	for _, item := range items {
+		var fw v1alpha1.FileWatch
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &fw)
+		if err != nil {
+			return err
+		}
+		table.Rows = append(table.Rows, metav1.TableRow{Cells: fileWatchTableCells(&fw)})
+	}
+	return p.table.PrintObj(table, out)
+}
+
+// The cells of a FileWatch's row in the -o wide table.
+func fileWatchTableCells(fw *v1alpha1.FileWatch) []interface{} {
+	ignores := 0
+	var bases []string
+	seen := make(map[string]bool)
+	for _, ignore := range fw.Spec.Ignores {
+		if len(ignore.Patterns) == 0 {
+			ignores++
+		}
+		ignores += len(ignore.Patterns)
+		if !seen[ignore.BasePath] {
+			seen[ignore.BasePath] = true
+			bases = append(bases, ignore.BasePath)
+		}
+	}
+
+	base := "<none>"
+	if len(bases) > 0 {
+		base = strings.Join(bases, ",")
+	}
+	return []interface{}{
+		fw.Name,
+		int64(len(fw.Spec.WatchedPaths) + len(fw.Spec.URLs)),
+		int64(ignores),
+		base,
+		fileWatchStatusSummary(fw),
+	}
+}
+
+// Summarizes the status of a FileWatch in a word, or <none> if it has
+// no status yet, e.g., in a dry run.
+func fileWatchStatusSummary(fw *v1alpha1.FileWatch) string {
+	switch {
+	case fw.Status.Error != "":
+		return "Error"
+	case fw.Status.DisableStatus != nil && fw.Status.DisableStatus.State == v1alpha1.DisableStateDisabled:
+		return "Disabled"
+	case !fw.Status.MonitorStartTime.IsZero():
+		return "Watching"
+	}
+	return "<none>"
+}
+
+// Prints each file change that a FileWatch reports, one path per line,
+// like `tail -f`:
+//
+//	2021-03-04T05:06:07Z	/home/me/app/src/a.go
+//
+// Only prints the file events newer than the ones it already printed,
+// since the status of a FileWatch keeps its most recent events.
+type fileChangesPrinter struct {
+	// The time of the last file event printed for each FileWatch.
+	printed map[string]metav1.MicroTime
+
+	asJSON bool
+}
+
+var _ printers.ResourcePrinter = &fileChangesPrinter{}
+
+func newFileChangesPrinter() *fileChangesPrinter {
+	return &fileChangesPrinter{printed: make(map[string]metav1.MicroTime)}
+}
+
+// Like newFileChangesPrinter, but prints each file change as a line of JSON:
+//
+//	{"name":"src","path":"/home/me/app/src/a.go","time":"2021-03-04T05:06:07.000000Z","resourceVersion":"42"}
+func newFileChangesJSONPrinter() *fileChangesPrinter {
+	return &fileChangesPrinter{printed: make(map[string]metav1.MicroTime), asJSON: true}
+}
+
+// A file change, as printed by --output-events-as=json.
+//
+// Scripts parse these lines, so keep the field names and their order stable.
+type fileChangeJSON struct {
+	Name            string `json:"name"`
+	Path            string `json:"path"`
+	Time            string `json:"time"`
+	ResourceVersion string `json:"resourceVersion"`
+}
+
+// Whether an --output-events-as value asks for JSON, or an error if it's invalid.
+func isJSONEventsFormat(format string) (bool, error) {
+	switch format {
+	case "text":
+		return false, nil
+	case "json":
+		return true, nil
+	}
+	return false, fmt.Errorf("--output-events-as must be one of (text, json), got %q", format)
+}
+
+func (p *fileChangesPrinter) PrintObj(obj runtime.Object, out io.Writer) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("internal error: expected an unstructured FileWatch, got %T", obj)
+	}
+
+	var fw v1alpha1.FileWatch
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &fw)
+	if err != nil {
+		return err
+	}
+
+	last := p.printed[fw.Name]
+	for _, event := range fw.Status.FileEvents {
+		if !last.Before(&event.Time) {
+			continue
+		}
+		for _, path := range event.SeenFiles {
+			err := p.printChange(out, &fw, event, path)
+			if err != nil {
+				return err
+			}
+		}
+		p.printed[fw.Name] = event.Time
+	}
+	return nil
+}
+
+func (p *fileChangesPrinter) printChange(out io.Writer, fw *v1alpha1.FileWatch, event v1alpha1.FileEvent, path string) error {
+	if !p.asJSON {
+		_, err := fmt.Fprintf(out, "%s\t%s\n", event.Time.Format(time.RFC3339), path)
+		return err
+	}
+
+	line, err := json.Marshal(fileChangeJSON{
+		Name:            fw.Name,
+		Path:            path,
+		Time:            event.Time.UTC().Format(metav1.RFC3339Micro),
+		ResourceVersion: fw.ResourceVersion,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "%s\n", line)
+	return err
+}
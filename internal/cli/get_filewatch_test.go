@@ -0,0 +1,580 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/internal/testutils/bufsync"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestGetFileWatch(t *testing.T) {
+	f := newServerFixture(t)
+
+	eventTime := metav1.NewMicroTime(time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC))
+	f.createFileWatch("my-watch")
+	f.createFileWatch("other-watch")
+	f.recordFileEvent("my-watch", eventTime, "/src/a.go")
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := newGetFileWatchCmd(streams)
+	c := cmd.register()
+	err := c.Flags().Parse([]string{})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, "my-watch\t2021-03-04T05:06:07Z\t/src/a.go\nother-watch\t<none>\t<none>\n", out.String())
+}
+
+func TestGetFileWatchErrorsStayInStreams(t *testing.T) {
+	f := newServerFixture(t)
+	stdout, stderr := captureStdio(t)
+
+	for _, tc := range []struct {
+		args     []string
+		expected string
+		code     int
+	}{
+		{[]string{"-l", "a=b", "my-watch"}, "Error: --selector can't be used with a FileWatch name\n", exitCodeValidation},
+		{[]string{"--error-format=json", "my-watch"},
+			`{"kind":"unknown","code":404,"message":"filewatches.tilt.dev \"my-watch\" not found"}` + "\n", exitCodeUnknown},
+	} {
+		streams, _, _, errOut := genericclioptions.NewTestIOStreams()
+		cmd := newGetFileWatchCmd(streams)
+		c := cmd.register()
+		err := c.Flags().Parse(tc.args)
+		require.NoError(t, err)
+
+		err = cmd.run(f.ctx, c.Flags().Args())
+		require.Error(t, err)
+		assert.Equal(t, tc.code, exitCode(err), "args: %v", tc.args)
+		assert.Equal(t, tc.expected, errOut.String(), "args: %v", tc.args)
+	}
+
+	assert.Empty(t, stdout())
+	assert.Empty(t, stderr())
+}
+
+func TestGetFileWatchSelector(t *testing.T) {
+	f := newServerFixture(t)
+
+	for name, ephemeral := range map[string]string{"tmp-watch": "true", "src-watch": "false"} {
+		err := f.client.Create(f.ctx, &v1alpha1.FileWatch{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"ephemeral": ephemeral}},
+			Spec:       v1alpha1.FileWatchSpec{WatchedPaths: []string{f.Path()}},
+		})
+		require.NoError(t, err)
+	}
+	f.createFileWatch("other-watch")
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := newGetFileWatchCmd(streams)
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"-l", "ephemeral=true"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, "tmp-watch\t<none>\t<none>\n", out.String())
+}
+
+func TestGetFileWatchSelectorOptions(t *testing.T) {
+	gvr := (&v1alpha1.FileWatch{}).GetGroupVersionResource()
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(v1alpha1.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "FileWatchList"})
+	var listSelector string
+	client.PrependReactor("list", "filewatches", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		listSelector = action.(k8stesting.ListAction).GetListRestrictions().Labels.String()
+		return true, &v1alpha1.FileWatchList{ListMeta: metav1.ListMeta{ResourceVersion: "3"}}, nil
+	})
+	watchRestrictions := make(chan k8stesting.WatchRestrictions, 1)
+	client.PrependWatchReactor("filewatches", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		watchRestrictions <- action.(k8stesting.WatchAction).GetWatchRestrictions()
+		return true, watch.NewFake(), nil
+	})
+
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	cmd := newGetFileWatchCmd(streams)
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--selector", "ephemeral=true,team!=web"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	resourceVersion, err := cmd.get(ctx, client.Resource(gvr), "")
+	require.NoError(t, err)
+	assert.Equal(t, "ephemeral=true,team!=web", listSelector)
+
+	done := make(chan error)
+	go func() {
+		done <- cmd.watchLoop(ctx, client.Resource(gvr), "", resourceVersion)
+	}()
+	restrictions := <-watchRestrictions
+	assert.Equal(t, "ephemeral=true,team!=web", restrictions.Labels.String())
+	assert.Equal(t, "3", restrictions.ResourceVersion)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestGetFileWatchSelectorInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"syntax", []string{"-l", "ephemeral=true,,"}, `invalid --selector "ephemeral=true,,"`},
+		{"with name", []string{"-l", "ephemeral=true", "my-watch"}, "--selector can't be used with a FileWatch name"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			streams, _, _, _ := genericclioptions.NewTestIOStreams()
+			cmd := newGetFileWatchCmd(streams)
+			c := cmd.register()
+			err := c.Flags().Parse(tc.args)
+			require.NoError(t, err)
+
+			ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+			err = cmd.run(ctx, c.Flags().Args())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestGetFileWatchShowPaths(t *testing.T) {
+	f := newServerFixture(t)
+
+	err := f.client.Create(f.ctx, &v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{Name: "src-and-web"},
+		Spec: v1alpha1.FileWatchSpec{
+			WatchedPaths: []string{"/app/src", "/app/web"},
+			Ignores: []v1alpha1.IgnoreDef{
+				{BasePath: "/app", Patterns: []string{"node_modules", "*.tmp"}},
+				{BasePath: "/app/web", Patterns: []string{"dist"}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	f.createFileWatch("other-watch")
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := newGetFileWatchCmd(streams)
+	c := cmd.register()
+	err = c.Flags().Parse([]string{"--show-paths"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, `Name:            other-watch
+Watched Paths:   `+f.Path()+`
+Ignores:         <none>
+
+Name:            src-and-web
+Watched Paths:   /app/src
+                 /app/web
+Ignores:         /app: node_modules, *.tmp
+                 /app/web: dist
+
+`, out.String())
+}
+
+func TestGetFileWatchShowPathsDescription(t *testing.T) {
+	f := newServerFixture(t)
+
+	err := f.client.Create(f.ctx, &v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "protos",
+			Annotations: map[string]string{v1alpha1.AnnotationDescription: "Regenerates the API clients"},
+		},
+		Spec: v1alpha1.FileWatchSpec{WatchedPaths: []string{"/app/proto"}},
+	})
+	require.NoError(t, err)
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := newGetFileWatchCmd(streams)
+	c := cmd.register()
+	err = c.Flags().Parse([]string{"--show-paths", "protos"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, `Name:            protos
+Description:     Regenerates the API clients
+Watched Paths:   /app/proto
+Ignores:         <none>
+
+`, out.String())
+}
+
+func TestGetFileWatchAPIPort(t *testing.T) {
+	f := newServerFixture(t)
+	f.createFileWatch("my-watch")
+
+	// Point the web port somewhere with no session, so that only
+	// --api-port can find the server.
+	defaultWebPort = 1
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := newGetFileWatchCmd(streams)
+	c := cmd.register()
+	t.Cleanup(func() { apiPortFlag = 0 })
+	err := c.Flags().Parse([]string{"-o", "name", "--api-port", strconv.Itoa(f.apiPort), "--api-host", "localhost"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, "filewatch.tilt.dev/my-watch\n", out.String())
+}
+
+func TestGetFileWatchAPIPortNotFound(t *testing.T) {
+	f := newServerFixture(t)
+
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	cmd := newGetFileWatchCmd(streams)
+	c := cmd.register()
+	t.Cleanup(func() { apiPortFlag = 0 })
+	err := c.Flags().Parse([]string{"--api-port", strconv.Itoa(f.apiPort + 1)})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("No tilt apiserver found on port %d", f.apiPort+1))
+}
+
+func TestGetFileWatchOutput(t *testing.T) {
+	f := newServerFixture(t)
+	f.createFileWatch("my-watch")
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := newGetFileWatchCmd(streams)
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"-o", "name", "my-watch"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t, "filewatch.tilt.dev/my-watch\n", out.String())
+}
+
+func TestGetFileWatchWide(t *testing.T) {
+	f := newServerFixture(t)
+	f.createFileWatch("my-watch")
+	f.createFileWatch("other-watch-with-a-longer-name")
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := newGetFileWatchCmd(streams)
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"-o", "wide"})
+	require.NoError(t, err)
+
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+	assert.Equal(t,
+		"NAME                             PATHS   IGNORES   BASE     STATUS\n"+
+			"my-watch                         1       0         <none>   <none>\n"+
+			"other-watch-with-a-longer-name   1       0         <none>   <none>\n",
+		out.String())
+}
+
+func TestFileWatchTableCells(t *testing.T) {
+	fw := &v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-watch"},
+		Spec: v1alpha1.FileWatchSpec{
+			WatchedPaths: []string{"/app/src", "/app/web"},
+			Ignores: []v1alpha1.IgnoreDef{
+				{BasePath: "/app", Patterns: []string{"node_modules", "*.tmp"}},
+				{BasePath: "/app/web", Patterns: []string{"dist"}},
+				{BasePath: "/app/build"},
+				{BasePath: "/app", Patterns: []string{".git"}},
+			},
+		},
+	}
+	assert.Equal(t,
+		[]interface{}{"my-watch", int64(2), int64(5), "/app,/app/web,/app/build", "<none>"},
+		fileWatchTableCells(fw))
+
+	fw.Status.MonitorStartTime = metav1.NewMicroTime(time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC))
+	assert.Equal(t, "Watching", fileWatchTableCells(fw)[4])
+
+	fw.Status.DisableStatus = &v1alpha1.DisableStatus{State: v1alpha1.DisableStateDisabled}
+	assert.Equal(t, "Disabled", fileWatchTableCells(fw)[4])
+
+	fw.Status.Error = "too many files"
+	assert.Equal(t, "Error", fileWatchTableCells(fw)[4])
+}
+
+func TestGetFileWatchWatch(t *testing.T) {
+	f := newServerFixture(t)
+	f.createFileWatch("my-watch")
+	f.createFileWatch("other-watch")
+
+	out := bufsync.NewThreadSafeBuffer()
+	streams := genericclioptions.IOStreams{Out: out, ErrOut: bufsync.NewThreadSafeBuffer()}
+	cmd := newGetFileWatchCmd(streams)
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--watch", "my-watch"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(f.ctx)
+	done := make(chan error)
+	go func() {
+		done <- cmd.run(ctx, c.Flags().Args())
+	}()
+
+	out.AssertEventuallyContains(t, "my-watch\t<none>\t<none>\n", time.Second)
+
+	eventTime := metav1.NewMicroTime(time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC))
+	f.recordFileEvent("other-watch", eventTime, "/web/b.go")
+	f.recordFileEvent("my-watch", eventTime, "/src/a.go")
+	out.AssertEventuallyContains(t, "my-watch\t2021-03-04T05:06:07Z\t/src/a.go\n", time.Second)
+	assert.NotContains(t, out.String(), "other-watch")
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for watch to exit")
+	}
+}
+
+func TestGetFileWatchReconnects(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(v1alpha1.NewScheme())
+	watches := make(chan *watch.FakeWatcher, 2)
+	client.PrependWatchReactor("filewatches", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w := watch.NewFake()
+		watches <- w
+		return true, w, nil
+	})
+
+	out := bufsync.NewThreadSafeBuffer()
+	errOut := bufsync.NewThreadSafeBuffer()
+	cmd := newGetFileWatchCmd(genericclioptions.IOStreams{Out: out, ErrOut: errOut})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() {
+		gvr := (&v1alpha1.FileWatch{}).GetGroupVersionResource()
+		done <- cmd.watchLoop(ctx, client.Resource(gvr), "my-watch", "1")
+	}()
+
+	// Simulate the server dropping the connection.
+	(<-watches).Stop()
+	errOut.AssertEventuallyContains(t, "Connection lost. Reconnecting", time.Second)
+
+	eventTime := metav1.NewMicroTime(time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC))
+	fw := &v1alpha1.FileWatch{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "tilt.dev/v1alpha1", Kind: "FileWatch"},
+		ObjectMeta: metav1.ObjectMeta{Name: "my-watch", ResourceVersion: "2"},
+		Status: v1alpha1.FileWatchStatus{
+			LastEventTime: eventTime,
+			FileEvents:    []v1alpha1.FileEvent{{Time: eventTime, SeenFiles: []string{"/src/a.go"}}},
+		},
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(fw)
+	require.NoError(t, err)
+	(<-watches).Modify(&unstructured.Unstructured{Object: obj})
+	out.AssertEventuallyContains(t, "my-watch\t2021-03-04T05:06:07Z\t/src/a.go\n", time.Second)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestGetFileWatchResumesFromResourceVersion(t *testing.T) {
+	gvr := (&v1alpha1.FileWatch{}).GetGroupVersionResource()
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(v1alpha1.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "FileWatchList"})
+	watches := make(chan *watch.FakeWatcher, 3)
+	resourceVersions := make(chan string, 3)
+	client.PrependWatchReactor("filewatches", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		resourceVersions <- action.(k8stesting.WatchAction).GetWatchRestrictions().ResourceVersion
+		w := watch.NewFake()
+		watches <- w
+		return true, w, nil
+	})
+
+	firstEvent := metav1.NewMicroTime(time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC))
+	missedEvent := metav1.NewMicroTime(time.Date(2021, time.March, 4, 5, 6, 8, 0, time.UTC))
+	client.PrependReactor("list", "filewatches", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &v1alpha1.FileWatchList{
+			ListMeta: metav1.ListMeta{ResourceVersion: "9"},
+			Items: []v1alpha1.FileWatch{
+				*fileWatchWithEvent("my-watch", "8", missedEvent, "/src/b.go"),
+				*fileWatchWithEvent("other-watch", "7", missedEvent, "/src/c.go"),
+			},
+		}, nil
+	})
+
+	out := bufsync.NewThreadSafeBuffer()
+	errOut := bufsync.NewThreadSafeBuffer()
+	cmd := newGetFileWatchCmd(genericclioptions.IOStreams{Out: out, ErrOut: errOut})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() {
+		done <- cmd.watchLoop(ctx, client.Resource(gvr), "my-watch", "1")
+	}()
+
+	assert.Equal(t, "1", <-resourceVersions)
+	w := <-watches
+	w.Modify(unstructuredFileWatch(t, fileWatchWithEvent("my-watch", "5", firstEvent, "/src/a.go")))
+	out.AssertEventuallyContains(t, "my-watch\t2021-03-04T05:06:07Z\t/src/a.go\n", time.Second)
+	w.Stop()
+
+	// Reconnects from the last change it saw.
+	assert.Equal(t, "5", <-resourceVersions)
+	w = <-watches
+	w.Error(&apierrors.NewResourceExpired("too old resource version: 5").ErrStatus)
+
+	// Catches up from a fresh list, and watches from there.
+	errOut.AssertEventuallyContains(t, "Missed some changes while disconnected", time.Second)
+	out.AssertEventuallyContains(t, "my-watch\t2021-03-04T05:06:08Z\t/src/b.go\n", time.Second)
+	assert.Equal(t, "9", <-resourceVersions)
+	assert.NotContains(t, out.String(), "other-watch")
+
+	// The event from the list isn't printed again.
+	<-watches
+	cancel()
+	require.NoError(t, <-done)
+	assert.Equal(t, 1, strings.Count(out.String(), "/src/b.go"))
+}
+
+func fileWatchWithEvent(name string, resourceVersion string, eventTime metav1.MicroTime, seenFiles ...string) *v1alpha1.FileWatch {
+	return &v1alpha1.FileWatch{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "tilt.dev/v1alpha1", Kind: "FileWatch"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, ResourceVersion: resourceVersion},
+		Status: v1alpha1.FileWatchStatus{
+			LastEventTime: eventTime,
+			FileEvents:    []v1alpha1.FileEvent{{Time: eventTime, SeenFiles: seenFiles}},
+		},
+	}
+}
+
+func unstructuredFileWatch(t *testing.T, fw *v1alpha1.FileWatch) *unstructured.Unstructured {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(fw)
+	require.NoError(t, err)
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func (f *serverFixture) createFileWatch(name string) {
+	err := f.client.Create(f.ctx, &v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1alpha1.FileWatchSpec{
+			WatchedPaths: []string{f.Path()},
+		},
+	})
+	require.NoError(f.T(), err)
+}
+
+// The names of the FileWatches in the session, sorted.
+func (f *serverFixture) fileWatchNames() []string {
+	var fws v1alpha1.FileWatchList
+	err := f.client.List(f.ctx, &fws)
+	require.NoError(f.T(), err)
+	names := []string{}
+	for _, fw := range fws.Items {
+		names = append(names, fw.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (f *serverFixture) recordFileEvent(name string, eventTime metav1.MicroTime, seenFiles ...string) {
+	var fw v1alpha1.FileWatch
+	err := f.client.Get(f.ctx, types.NamespacedName{Name: name}, &fw)
+	require.NoError(f.T(), err)
+
+	fw.Status.LastEventTime = eventTime
+	fw.Status.FileEvents = append(fw.Status.FileEvents, v1alpha1.FileEvent{Time: eventTime, SeenFiles: seenFiles})
+	err = f.client.Status().Update(f.ctx, &fw)
+	require.NoError(f.T(), err, fmt.Sprintf("updating status of %s", name))
+}
+
+func TestFileChangesPrinter(t *testing.T) {
+	t1 := metav1.NewMicroTime(time.Date(2021, time.March, 4, 5, 6, 7, 0, time.UTC))
+	t2 := metav1.NewMicroTime(time.Date(2021, time.March, 4, 5, 6, 8, 0, time.UTC))
+	fw := &v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-watch"},
+		Status: v1alpha1.FileWatchStatus{
+			FileEvents: []v1alpha1.FileEvent{{Time: t1, SeenFiles: []string{"/src/a.go"}}},
+		},
+	}
+
+	p := newFileChangesPrinter()
+	out := bytes.NewBuffer(nil)
+	printFileChanges(t, p, fw, out)
+	assert.Equal(t, "2021-03-04T05:06:07Z\t/src/a.go\n", out.String())
+
+	out.Reset()
+	fw.Status.FileEvents = append(fw.Status.FileEvents, v1alpha1.FileEvent{Time: t2, SeenFiles: []string{"/src/b.go"}})
+	printFileChanges(t, p, fw, out)
+	assert.Equal(t, "2021-03-04T05:06:08Z\t/src/b.go\n", out.String())
+
+	out.Reset()
+	printFileChanges(t, p, fw, out)
+	assert.Empty(t, out.String())
+}
+
+func TestFileChangesJSONPrinter(t *testing.T) {
+	eventTime := metav1.NewMicroTime(time.Date(2021, time.March, 4, 5, 6, 7, 8000, time.FixedZone("EST", -5*60*60)))
+	fw := &v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-watch", ResourceVersion: "42"},
+		Status: v1alpha1.FileWatchStatus{
+			FileEvents: []v1alpha1.FileEvent{{Time: eventTime, SeenFiles: []string{"/src/a.go", "/src/\"quoted\".go"}}},
+		},
+	}
+
+	p := newFileChangesJSONPrinter()
+	out := bytes.NewBuffer(nil)
+	printFileChanges(t, p, fw, out)
+	assert.Equal(t,
+		`{"name":"my-watch","path":"/src/a.go","time":"2021-03-04T10:06:07.000008Z","resourceVersion":"42"}`+"\n"+
+			`{"name":"my-watch","path":"/src/\"quoted\".go","time":"2021-03-04T10:06:07.000008Z","resourceVersion":"42"}`+"\n",
+		out.String())
+
+	out.Reset()
+	printFileChanges(t, p, fw, out)
+	assert.Empty(t, out.String())
+}
+
+func TestGetFileWatchEventsAsJSONNeedsWatch(t *testing.T) {
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	cmd := newGetFileWatchCmd(streams)
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--output-events-as=json"})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	err = cmd.run(ctx, c.Flags().Args())
+	assert.EqualError(t, err, "--output-events-as can only be used with --watch")
+}
+
+func printFileChanges(t *testing.T, p *fileChangesPrinter, fw *v1alpha1.FileWatch, out *bytes.Buffer) {
+	t.Helper()
+	u, err := toUnstructured(fw)
+	require.NoError(t, err)
+	require.NoError(t, p.PrintObj(u, out))
+}
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -68,6 +69,29 @@ func (p *staticConnProvider) Listen(network, address string) (net.Listener, erro
 	return p.l, nil
 }
 
+// The FileWatch spellings of the kubectl resource types stay with the
+// generic command, with all of its flags.
+func TestGetFileWatchTypeIsGeneric(t *testing.T) {
+	root := &cobra.Command{Use: "tilt"}
+	cmd := newGetCmd(genericclioptions.IOStreams{}).register()
+	root.AddCommand(cmd)
+	for _, args := range [][]string{
+		{"fw", "a", "b"},
+		{"filewatch", "--no-headers"},
+		{"filewatches", "-A"},
+	} {
+		found, _, err := root.Find(append([]string{"get"}, args...))
+		require.NoError(t, err)
+		assert.Equal(t, cmd, found, "args: %v", args)
+	}
+
+	for _, name := range []string{"filewatch-changes", "fw-changes"} {
+		found, _, err := root.Find([]string{"get", name, "src"})
+		require.NoError(t, err)
+		assert.Equal(t, "filewatch-changes", found.Name())
+	}
+}
+
 type serverFixture struct {
 	*tempdir.TempDirFixture
 	ctx       context.Context
@@ -75,6 +99,7 @@ type serverFixture struct {
 	hudsc     *server.HeadsUpServerController
 	client    ctrlclient.Client
 	analytics *analytics.MemoryAnalytics
+	apiPort   int
 
 	origPort int
 }
@@ -127,6 +152,7 @@ func newServerFixture(t *testing.T) *serverFixture {
 		client:         client,
 		origPort:       origPort,
 		analytics:      a,
+		apiPort:        apiPort,
 	}
 
 	t.Cleanup(ret.TearDown)
@@ -56,6 +56,17 @@ func (t triggerCmd) run(ctx context.Context, args []string) error {
 	a.Incr("cmd.trigger", make(analytics2.CmdTags))
 	defer a.Flush(time.Second)
 
+	err := postTrigger(resource)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(t.streams.Out, "Successfully triggered update for resource: %q\n", resource)
+	return nil
+}
+
+// Asks the tilt session to update a resource, as if triggered from the CLI.
+func postTrigger(resource string) error {
 	// TODO(maia): this should probably be the triggerPayload struct, but seems
 	//   like a lot of code to move over (to avoid import cycles) for one call.
 	payload := []byte(fmt.Sprintf(`{"manifest_names":[%q], "build_reason": %d}`, resource, model.BuildReasonFlagTriggerCLI))
@@ -75,7 +86,5 @@ func (t triggerCmd) run(ctx context.Context, args []string) error {
 	if len(body) > 0 {
 		return errors.New(body)
 	}
-
-	_, _ = fmt.Fprintf(t.streams.Out, "Successfully triggered update for resource: %q\n", resource)
 	return nil
 }
@@ -123,6 +123,7 @@ var BaseWireSet = wire.NewSet(
 	engineanalytics.ProvideAnalyticsReporter,
 	provideUpdateModeFlag,
 	fsevent.ProvideWatcherMaker,
+	fsevent.ProvidePollWatcherMaker,
 	fsevent.ProvideTimerMaker,
 
 	controllers.WireSet,
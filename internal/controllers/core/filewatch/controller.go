@@ -15,6 +15,7 @@ package filewatch
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"sync"
 	"time"
 
@@ -39,6 +40,7 @@ import (
 	"github.com/tilt-dev/tilt/pkg/apis"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 // Controller reconciles a FileWatch object
@@ -46,25 +48,29 @@ type Controller struct {
 	ctrlclient.Client
 	Store store.RStore
 
-	targetWatches  map[types.NamespacedName]*watcher
-	fsWatcherMaker fsevent.WatcherMaker
-	timerMaker     fsevent.TimerMaker
-	mu             sync.Mutex
-	clock          clockwork.Clock
-	indexer        *indexer.Indexer
-	requeuer       *indexer.Requeuer
+	targetWatches    map[types.NamespacedName]*watcher
+	fsWatcherMaker   fsevent.WatcherMaker
+	pollWatcherMaker fsevent.PollWatcherMaker
+	timerMaker       fsevent.TimerMaker
+	mu               sync.Mutex
+	clock            clockwork.Clock
+	indexer          *indexer.Indexer
+	requeuer         *indexer.Requeuer
+	httpClient       *http.Client
 }
 
-func NewController(client ctrlclient.Client, store store.RStore, fsWatcherMaker fsevent.WatcherMaker, timerMaker fsevent.TimerMaker, scheme *runtime.Scheme, clock clockwork.Clock) *Controller {
+func NewController(client ctrlclient.Client, store store.RStore, fsWatcherMaker fsevent.WatcherMaker, pollWatcherMaker fsevent.PollWatcherMaker, timerMaker fsevent.TimerMaker, scheme *runtime.Scheme, clock clockwork.Clock) *Controller {
 	return &Controller{
-		Client:         client,
-		Store:          store,
-		targetWatches:  make(map[types.NamespacedName]*watcher),
-		fsWatcherMaker: fsWatcherMaker,
-		timerMaker:     timerMaker,
-		indexer:        indexer.NewIndexer(scheme, indexFw),
-		requeuer:       indexer.NewRequeuer(),
-		clock:          clock,
+		Client:           client,
+		Store:            store,
+		targetWatches:    make(map[types.NamespacedName]*watcher),
+		fsWatcherMaker:   fsWatcherMaker,
+		pollWatcherMaker: pollWatcherMaker,
+		timerMaker:       timerMaker,
+		indexer:          indexer.NewIndexer(scheme, indexFw),
+		requeuer:         indexer.NewRequeuer(),
+		clock:            clock,
+		httpClient:       &http.Client{Timeout: urlCheckTimeout},
 	}
 }
 
@@ -91,6 +97,16 @@ func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, nil
 	}
 
+	ttl, expires := c.timeToExpiry(ctx, &fw)
+	if expires && ttl <= 0 {
+		// Deleting the FileWatch reconciles it again, which stops the watch.
+		err := c.Client.Delete(ctx, &fw)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
 	// The apiserver is the source of truth, and will ensure the engine state is up to date.
 	c.Store.Dispatch(filewatches.NewFileWatchUpsertAction(&fw))
 
@@ -133,9 +149,29 @@ func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, err
 	}
 
+	if expires && (result.RequeueAfter == 0 || ttl < result.RequeueAfter) {
+		result.RequeueAfter = ttl
+	}
 	return result, nil
 }
 
+// How long until a FileWatch expires, according to its tilt.dev/expires-at annotation.
+//
+// Returns false if it never expires. A malformed annotation is logged and ignored.
+func (c *Controller) timeToExpiry(ctx context.Context, fw *v1alpha1.FileWatch) (time.Duration, bool) {
+	value, ok := fw.Annotations[v1alpha1.AnnotationExpiresAt]
+	if !ok {
+		return 0, false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		logger.Get(ctx).Debugf("filewatch %s: ignoring malformed %s annotation %q: %v",
+			fw.Name, v1alpha1.AnnotationExpiresAt, value, err)
+		return 0, false
+	}
+	return expiresAt.Sub(c.clock.Now()), true
+}
+
 func (c *Controller) maybeUpdateObjectStatus(ctx context.Context, fw *v1alpha1.FileWatch, newStatus *v1alpha1.FileWatchStatus) error {
 	if apicmp.DeepEqual(newStatus, &fw.Status) {
 		return nil
@@ -186,27 +222,36 @@ func (c *Controller) addOrReplace(ctx context.Context, name types.NamespacedName
 		clock:          c.clock,
 		restartBackoff: time.Second,
 	}
-	if hasExisting && apicmp.DeepEqual(existing.spec, w.spec) {
+	sameSpec := hasExisting && apicmp.DeepEqual(existing.spec, w.spec)
+	if sameSpec {
 		w.restartBackoff = existing.restartBackoff
 		status.Error = existing.status.Error
 	}
 
 	ignoreMatcher := ignore.CreateFileChangeFilter(fw.Spec.Ignores)
+	if fw.Spec.NonRecursive {
+		ignoreMatcher = model.NewCompositeMatcher([]model.PathMatcher{
+			ignoreMatcher, nonRecursiveMatcher{watchedPaths: fw.Spec.WatchedPaths},
+		})
+	}
 	startFileChangeLoop := false
-	notify, err := c.fsWatcherMaker(
-		append([]string{}, fw.Spec.WatchedPaths...),
-		ignoreMatcher,
-		logger.Get(ctx))
-	if err != nil {
-		status.Error = fmt.Sprintf("filewatch init: %v", err)
-	} else if err := notify.Start(); err != nil {
-		status.Error = fmt.Sprintf("filewatch init: %v", err)
-
-		// Close the notify immediately, but don't add it to the watcher object. The
-		// watcher object is still needed to handle backoff.
-		_ = notify.Close()
-	} else {
-		startFileChangeLoop = true
+	// In url mode, there are no local paths to watch.
+	startURLLoop := fw.Spec.Mode == v1alpha1.FileWatchModeURL
+	var notify watch.Notify
+	if !startURLLoop {
+		var err error
+		notify, err = c.newNotify(ctx, fw.Spec, ignoreMatcher)
+		if err != nil {
+			status.Error = fmt.Sprintf("filewatch init: %v", err)
+		} else if err := notify.Start(); err != nil {
+			status.Error = fmt.Sprintf("filewatch init: %v", err)
+
+			// Close the notify immediately, but don't add it to the watcher object. The
+			// watcher object is still needed to handle backoff.
+			_ = notify.Close()
+		} else {
+			startFileChangeLoop = true
+		}
 	}
 
 	if hasExisting {
@@ -217,18 +262,79 @@ func (c *Controller) addOrReplace(ctx context.Context, name types.NamespacedName
 	ctx, cancel := context.WithCancel(ctx)
 	w.cancel = cancel
 
+	if startFileChangeLoop || startURLLoop {
+		status.MonitorStartTime = apis.NowMicro()
+	}
+	// The loops record their events and errors in the status, so it's
+	// set before they start.
+	w.status = status
+	c.targetWatches[name] = w
+
 	if startFileChangeLoop {
 		w.notify = notify
-		status.MonitorStartTime = apis.NowMicro()
 		go c.dispatchFileChangesLoop(ctx, w)
 	}
+	if startURLLoop {
+		go c.pollURLsLoop(ctx, w)
+	}
 
-	w.status = status
-	c.targetWatches[name] = w
+	// Only seed the status when the spec changes, so that restarting
+	// the watcher doesn't report the same files again.
+	if startFileChangeLoop && !sameSpec && !fw.Spec.Since.IsZero() {
+		seen, err := filesChangedSince(fw.Spec.WatchedPaths, ignoreMatcher, fw.Spec.Since.Time)
+		if err != nil {
+			w.recordError(fmt.Errorf("filewatch since: %v", err))
+		} else {
+			w.recordEvent(seen)
+		}
+	}
+}
+
+// Creates a filesystem watcher with the backend selected by the spec's mode.
+func (c *Controller) newNotify(ctx context.Context, spec v1alpha1.FileWatchSpec, ignoreMatcher watch.PathMatcher) (watch.Notify, error) {
+	paths := append([]string{}, spec.WatchedPaths...)
+	var symlinks []symlinkTarget
+	if spec.FollowSymlinks {
+		var err error
+		symlinks, err = findSymlinkTargets(spec.WatchedPaths, ignoreMatcher)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range symlinks {
+			paths = append(paths, s.target)
+		}
+	}
+
+	var notify watch.Notify
+	var err error
+	if spec.Mode == v1alpha1.FileWatchModePoll {
+		notify, err = c.pollWatcherMaker(paths, ignoreMatcher, spec.PollInterval.Duration, logger.Get(ctx))
+	} else {
+		notify, err = c.fsWatcherMaker(paths, ignoreMatcher, logger.Get(ctx))
+	}
+	if err != nil || len(symlinks) == 0 {
+		return notify, err
+	}
+	return newSymlinkNotify(notify, symlinks, ignoreMatcher), nil
 }
 
 func (c *Controller) dispatchFileChangesLoop(ctx context.Context, w *watcher) {
-	eventsCh := fsevent.Coalesce(c.timerMaker, w.notify.Events())
+	eventsCh := fsevent.Coalesce(c.timerMaker, w.spec.DebounceDuration.Duration, int(w.spec.MaxEvents), w.notify.Events())
+
+	// Without a heartbeat interval, the nil channel never fires.
+	var heartbeatCh <-chan time.Time
+	if w.spec.HeartbeatInterval.Duration > 0 {
+		ticker := c.clock.NewTicker(w.spec.HeartbeatInterval.Duration)
+		defer ticker.Stop()
+		heartbeatCh = ticker.Chan()
+	}
+
+	// Without a rate limit, events are reported as soon as they're coalesced.
+	var limiter *rateLimiter
+	if w.spec.RateLimitEvents > 0 {
+		limiter = newRateLimiter(c.clock, w.spec.RateLimitEvents, w.spec.RateLimitWindow.Duration)
+		defer limiter.stop()
+	}
 
 	defer func() {
 		c.mu.Lock()
@@ -266,8 +372,24 @@ func (c *Controller) dispatchFileChangesLoop(ctx context.Context, w *watcher) {
 			if !ok {
 				return
 			}
+			if limiter != nil {
+				fsEvents = limiter.add(fsEvents)
+				if len(fsEvents) == 0 {
+					continue
+				}
+			}
 			w.recordEvent(fsEvents)
 			c.requeuer.Add(w.name)
+		case <-limiter.Chan():
+			fsEvents := limiter.fire()
+			if len(fsEvents) == 0 {
+				continue
+			}
+			w.recordEvent(fsEvents)
+			c.requeuer.Add(w.name)
+		case <-heartbeatCh:
+			w.recordHeartbeat()
+			c.requeuer.Add(w.name)
 		}
 	}
 }
@@ -1,12 +1,17 @@
 package filewatch
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -72,7 +77,7 @@ func newFixture(t *testing.T) *fixture {
 	cfb := fake.NewControllerFixtureBuilder(t)
 	testingStore := NewTestingStore(cfb.OutWriter())
 	clock := clockwork.NewFakeClock()
-	controller := NewController(cfb.Client, testingStore, fakeMultiWatcher.NewSub, timerMaker.Maker(), filewatches.NewScheme(), clock)
+	controller := NewController(cfb.Client, testingStore, fakeMultiWatcher.NewSub, fakeMultiWatcher.NewPollSub, timerMaker.Maker(), filewatches.NewScheme(), clock)
 
 	return &fixture{
 		ControllerFixture: cfb.WithRequeuer(controller.requeuer).Build(controller),
@@ -288,6 +293,50 @@ func TestController_Reconcile_Delete(t *testing.T) {
 	require.Empty(t, f.controller.targetWatches, "There should not be any remaining file watchers")
 }
 
+func TestController_Reconcile_Expired(t *testing.T) {
+	f := newFixture(t)
+	fw := &filewatches.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: apis.SanitizeName(t.Name()),
+			Name:      "test-file-watch",
+			Annotations: map[string]string{
+				filewatches.AnnotationExpiresAt: f.clock.Now().Add(time.Minute).Format(time.RFC3339),
+			},
+		},
+		Spec: filewatches.FileWatchSpec{
+			WatchedPaths: []string{f.tmpdir.JoinPath("a")},
+		},
+	}
+	result := f.Create(fw)
+	key := f.KeyForObject(fw)
+	assert.Equal(t, time.Minute, result.RequeueAfter)
+	require.Len(t, f.controller.targetWatches, 1)
+
+	f.clock.Advance(time.Minute)
+	f.reconcileFw(key)
+	assert.False(t, f.Get(key, &filewatches.FileWatch{}), "FileWatch was not deleted")
+
+	f.reconcileFw(key)
+	assert.Empty(t, f.controller.targetWatches, "There should not be any remaining file watchers")
+}
+
+func TestController_Reconcile_ExpiresAtMalformed(t *testing.T) {
+	f := newFixture(t)
+	fw := &filewatches.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   apis.SanitizeName(t.Name()),
+			Name:        "test-file-watch",
+			Annotations: map[string]string{filewatches.AnnotationExpiresAt: "tomorrow"},
+		},
+		Spec: filewatches.FileWatchSpec{
+			WatchedPaths: []string{f.tmpdir.JoinPath("a")},
+		},
+	}
+	result := f.Create(fw)
+	assert.Zero(t, result.RequeueAfter)
+	assert.True(t, f.Get(f.KeyForObject(fw), &filewatches.FileWatch{}))
+}
+
 func TestController_Reconcile_Watches(t *testing.T) {
 	f := newFixture(t)
 	key, fw := f.CreateSimpleFileWatch()
@@ -378,6 +427,461 @@ func TestCreateSubError(t *testing.T) {
 	assert.Contains(t, fw.Status.Error, "filewatch init: Unusual watcher error")
 }
 
+func TestPollMode(t *testing.T) {
+	f := newFixture(t)
+	var pollInterval time.Duration
+	maker := f.controller.pollWatcherMaker
+	f.controller.pollWatcherMaker = fsevent.PollWatcherMaker(func(paths []string, ignore watch.PathMatcher, interval time.Duration, l logger.Logger) (watch.Notify, error) {
+		pollInterval = interval
+		return maker(paths, ignore, interval, l)
+	})
+	f.controller.fsWatcherMaker = fsevent.WatcherMaker(func(paths []string, ignore watch.PathMatcher, _ logger.Logger) (watch.Notify, error) {
+		return nil, fmt.Errorf("notify watcher should not be used in poll mode")
+	})
+
+	fw := &filewatches.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: apis.SanitizeName(t.Name()),
+			Name:      "test-file-watch",
+		},
+		Spec: filewatches.FileWatchSpec{
+			WatchedPaths: []string{f.tmpdir.JoinPath("a")},
+			Mode:         filewatches.FileWatchModePoll,
+			PollInterval: metav1.Duration{Duration: 2 * time.Second},
+		},
+	}
+	f.Create(fw)
+
+	var actual filewatches.FileWatch
+	f.MustGet(f.KeyForObject(fw), &actual)
+	assert.Empty(t, actual.Status.Error)
+	assert.False(t, actual.Status.MonitorStartTime.IsZero())
+	assert.Equal(t, 2*time.Second, pollInterval)
+}
+
+func TestController_MaxEvents(t *testing.T) {
+	f := newFixture(t)
+
+	// hold the timers, so that only MaxEvents can end a batch
+	f.fakeTimerMaker.RestTimerLock.Lock()
+	f.fakeTimerMaker.MaxTimerLock.Lock()
+	t.Cleanup(func() {
+		f.fakeTimerMaker.RestTimerLock.Unlock()
+		f.fakeTimerMaker.MaxTimerLock.Unlock()
+	})
+
+	fw := &filewatches.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: apis.SanitizeName(t.Name()),
+			Name:      "test-file-watch",
+		},
+		Spec: filewatches.FileWatchSpec{
+			WatchedPaths: []string{f.tmpdir.JoinPath("a")},
+			MaxEvents:    2,
+		},
+	}
+	f.Create(fw)
+	key := f.KeyForObject(fw)
+
+	for i := 0; i < 4; i++ {
+		f.ChangeFile("a", strconv.Itoa(i))
+	}
+	f.WaitForSeenFile(key, "a", "3")
+
+	var actual filewatches.FileWatch
+	f.MustGet(key, &actual)
+	require.Equal(t, 2, len(actual.Status.FileEvents), "Wrong file event count")
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "0"), f.tmpdir.JoinPath("a", "1")},
+		actual.Status.FileEvents[0].SeenFiles)
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "2"), f.tmpdir.JoinPath("a", "3")},
+		actual.Status.FileEvents[1].SeenFiles)
+}
+
+func TestController_Since(t *testing.T) {
+	f := newFixture(t)
+
+	since := time.Now().Add(-time.Minute)
+	f.tmpdir.WriteFile(filepath.Join("a", "old.txt"), "old")
+	f.tmpdir.WriteFile(filepath.Join("a", "new.txt"), "new")
+	f.tmpdir.WriteFile(filepath.Join("a", "ignored.txt"), "new")
+	old := since.Add(-time.Hour)
+	require.NoError(t, os.Chtimes(f.tmpdir.JoinPath("a", "old.txt"), old, old))
+
+	fw := &filewatches.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: apis.SanitizeName(t.Name()),
+			Name:      "test-file-watch",
+		},
+		Spec: filewatches.FileWatchSpec{
+			WatchedPaths: []string{f.tmpdir.JoinPath("a")},
+			Ignores: []filewatches.IgnoreDef{
+				{BasePath: f.tmpdir.JoinPath("a"), Patterns: []string{"ignored.txt"}},
+			},
+			Since: metav1.NewMicroTime(since),
+		},
+	}
+	f.Create(fw)
+
+	var actual filewatches.FileWatch
+	f.MustGet(f.KeyForObject(fw), &actual)
+	require.Equal(t, 1, len(actual.Status.FileEvents), "Wrong file event count")
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "new.txt")}, actual.Status.FileEvents[0].SeenFiles)
+	assert.False(t, actual.Status.LastEventTime.IsZero())
+}
+
+func TestController_SinceNothingChanged(t *testing.T) {
+	f := newFixture(t)
+
+	f.tmpdir.WriteFile(filepath.Join("a", "old.txt"), "old")
+	fw := &filewatches.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: apis.SanitizeName(t.Name()),
+			Name:      "test-file-watch",
+		},
+		Spec: filewatches.FileWatchSpec{
+			WatchedPaths: []string{f.tmpdir.JoinPath("a"), f.tmpdir.JoinPath("missing")},
+			Since:        metav1.NewMicroTime(time.Now().Add(time.Hour)),
+		},
+	}
+	f.Create(fw)
+
+	var actual filewatches.FileWatch
+	f.MustGet(f.KeyForObject(fw), &actual)
+	assert.Empty(t, actual.Status.Error)
+	assert.Empty(t, actual.Status.FileEvents)
+}
+
+func TestController_NonRecursive(t *testing.T) {
+	f := newFixture(t)
+
+	since := time.Now().Add(-time.Minute)
+	f.tmpdir.WriteFile(filepath.Join("a", "top.txt"), "new")
+	f.tmpdir.WriteFile(filepath.Join("a", "sub", "deep.txt"), "new")
+
+	fw := &filewatches.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: apis.SanitizeName(t.Name()),
+			Name:      "test-file-watch",
+		},
+		Spec: filewatches.FileWatchSpec{
+			WatchedPaths: []string{f.tmpdir.JoinPath("a")},
+			Since:        metav1.NewMicroTime(since),
+			NonRecursive: true,
+		},
+	}
+	f.Create(fw)
+
+	var actual filewatches.FileWatch
+	f.MustGet(f.KeyForObject(fw), &actual)
+	require.Equal(t, 1, len(actual.Status.FileEvents), "Wrong file event count")
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "top.txt")}, actual.Status.FileEvents[0].SeenFiles)
+}
+
+func TestController_Heartbeat(t *testing.T) {
+	f := newFixture(t)
+
+	fw := &filewatches.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: apis.SanitizeName(t.Name()),
+			Name:      "test-file-watch",
+		},
+		Spec: filewatches.FileWatchSpec{
+			WatchedPaths:      []string{f.tmpdir.JoinPath("a")},
+			HeartbeatInterval: metav1.Duration{Duration: time.Minute},
+		},
+	}
+	f.Create(fw)
+	key := f.KeyForObject(fw)
+
+	var actual filewatches.FileWatch
+	f.MustGet(key, &actual)
+	assert.Nil(t, actual.Status.LastHeartbeatTime)
+
+	// Wait for the heartbeat ticker to start before moving the clock.
+	f.clock.BlockUntil(1)
+	f.clock.Advance(time.Minute)
+	expected := f.clock.Now()
+	require.Eventually(t, func() bool {
+		var fw filewatches.FileWatch
+		return f.Get(key, &fw) && fw.Status.LastHeartbeatTime != nil &&
+			fw.Status.LastHeartbeatTime.Time.Equal(expected)
+	}, timeout, interval)
+
+	f.MustGet(key, &actual)
+	assert.Empty(t, actual.Status.FileEvents)
+	assert.True(t, actual.Status.LastEventTime.IsZero())
+}
+
+func TestController_RateLimit(t *testing.T) {
+	f := newFixture(t)
+
+	fw := &filewatches.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: apis.SanitizeName(t.Name()),
+			Name:      "test-file-watch",
+		},
+		Spec: filewatches.FileWatchSpec{
+			WatchedPaths:    []string{f.tmpdir.JoinPath("a")},
+			RateLimitEvents: 1,
+			RateLimitWindow: metav1.Duration{Duration: time.Minute},
+		},
+	}
+	f.Create(fw)
+	key := f.KeyForObject(fw)
+
+	f.ChangeAndWaitForSeenFile(key, "a", "0")
+
+	// The first event took the only token, so the next one is held
+	// until the window passes.
+	f.ChangeFile("a", "1")
+	f.clock.BlockUntil(1)
+	var actual filewatches.FileWatch
+	f.MustGet(key, &actual)
+	require.Equal(t, 1, len(actual.Status.FileEvents), "Wrong file event count")
+
+	f.clock.Advance(time.Minute)
+	f.WaitForSeenFile(key, "a", "1")
+	f.MustGet(key, &actual)
+	require.Equal(t, 2, len(actual.Status.FileEvents), "Wrong file event count")
+	assert.Equal(t, []string{f.tmpdir.JoinPath("a", "1")}, actual.Status.FileEvents[1].SeenFiles)
+}
+
+func TestRateLimiter(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	clock := clockwork.NewFakeClock()
+	l := newRateLimiter(clock, 2, time.Minute)
+	events := func(paths ...string) []watch.FileEvent {
+		var result []watch.FileEvent
+		for _, p := range paths {
+			result = append(result, watch.NewFileEvent(f.JoinPath(p)))
+		}
+		return result
+	}
+
+	// A burst of up to 2 events is reported right away.
+	assert.Equal(t, events("a"), l.add(events("a")))
+	assert.Equal(t, events("b"), l.add(events("b")))
+	assert.Nil(t, l.Chan())
+
+	// Then events are held, and merged until the next token comes in
+	// after 30s.
+	assert.Empty(t, l.add(events("c")))
+	assert.Empty(t, l.add(events("d")))
+	require.NotNil(t, l.Chan())
+	clock.Advance(29 * time.Second)
+	select {
+	case <-l.Chan():
+		t.Fatal("rate limiter fired early")
+	default:
+	}
+	clock.Advance(time.Second)
+	<-l.Chan()
+	assert.Equal(t, events("c", "d"), l.fire())
+	assert.Nil(t, l.Chan())
+
+	// After a quiet window, the bucket is full again, but no fuller.
+	clock.Advance(10 * time.Minute)
+	assert.Equal(t, events("e"), l.add(events("e")))
+	assert.Equal(t, events("f"), l.add(events("f")))
+	assert.Empty(t, l.add(events("g")))
+}
+
+func TestController_URLMode(t *testing.T) {
+	f := newFixture(t)
+
+	var mu sync.Mutex
+	etag := `"v1"`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("ETag", etag)
+	}))
+	t.Cleanup(srv.Close)
+
+	fw := &filewatches.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: apis.SanitizeName(t.Name()),
+			Name:      "test-file-watch",
+		},
+		Spec: filewatches.FileWatchSpec{
+			Mode:         filewatches.FileWatchModeURL,
+			URLs:         []string{srv.URL + "/a"},
+			PollInterval: metav1.Duration{Duration: time.Minute},
+		},
+	}
+	f.Create(fw)
+	key := f.KeyForObject(fw)
+
+	// Wait for the first check, which only records the ETag.
+	f.clock.BlockUntil(1)
+	var actual filewatches.FileWatch
+	f.MustGet(key, &actual)
+	assert.False(t, actual.Status.MonitorStartTime.IsZero())
+	assert.Empty(t, actual.Status.FileEvents)
+
+	mu.Lock()
+	etag = `"v2"`
+	mu.Unlock()
+	f.clock.Advance(time.Minute)
+	require.Eventually(t, func() bool {
+		var fw filewatches.FileWatch
+		return f.Get(key, &fw) && len(fw.Status.FileEvents) == 1
+	}, timeout, interval)
+
+	f.MustGet(key, &actual)
+	assert.Equal(t, []string{srv.URL + "/a"}, actual.Status.FileEvents[0].SeenFiles)
+	assert.Equal(t, "", actual.Status.Error)
+}
+
+func TestController_URLModeFirstCheckFails(t *testing.T) {
+	f := newFixture(t)
+
+	// Nothing listens at the URL, so the first check fails right away.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := srv.URL + "/a"
+	srv.Close()
+
+	fw := &filewatches.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: apis.SanitizeName(t.Name()),
+			Name:      "test-file-watch",
+		},
+		Spec: filewatches.FileWatchSpec{
+			Mode:         filewatches.FileWatchModeURL,
+			URLs:         []string{url},
+			PollInterval: metav1.Duration{Duration: time.Minute},
+		},
+	}
+	f.Create(fw)
+	key := f.KeyForObject(fw)
+
+	require.Eventually(t, func() bool {
+		var fw filewatches.FileWatch
+		return f.Get(key, &fw) && fw.Status.Error != ""
+	}, timeout, interval)
+
+	var actual filewatches.FileWatch
+	f.MustGet(key, &actual)
+	assert.Contains(t, actual.Status.Error, "checking "+url)
+	assert.False(t, actual.Status.MonitorStartTime.IsZero())
+	assert.Empty(t, actual.Status.FileEvents)
+}
+
+func TestCheckURLs(t *testing.T) {
+	headers := map[string]http.Header{
+		"/etag":     {"Etag": []string{`"v1"`}},
+		"/modified": {"Last-Modified": []string{"Mon, 02 Jan 2006 15:04:05 GMT"}},
+		"/none":     {},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, ok := headers[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		for k, v := range h {
+			w.Header()[k] = v
+		}
+	}))
+	t.Cleanup(srv.Close)
+	ctx := context.Background()
+
+	versions := map[string]string{}
+	changed, err := checkURLs(ctx, srv.Client(), []string{srv.URL + "/etag", srv.URL + "/modified"}, versions)
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+
+	headers["/modified"] = http.Header{"Last-Modified": []string{"Tue, 03 Jan 2006 15:04:05 GMT"}}
+	changed, err = checkURLs(ctx, srv.Client(), []string{srv.URL + "/etag", srv.URL + "/modified"}, versions)
+	require.NoError(t, err)
+	assert.Equal(t, []string{srv.URL + "/modified"}, changed)
+
+	_, err = checkURLs(ctx, srv.Client(), []string{srv.URL + "/none", srv.URL + "/missing"}, versions)
+	assert.EqualError(t, err, fmt.Sprintf(
+		"checking %s/none: no ETag or Last-Modified header, so changes can't be detected\n"+
+			"checking %s/missing: 404 Not Found", srv.URL, srv.URL))
+}
+
+func TestController_FollowSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no user-space symlinks on windows")
+	}
+	f := newFixture(t)
+
+	f.tmpdir.WriteFile(filepath.Join("a", "main.go"), "package main")
+	f.tmpdir.WriteFile(filepath.Join("real", "lib.go"), "package lib")
+	require.NoError(t, os.Symlink(f.tmpdir.JoinPath("real"), f.tmpdir.JoinPath("a", "link")))
+
+	fw := &filewatches.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: apis.SanitizeName(t.Name()),
+			Name:      "test-file-watch",
+		},
+		Spec: filewatches.FileWatchSpec{
+			WatchedPaths:   []string{f.tmpdir.JoinPath("a")},
+			FollowSymlinks: true,
+		},
+	}
+	f.Create(fw)
+	key := f.KeyForObject(fw)
+
+	f.ChangeFile("real", "lib.go")
+	f.WaitForSeenFile(key, "a", "link", "lib.go")
+}
+
+func TestFindSymlinkTargetsCycles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no user-space symlinks on windows")
+	}
+	f := tempdir.NewTempDirFixture(t)
+
+	f.WriteFile(filepath.Join("a", "main.go"), "package main")
+	f.WriteFile(filepath.Join("real", "lib.go"), "package lib")
+	require.NoError(t, os.Symlink(f.JoinPath("real"), f.JoinPath("a", "link")))
+	require.NoError(t, os.Symlink(f.JoinPath("a"), f.JoinPath("a", "self")))
+	require.NoError(t, os.Symlink(f.Path(), f.JoinPath("a", "parent")))
+	require.NoError(t, os.Symlink(f.JoinPath("a"), f.JoinPath("real", "back")))
+	require.NoError(t, os.Symlink(f.JoinPath("real"), f.JoinPath("real", "again")))
+	require.NoError(t, os.Symlink(f.JoinPath("missing"), f.JoinPath("a", "dangling")))
+
+	root, err := filepath.EvalSymlinks(f.Path())
+	require.NoError(t, err)
+	targets, err := findSymlinkTargets([]string{filepath.Join(root, "a")}, watch.EmptyMatcher{})
+	require.NoError(t, err)
+	assert.Equal(t, []symlinkTarget{
+		{link: filepath.Join(root, "a", "link"), target: filepath.Join(root, "real")},
+	}, targets)
+}
+
+func TestSymlinkNotifyLinkPath(t *testing.T) {
+	n := newSymlinkNotify(nil, []symlinkTarget{
+		{link: "/other/lib/vendor", target: "/vendor"},
+		{link: "/src/lib", target: "/other/lib"},
+	}, watch.EmptyMatcher{})
+
+	assert.Equal(t, "/src/main.go", n.linkPath("/src/main.go"))
+	assert.Equal(t, "/src/lib/lib.go", n.linkPath("/other/lib/lib.go"))
+	assert.Equal(t, "/src/lib/vendor/dep.go", n.linkPath("/vendor/dep.go"))
+}
+
+func TestNonRecursiveMatcher(t *testing.T) {
+	m := nonRecursiveMatcher{watchedPaths: []string{"/src", "/web/index.html"}}
+	for path, expected := range map[string]bool{
+		"/src":              false,
+		"/src/main.go":      false,
+		"/src/pkg":          false,
+		"/src/pkg/util.go":  true,
+		"/src/pkg/a/b.go":   true,
+		"/web/index.html":   false,
+		"/web/other.html":   false,
+		"/elsewhere/x/y.go": false,
+	} {
+		matches, err := m.Matches(path)
+		require.NoError(t, err)
+		assert.Equal(t, expected, matches, path)
+	}
+}
+
 func TestStartSubError(t *testing.T) {
 	f := newFixture(t)
 	maker := f.controller.fsWatcherMaker
@@ -22,7 +22,16 @@ const BufferMaxDuration = 10 * time.Second
 
 // Coalesce makes an attempt to read some events from `eventChan` so that multiple file changes
 // that happen at the same time from the user's perspective are grouped together.
-func Coalesce(timerMaker TimerMaker, eventChan <-chan watch.FileEvent) <-chan []watch.FileEvent {
+//
+// A batch is emitted once `minRestDuration` has passed without seeing a change. If zero,
+// BufferMinRestDuration is used.
+//
+// A batch is also emitted as soon as it has `maxEvents` changes. If zero, batches are unbounded.
+func Coalesce(timerMaker TimerMaker, minRestDuration time.Duration, maxEvents int, eventChan <-chan watch.FileEvent) <-chan []watch.FileEvent {
+	if minRestDuration <= 0 {
+		minRestDuration = BufferMinRestDuration
+	}
+
 	ret := make(chan []watch.FileEvent)
 	go func() {
 		defer close(ret)
@@ -34,14 +43,15 @@ func Coalesce(timerMaker TimerMaker, eventChan <-chan watch.FileEvent) <-chan []
 			}
 			events := []watch.FileEvent{event}
 
-			// keep grabbing changes until we've gone `BufferMinRestDuration` without seeing a change
-			minRestTimer := timerMaker(BufferMinRestDuration)
+			// keep grabbing changes until we've gone `minRestDuration` without seeing a change
+			minRestTimer := timerMaker(minRestDuration)
 
 			// but if we go too long before seeing a break (e.g., a process is constantly writing logs to that dir)
 			// then just send what we've got
 			timeout := timerMaker(BufferMaxDuration)
 
-			done := false
+			// and if we've accumulated too many changes, send them before they pile up
+			done := maxEvents > 0 && len(events) >= maxEvents
 			channelClosed := false
 			for !done && !channelClosed {
 				select {
@@ -49,8 +59,9 @@ func Coalesce(timerMaker TimerMaker, eventChan <-chan watch.FileEvent) <-chan []
 					if !ok {
 						channelClosed = true
 					} else {
-						minRestTimer = timerMaker(BufferMinRestDuration)
+						minRestTimer = timerMaker(minRestDuration)
 						events = append(events, event)
+						done = maxEvents > 0 && len(events) >= maxEvents
 					}
 				case <-minRestTimer:
 					done = true
@@ -9,12 +9,18 @@ import (
 
 type WatcherMaker func(paths []string, ignore watch.PathMatcher, l logger.Logger) (watch.Notify, error)
 
+type PollWatcherMaker func(paths []string, ignore watch.PathMatcher, interval time.Duration, l logger.Logger) (watch.Notify, error)
+
 type TimerMaker func(d time.Duration) <-chan time.Time
 
 func ProvideWatcherMaker() WatcherMaker {
 	return watch.NewWatcher
 }
 
+func ProvidePollWatcherMaker() PollWatcherMaker {
+	return watch.NewPollingWatcher
+}
+
 func ProvideTimerMaker() TimerMaker {
 	return time.After
 }
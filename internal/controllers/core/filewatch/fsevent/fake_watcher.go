@@ -4,6 +4,7 @@ import (
 	"path/filepath"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/tilt-dev/tilt/internal/ospath"
 	"github.com/tilt-dev/tilt/internal/watch"
@@ -42,6 +43,13 @@ func (w *FakeMultiWatcher) NewSub(paths []string, ignore watch.PathMatcher, _ lo
 	return watcher, nil
 }
 
+// NewPollSub creates a fake watcher for a FileWatch in poll mode.
+//
+// Fake watchers don't touch the filesystem, so this behaves the same as NewSub.
+func (w *FakeMultiWatcher) NewPollSub(paths []string, ignore watch.PathMatcher, _ time.Duration, l logger.Logger) (watch.Notify, error) {
+	return w.NewSub(paths, ignore, l)
+}
+
 func (w *FakeMultiWatcher) getSubs() []chan watch.FileEvent {
 	w.mu.Lock()
 	defer w.mu.Unlock()
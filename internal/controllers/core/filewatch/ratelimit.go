@@ -0,0 +1,102 @@
+package filewatch
+
+import (
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"github.com/tilt-dev/tilt/internal/watch"
+)
+
+// Limits how often a watcher reports file events, per the spec's
+// RateLimitEvents and RateLimitWindow.
+//
+// It's a token bucket that holds up to `capacity` tokens, and gets one
+// back every `interval`. Each reported event takes a token. Events that
+// come in while the bucket is empty are held, and reported together
+// once the next token comes in.
+type rateLimiter struct {
+	clock    clockwork.Clock
+	capacity int
+	interval time.Duration
+	tokens   int
+	refillAt time.Time
+	pending  []watch.FileEvent
+	timer    clockwork.Timer
+}
+
+func newRateLimiter(clock clockwork.Clock, events int32, window time.Duration) *rateLimiter {
+	interval := window / time.Duration(events)
+	if interval <= 0 {
+		interval = time.Nanosecond
+	}
+	return &rateLimiter{
+		clock:    clock,
+		capacity: int(events),
+		interval: interval,
+		tokens:   int(events),
+		refillAt: clock.Now(),
+	}
+}
+
+// Adds the tokens that came in since the last refill.
+func (l *rateLimiter) refill() {
+	now := l.clock.Now()
+	n := int(now.Sub(l.refillAt) / l.interval)
+	if n <= 0 {
+		return
+	}
+	l.tokens += n
+	l.refillAt = l.refillAt.Add(time.Duration(n) * l.interval)
+	if l.tokens >= l.capacity {
+		l.tokens = l.capacity
+		l.refillAt = now
+	}
+}
+
+// Takes a token and returns the held events, if a token is available.
+// Otherwise, starts a timer for when the next one is.
+func (l *rateLimiter) take() []watch.FileEvent {
+	l.refill()
+	if l.tokens > 0 {
+		l.tokens--
+		ready := l.pending
+		l.pending = nil
+		return ready
+	}
+	l.timer = l.clock.NewTimer(l.refillAt.Add(l.interval).Sub(l.clock.Now()))
+	return nil
+}
+
+// Holds events until the limit allows them, and returns the events
+// that may be reported now, if any.
+func (l *rateLimiter) add(events []watch.FileEvent) []watch.FileEvent {
+	l.pending = append(l.pending, events...)
+	if l.timer != nil {
+		// Already waiting for a token.
+		return nil
+	}
+	return l.take()
+}
+
+// Called when the timer fires. Returns the held events, if the limit
+// now allows them.
+func (l *rateLimiter) fire() []watch.FileEvent {
+	l.timer = nil
+	return l.take()
+}
+
+// Fires when held events may be reported. A nil limiter, or one that
+// isn't holding events, never fires.
+func (l *rateLimiter) Chan() <-chan time.Time {
+	if l == nil || l.timer == nil {
+		return nil
+	}
+	return l.timer.Chan()
+}
+
+func (l *rateLimiter) stop() {
+	if l != nil && l.timer != nil {
+		l.timer.Stop()
+	}
+}
@@ -0,0 +1,169 @@
+package filewatch
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/tilt-dev/tilt/internal/ospath"
+	"github.com/tilt-dev/tilt/internal/watch"
+)
+
+// A symlink under the watched paths of a FollowSymlinks FileWatch,
+// and the real path it points to.
+type symlinkTarget struct {
+	link   string
+	target string
+}
+
+// Finds the symlinks under paths whose targets aren't watched yet,
+// including symlinks under those targets.
+//
+// Symlinks to a watched path, or to a directory that contains one, are
+// skipped, so that a symlink cycle doesn't watch the same files twice.
+// So are ignored and dangling symlinks.
+func findSymlinkTargets(paths []string, ignore watch.PathMatcher) ([]symlinkTarget, error) {
+	var result []symlinkTarget
+	watched := []string{}
+	queue := []string{}
+	for _, path := range paths {
+		watched = append(watched, path)
+		real, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			continue
+		}
+		// A watched path may be a symlink itself.
+		if real != path {
+			watched = append(watched, real)
+			result = append(result, symlinkTarget{link: path, target: real})
+		}
+		queue = append(queue, real)
+	}
+
+	for len(queue) > 0 {
+		root := queue[0]
+		queue = queue[1:]
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if entry.IsDir() {
+				skip, err := ignore.MatchesEntireDir(path)
+				if err != nil {
+					return err
+				}
+				if skip {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if entry.Type()&fs.ModeSymlink == 0 {
+				return nil
+			}
+
+			ignored, err := ignore.Matches(path)
+			if err != nil || ignored {
+				return err
+			}
+			target, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				return nil
+			}
+			for _, w := range watched {
+				if ospath.IsChild(w, target) || ospath.IsChild(target, w) {
+					return nil
+				}
+			}
+
+			watched = append(watched, target)
+			result = append(result, symlinkTarget{link: path, target: target})
+			if ospath.IsDir(target) {
+				queue = append(queue, target)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Most specific targets first, so that a path maps to the closest symlink.
+	sort.SliceStable(result, func(i, j int) bool {
+		return len(result[i].target) > len(result[j].target)
+	})
+	return result, nil
+}
+
+// Reports the changes to symlink targets at the paths of their symlinks.
+//
+// The wrapped Notify watches the targets too, but only knows their real paths.
+// Changes that are ignored at the paths of their symlinks are dropped.
+type symlinkNotify struct {
+	watch.Notify
+	symlinks []symlinkTarget
+	ignore   watch.PathMatcher
+	events   chan watch.FileEvent
+}
+
+var _ watch.Notify = &symlinkNotify{}
+
+func newSymlinkNotify(notify watch.Notify, symlinks []symlinkTarget, ignore watch.PathMatcher) *symlinkNotify {
+	return &symlinkNotify{
+		Notify:   notify,
+		symlinks: symlinks,
+		ignore:   ignore,
+		events:   make(chan watch.FileEvent),
+	}
+}
+
+func (n *symlinkNotify) Start() error {
+	err := n.Notify.Start()
+	if err != nil {
+		return err
+	}
+	go n.loop()
+	return nil
+}
+
+func (n *symlinkNotify) Events() chan watch.FileEvent {
+	return n.events
+}
+
+func (n *symlinkNotify) loop() {
+	defer close(n.events)
+	for event := range n.Notify.Events() {
+		path := n.linkPath(event.Path())
+		if path != event.Path() {
+			ignored, err := n.ignore.Matches(path)
+			if err == nil && ignored {
+				continue
+			}
+		}
+		n.events <- watch.NewFileEvent(path)
+	}
+}
+
+// Maps a path under a symlink target to the same path under the symlink.
+//
+// A symlink may itself be under another symlink's target, so this repeats
+// until the path is under a watched path, with at most one step per symlink.
+func (n *symlinkNotify) linkPath(path string) string {
+	for range n.symlinks {
+		mapped := false
+		for _, s := range n.symlinks {
+			if rel, ok := ospath.Child(s.target, path); ok {
+				path = filepath.Join(s.link, rel)
+				mapped = true
+				break
+			}
+		}
+		if !mapped {
+			break
+		}
+	}
+	return path
+}
@@ -0,0 +1,120 @@
+package filewatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// How long to wait for a URL to answer a check.
+const urlCheckTimeout = 30 * time.Second
+
+// Checks the URLs of a url mode FileWatch every PollInterval, and reports
+// the ones whose ETag or Last-Modified header changed since the last check.
+//
+// The first check only records the headers, so nothing is reported
+// until a URL changes.
+func (c *Controller) pollURLsLoop(ctx context.Context, w *watcher) {
+	defer func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		w.cleanupWatch(ctx)
+		c.requeuer.Add(w.name)
+	}()
+
+	versions := make(map[string]string)
+	lastErr := ""
+	check := func() {
+		changed, err := checkURLs(ctx, c.httpClient, w.spec.URLs, versions)
+		if len(changed) > 0 {
+			w.recordSeenFiles(changed)
+			c.requeuer.Add(w.name)
+		}
+		errText := ""
+		if err != nil {
+			errText = err.Error()
+		}
+		if errText != lastErr {
+			w.recordError(err)
+			c.requeuer.Add(w.name)
+		}
+		lastErr = errText
+	}
+	check()
+
+	ticker := c.clock.NewTicker(w.spec.PollInterval.Duration)
+	defer ticker.Stop()
+
+	// Without a heartbeat interval, the nil channel never fires.
+	var heartbeatCh <-chan time.Time
+	if w.spec.HeartbeatInterval.Duration > 0 {
+		heartbeat := c.clock.NewTicker(w.spec.HeartbeatInterval.Duration)
+		defer heartbeat.Stop()
+		heartbeatCh = heartbeat.Chan()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.Chan():
+			check()
+		case <-heartbeatCh:
+			w.recordHeartbeat()
+			c.requeuer.Add(w.name)
+		}
+	}
+}
+
+// Checks each URL, and returns the ones whose version differs from the one
+// in versions. Updates versions with what it found.
+//
+// A URL that can't be checked keeps its last version, so it's only
+// reported once it can be checked again, and if it changed.
+func checkURLs(ctx context.Context, client *http.Client, urls []string, versions map[string]string) ([]string, error) {
+	var changed []string
+	var errs []string
+	for _, u := range urls {
+		version, err := fetchURLVersion(ctx, client, u)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if last, ok := versions[u]; ok && last != version {
+			changed = append(changed, u)
+		}
+		versions[u] = version
+	}
+	if len(errs) > 0 {
+		return changed, errors.New(strings.Join(errs, "\n"))
+	}
+	return changed, nil
+}
+
+// Fetches the version of a URL with a HEAD request: its ETag, or its
+// Last-Modified time if it has no ETag.
+func fetchURLVersion(ctx context.Context, client *http.Client, u string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, u, nil)
+	if err != nil {
+		return "", fmt.Errorf("checking %s: %v", u, err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("checking %s: %v", u, err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", fmt.Errorf("checking %s: %s", u, resp.Status)
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		return "etag " + etag, nil
+	}
+	if modified := resp.Header.Get("Last-Modified"); modified != "" {
+		return "last-modified " + modified, nil
+	}
+	return "", fmt.Errorf("checking %s: no ETag or Last-Modified header, so changes can't be detected", u)
+}
@@ -2,14 +2,19 @@ package filewatch
 
 import (
 	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/jonboulle/clockwork"
 
+	"github.com/tilt-dev/tilt/internal/ospath"
 	"github.com/tilt-dev/tilt/internal/watch"
 	"github.com/tilt-dev/tilt/pkg/apis"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
@@ -93,14 +98,109 @@ func (w *watcher) recordError(err error) {
 	}
 }
 
-func (w *watcher) recordEvent(fsEvents []watch.FileEvent) {
-	now := apis.NowMicro()
+func (w *watcher) recordHeartbeat() {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	event := v1alpha1.FileEvent{Time: *now.DeepCopy()}
+	now := metav1.NewMicroTime(w.clock.Now())
+	w.status.LastHeartbeatTime = &now
+}
+
+// Finds the files under paths that were modified after since, skipping ignored files.
+func filesChangedSince(paths []string, ignore watch.PathMatcher, since time.Time) ([]watch.FileEvent, error) {
+	var result []watch.FileEvent
+	seen := make(map[string]bool)
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+
+			if entry.IsDir() {
+				skip, err := ignore.MatchesEntireDir(path)
+				if err != nil {
+					return err
+				}
+				if skip {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if seen[path] {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if !info.ModTime().After(since) {
+				return nil
+			}
+			ignored, err := ignore.Matches(path)
+			if err != nil {
+				return err
+			}
+			if !ignored {
+				seen[path] = true
+				result = append(result, watch.NewFileEvent(path))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// Matches paths below the immediate children of watched directories,
+// so that a NonRecursive FileWatch ignores them.
+type nonRecursiveMatcher struct {
+	watchedPaths []string
+}
+
+var _ watch.PathMatcher = nonRecursiveMatcher{}
+
+func (m nonRecursiveMatcher) Matches(f string) (bool, error) {
+	matches := false
+	for _, root := range m.watchedPaths {
+		if !ospath.IsChild(root, f) {
+			continue
+		}
+		if f == root || filepath.Dir(f) == root {
+			return false, nil
+		}
+		matches = true
+	}
+	return matches, nil
+}
+
+// Doesn't match the immediate children themselves, so that changes
+// to a child directory are still seen.
+func (m nonRecursiveMatcher) MatchesEntireDir(f string) (bool, error) {
+	return m.Matches(f)
+}
+
+func (w *watcher) recordEvent(fsEvents []watch.FileEvent) {
+	var seen []string
 	for _, fsEvent := range fsEvents {
-		event.SeenFiles = append(event.SeenFiles, fsEvent.Path())
+		seen = append(seen, fsEvent.Path())
 	}
+	w.recordSeenFiles(seen)
+}
+
+// Records a FileEvent for the seen files, which are URLs in url mode.
+func (w *watcher) recordSeenFiles(seen []string) {
+	now := apis.NowMicro()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	event := v1alpha1.FileEvent{Time: *now.DeepCopy(), SeenFiles: seen}
 	if len(event.SeenFiles) != 0 {
 		w.status.LastEventTime = *now.DeepCopy()
 		w.status.FileEvents = append(w.status.FileEvents, event)
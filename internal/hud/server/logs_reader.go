@@ -31,11 +31,6 @@ type WebsocketReader struct {
 	handler      ViewHandler
 }
 
-func newWebsocketReaderForLogs(conn WebsocketConn, persistent bool, resources []string, p *hud.IncrementalPrinter) *WebsocketReader {
-	ls := NewLogStreamer(resources, p)
-	return newWebsocketReader(conn, persistent, ls)
-}
-
 func newWebsocketReader(conn WebsocketConn, persistent bool, handler ViewHandler) *WebsocketReader {
 	return &WebsocketReader{
 		conn:         conn,
@@ -59,7 +54,8 @@ type LogStreamer struct {
 	//
 	// This value should only be used to compare to other server values, NOT client checkpoints.
 	serverWatermark int32
-	resources       model.ManifestNameSet // if present, resource(s) to stream logs for
+	resources       model.ManifestNameSet            // if present, resource(s) to stream logs for
+	filter          func(line logstore.LogLine) bool // if present, only lines it accepts are printed
 	printer         *hud.IncrementalPrinter
 }
 
@@ -76,6 +72,13 @@ func NewLogStreamer(resources []string, p *hud.IncrementalPrinter) *LogStreamer
 	}
 }
 
+// Like NewLogStreamer, but only prints the lines that filter accepts.
+func NewFilteredLogStreamer(resources []string, filter func(line logstore.LogLine) bool, p *hud.IncrementalPrinter) *LogStreamer {
+	ls := NewLogStreamer(resources, p)
+	ls.filter = filter
+	return ls
+}
+
 func (ls *LogStreamer) Handle(v *proto_webview.View) error {
 	if v == nil || v.LogList == nil || v.LogList.FromCheckpoint == -1 {
 		// Server has no new logs to send
@@ -89,6 +92,9 @@ func (ls *LogStreamer) Handle(v *proto_webview.View) error {
 	if v.LogList.FromCheckpoint < ls.serverWatermark {
 		// The server is re-sending some logs we already have, so slice them off.
 		deleteCount := ls.serverWatermark - v.LogList.FromCheckpoint
+		if int(deleteCount) > len(segments) {
+			deleteCount = int32(len(segments))
+		}
 		segments = segments[deleteCount:]
 	}
 
@@ -97,10 +103,20 @@ func (ls *LogStreamer) Handle(v *proto_webview.View) error {
 		ls.logstore.Append(webview.LogSegmentToEvent(seg, v.LogList.Spans), model.SecretSet{})
 	}
 
-	ls.printer.Print(ls.logstore.ContinuingLinesWithOptions(ls.checkpoint, logstore.LineOptions{
+	lines := ls.logstore.ContinuingLinesWithOptions(ls.checkpoint, logstore.LineOptions{
 		ManifestNames:  ls.resources,
 		SuppressPrefix: suppressPrefix,
-	}))
+	})
+	if ls.filter != nil {
+		filtered := []logstore.LogLine{}
+		for _, line := range lines {
+			if ls.filter(line) {
+				filtered = append(filtered, line)
+			}
+		}
+		lines = filtered
+	}
+	ls.printer.Print(lines)
 
 	ls.checkpoint = ls.logstore.Checkpoint()
 	ls.serverWatermark = v.LogList.ToCheckpoint
@@ -108,6 +124,12 @@ func (ls *LogStreamer) Handle(v *proto_webview.View) error {
 	return nil
 }
 func StreamLogs(ctx context.Context, follow bool, url model.WebURL, resources []string, printer *hud.IncrementalPrinter) error {
+	return StreamLogsTo(ctx, follow, url, NewLogStreamer(resources, printer))
+}
+
+// Like StreamLogs, but hands the views to handler. Returns when the
+// connection closes, or, if follow is false, after the first view.
+func StreamLogsTo(ctx context.Context, follow bool, url model.WebURL, handler ViewHandler) error {
 	url.Scheme = "ws"
 	url.Path = "/ws/view"
 	logger.Get(ctx).Debugf("connecting to %s", url.String())
@@ -118,7 +140,7 @@ func StreamLogs(ctx context.Context, follow bool, url model.WebURL, resources []
 	}
 	defer conn.Close()
 
-	wsr := newWebsocketReaderForLogs(conn, follow, resources, printer)
+	wsr := newWebsocketReader(conn, follow, handler)
 	return wsr.Listen(ctx)
 }
 
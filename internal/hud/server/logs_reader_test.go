@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
 
 	"github.com/tilt-dev/tilt/internal/hud"
 	proto_webview "github.com/tilt-dev/tilt/pkg/webview"
@@ -131,6 +132,31 @@ func TestLogStreamerCheckpointHandlingWithFiltering(t *testing.T) {
 	f.assertExpectedLogLines(expected)
 }
 
+func TestLogStreamerFiltersOnLines(t *testing.T) {
+	f := newLogStreamerFixture(t)
+	f.ls.filter = func(line logstore.LogLine) bool {
+		return strings.Contains(line.Text, "a")
+	}
+	view := f.newViewWithLogsForManifest([]string{"alpha", "echo", "lima", "mike"}, "foo", 0)
+	f.handle(view)
+
+	expected := f.expectedLinesWithPrefix([]string{"alpha", "lima"}, "foo")
+	f.assertExpectedLogLines(expected)
+}
+
+func TestLogStreamerServerHasFewerLogsThanSeen(t *testing.T) {
+	f := newLogStreamerFixture(t)
+	view := f.newViewWithLogsForManifest(alphabet[:4], "foo", 0)
+	f.handle(view)
+
+	// e.g., the tilt session restarted, and only has two segments so far.
+	view = f.newViewWithLogsForManifest(alphabet[4:6], "foo", 0)
+	f.handle(view)
+
+	expected := f.expectedLinesWithPrefix(alphabet[:4], "foo")
+	f.assertExpectedLogLines(expected)
+}
+
 type logStreamerFixture struct {
 	t          *testing.T
 	fakeStdout *bytes.Buffer
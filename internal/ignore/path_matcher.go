@@ -2,6 +2,7 @@ package ignore
 
 import (
 	"path/filepath"
+	"strings"
 
 	"github.com/pkg/errors"
 
@@ -26,23 +27,62 @@ func CreateFileChangeFilter(ignores []v1alpha1.IgnoreDef) model.PathMatcher {
 func ToMatchersBestEffort(ignores []v1alpha1.IgnoreDef) []model.PathMatcher {
 	var ignoreMatchers []model.PathMatcher
 	for _, ignoreDef := range ignores {
-		if len(ignoreDef.Patterns) != 0 {
-			m, err := dockerignore.NewDockerPatternMatcher(
-				ignoreDef.BasePath,
-				append([]string{}, ignoreDef.Patterns...))
-			if err == nil {
-				ignoreMatchers = append(ignoreMatchers, m)
-			}
-		} else {
-			m, err := NewDirectoryMatcher(ignoreDef.BasePath)
-			if err == nil {
-				ignoreMatchers = append(ignoreMatchers, m)
-			}
+		m, err := toMatcher(ignoreDef)
+		if err == nil {
+			ignoreMatchers = append(ignoreMatchers, m)
 		}
 	}
 	return ignoreMatchers
 }
 
+// Interpret a single ignore as a PathMatcher.
+func toMatcher(ignoreDef v1alpha1.IgnoreDef) (model.PathMatcher, error) {
+	basePath := ignoreDef.BasePath
+	patterns := append([]string{}, ignoreDef.Patterns...)
+	if ignoreDef.IgnoreCase {
+		basePath = strings.ToLower(basePath)
+		for i, p := range patterns {
+			patterns[i] = strings.ToLower(p)
+		}
+	}
+
+	var m model.PathMatcher
+	if len(patterns) != 0 {
+		dm, err := dockerignore.NewDockerPatternMatcher(basePath, patterns)
+		if err != nil {
+			return nil, err
+		}
+		m = dm
+	} else {
+		dm, err := NewDirectoryMatcher(basePath)
+		if err != nil {
+			return nil, err
+		}
+		m = dm
+	}
+
+	if ignoreDef.IgnoreCase {
+		return caseInsensitiveMatcher{matcher: m}, nil
+	}
+	return m, nil
+}
+
+// Matches paths regardless of case, by lowercasing them for a matcher
+// built from lowercased patterns.
+type caseInsensitiveMatcher struct {
+	matcher model.PathMatcher
+}
+
+var _ model.PathMatcher = caseInsensitiveMatcher{}
+
+func (m caseInsensitiveMatcher) Matches(p string) (bool, error) {
+	return m.matcher.Matches(strings.ToLower(p))
+}
+
+func (m caseInsensitiveMatcher) MatchesEntireDir(p string) (bool, error) {
+	return m.matcher.MatchesEntireDir(strings.ToLower(p))
+}
+
 type DirectoryMatcher struct {
 	dir string
 }
@@ -138,3 +138,34 @@ func TestIgnores(t *testing.T) {
 		})
 	}
 }
+
+func TestIgnoreCase(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	root := f.JoinPath("Root")
+
+	for _, tc := range []struct {
+		ignore     v1alpha1.IgnoreDef
+		path       string
+		ignoreCase bool
+	}{
+		{v1alpha1.IgnoreDef{BasePath: root, Patterns: []string{"BUILD"}}, filepath.Join(root, "build", "out.txt"), true},
+		{v1alpha1.IgnoreDef{BasePath: root, Patterns: []string{"build"}}, filepath.Join(root, "BUILD"), true},
+		{v1alpha1.IgnoreDef{BasePath: root, Patterns: []string{"*.TMP"}}, filepath.Join(root, "a.tmp"), true},
+		{v1alpha1.IgnoreDef{BasePath: root, Patterns: []string{"build"}}, filepath.Join(f.Path(), "root", "build"), true},
+		{v1alpha1.IgnoreDef{BasePath: root}, filepath.Join(f.Path(), "ROOT", "a.txt"), true},
+		{v1alpha1.IgnoreDef{BasePath: root, Patterns: []string{"build"}}, filepath.Join(root, "src", "main.go"), false},
+	} {
+		t.Run(fmt.Sprintf("%v %s", tc.ignore.Patterns, tc.path), func(t *testing.T) {
+			sensitive := CreateBuildContextFilter([]v1alpha1.IgnoreDef{tc.ignore})
+			matches, err := sensitive.Matches(tc.path)
+			assert.NoError(t, err)
+			assert.False(t, matches, "case-sensitive ignore shouldn't match")
+
+			tc.ignore.IgnoreCase = true
+			insensitive := CreateBuildContextFilter([]v1alpha1.IgnoreDef{tc.ignore})
+			matches, err = insensitive.Matches(tc.path)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.ignoreCase, matches)
+		})
+	}
+}
@@ -337,6 +337,16 @@ def file_watch(
   watched_paths: List[str] = None,
   ignores: List[IgnoreDef] = None,
   disable_source: Optional[DisableSource] = None,
+  debounce_duration: str = "",
+  mode: str = "",
+  poll_interval: str = "",
+  max_events: int = 0,
+  non_recursive: bool = False,
+  follow_symlinks: bool = False,
+  heartbeat_interval: str = "",
+  rate_limit_events: int = 0,
+  rate_limit_window: str = "",
+  urls: List[str] = None,
 ):
   """
   FileWatch
@@ -345,11 +355,74 @@ def file_watch(
     name: The name in the Object metadata.
     labels: A set of key/value pairs in the Object metadata for grouping objects.
     annotations: A set of key/value pairs in the Object metadata for attaching data to objects.
-    watched_paths: WatchedPaths are paths of directories or files to watch for changes to. It cannot be empty.
+    watched_paths: WatchedPaths are paths of directories or files to watch for changes to. It cannot be empty,
+      except in url mode, where it must be.
       
     ignores: Ignores are optional rules to filter out a subset of changes matched by WatchedPaths.
     disable_source: Specifies how to disable this.
       
+    debounce_duration: DebounceDuration is how long the watcher waits without seeing any new file changes
+      before reporting a batch of changes in the status.
+      
+      Editors that save by writing a temp file and renaming it can generate bursts of events;
+      a longer debounce coalesces them into a single FileEvent.
+      
+      If zero, a short default is used. It cannot be negative.
+      
+    mode: Mode is how the watcher detects file changes.
+      
+      If empty, uses native filesystem notifications.
+      
+    poll_interval: PollInterval is how often to check the filesystem for changes in poll mode,
+      or the URLs in url mode.
+      
+      It must be positive in poll and url modes, and zero otherwise.
+      
+    max_events: MaxEvents is the most file changes the watcher coalesces into a single batch.
+      
+      When a batch reaches MaxEvents, it's reported right away, even if changes
+      are still coming in. This bounds the memory used by high-churn directories,
+      like build outputs.
+      
+      If zero, batches are unbounded. It cannot be negative.
+      
+    non_recursive: NonRecursive watches only the immediate children of watched directories.
+      
+      Changes deeper inside a watched directory are ignored. Watched files
+      are watched the same either way.
+      
+    follow_symlinks: FollowSymlinks also watches the targets of symlinks under WatchedPaths.
+      
+      Changes to a target are reported at the path of the symlink. Symlinks
+      to a path that's already watched, or to a directory that contains one,
+      aren't followed, so symlink cycles don't watch anything twice.
+      
+    heartbeat_interval: HeartbeatInterval is how often the watcher updates LastHeartbeatTime
+      in its status, even if no files changed.
+      
+      It lets monitors tell a live watcher with no changes from a dead one.
+      If zero, the status is only updated on changes and errors.
+      
+    rate_limit_events: RateLimitEvents is the most file events the watcher reports per
+      RateLimitWindow, to limit how fast downstream consumers are triggered.
+      
+      The limit is a token bucket: up to RateLimitEvents events may be reported
+      in a burst, and then one more every RateLimitWindow / RateLimitEvents.
+      Changes that come in faster are held, and reported together in the next
+      event that the limit allows.
+      
+      If zero, events aren't limited. It cannot be negative, and is set
+      together with RateLimitWindow.
+      
+    rate_limit_window: RateLimitWindow is the window of RateLimitEvents.
+      
+    urls: URLs are http or https URLs to watch for changes to, in url mode.
+      
+      The watcher checks each URL every PollInterval with a HEAD request, and
+      reports it as changed when its ETag or Last-Modified header changes.
+      
+      URLs cannot be empty in url mode, and must be empty otherwise.
+      
 """
   pass
 def kubernetes_apply(
@@ -656,6 +729,7 @@ def handler(
 def ignore_def(
   base_path: str = "",
   patterns: List[str] = None,
+  ignore_case: bool = False,
 ) -> IgnoreDef:
   """
   Describes sets of file paths that the FileWatch should ignore.
@@ -668,6 +742,7 @@ def ignore_def(
     patterns: Patterns are dockerignore style rules. Absolute-style patterns will be rooted to the BasePath.
       
       See https://docs.docker.com/engine/reference/builder/#dockerignore-file.
+    ignore_case: IgnoreCase makes the patterns and the BasePath match paths regardless of case, e.g., for case-insensitive filesystems like the macOS default.
 """
   pass
 
@@ -131,6 +131,29 @@ v1alpha1.file_watch(name='my-fw',
 	})
 }
 
+func TestFileWatchURLMode(t *testing.T) {
+	f := newFixture(t)
+
+	f.File("Tiltfile", `
+v1alpha1.file_watch(name='my-fw',
+                    mode='url',
+                    urls=['https://example.com/config.json'],
+                    poll_interval='30s')
+`)
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+
+	set := MustState(result)
+
+	fw := set.GetSetForType(&v1alpha1.FileWatch{})["my-fw"].(*v1alpha1.FileWatch)
+	require.NotNil(t, fw)
+	require.Equal(t, fw.Spec, v1alpha1.FileWatchSpec{
+		Mode:         v1alpha1.FileWatchModeURL,
+		URLs:         []string{"https://example.com/config.json"},
+		PollInterval: metav1.Duration{Duration: 30 * time.Second},
+	})
+}
+
 func TestFileWatchWithIgnoreBuiltin(t *testing.T) {
 	f := newFixture(t)
 
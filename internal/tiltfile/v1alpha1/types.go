@@ -304,6 +304,14 @@ func (p Plugin) fileWatch(t *starlark.Thread, fn *starlark.Builtin, args starlar
 	var watchedPaths value.LocalPathList = value.NewLocalPathListUnpacker(t)
 	var ignores IgnoreDefList = IgnoreDefList{t: t}
 	var disableSource DisableSource = DisableSource{t: t}
+	var debounceDuration value.Duration
+	var mode string
+	var pollInterval value.Duration
+	var maxEvents value.Int32
+	var heartbeatInterval value.Duration
+	var rateLimitEvents value.Int32
+	var rateLimitWindow value.Duration
+	var urls value.StringList
 	var labels value.StringStringMap
 	var annotations value.StringStringMap
 	err = starkit.UnpackArgs(t, fn.Name(), args, kwargs,
@@ -313,6 +321,16 @@ func (p Plugin) fileWatch(t *starlark.Thread, fn *starlark.Builtin, args starlar
 		"watched_paths?", &watchedPaths,
 		"ignores?", &ignores,
 		"disable_source?", &disableSource,
+		"debounce_duration?", &debounceDuration,
+		"mode?", &mode,
+		"poll_interval?", &pollInterval,
+		"max_events?", &maxEvents,
+		"non_recursive?", &obj.Spec.NonRecursive,
+		"follow_symlinks?", &obj.Spec.FollowSymlinks,
+		"heartbeat_interval?", &heartbeatInterval,
+		"rate_limit_events?", &rateLimitEvents,
+		"rate_limit_window?", &rateLimitWindow,
+		"urls?", &urls,
 	)
 	if err != nil {
 		return nil, err
@@ -323,6 +341,14 @@ func (p Plugin) fileWatch(t *starlark.Thread, fn *starlark.Builtin, args starlar
 	if disableSource.isUnpacked {
 		obj.Spec.DisableSource = (*v1alpha1.DisableSource)(&disableSource.Value)
 	}
+	obj.Spec.DebounceDuration = metav1.Duration{Duration: time.Duration(debounceDuration)}
+	obj.Spec.Mode = v1alpha1.FileWatchMode(mode)
+	obj.Spec.PollInterval = metav1.Duration{Duration: time.Duration(pollInterval)}
+	obj.Spec.MaxEvents = maxEvents.Int32()
+	obj.Spec.HeartbeatInterval = metav1.Duration{Duration: time.Duration(heartbeatInterval)}
+	obj.Spec.RateLimitEvents = rateLimitEvents.Int32()
+	obj.Spec.RateLimitWindow = metav1.Duration{Duration: time.Duration(rateLimitWindow)}
+	obj.Spec.URLs = urls
 	obj.ObjectMeta.Labels = labels
 	obj.ObjectMeta.Annotations = annotations
 	return p.register(t, obj)
@@ -1424,15 +1450,17 @@ type IgnoreDef struct {
 func (p Plugin) ignoreDef(t *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var basePath starlark.Value
 	var patterns starlark.Value
+	var ignoreCase starlark.Value
 	err := starkit.UnpackArgs(t, fn.Name(), args, kwargs,
 		"base_path?", &basePath,
 		"patterns?", &patterns,
+		"ignore_case?", &ignoreCase,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	dict := starlark.NewDict(2)
+	dict := starlark.NewDict(3)
 
 	if basePath != nil {
 		err := dict.SetKey(starlark.String("base_path"), basePath)
@@ -1446,6 +1474,12 @@ func (p Plugin) ignoreDef(t *starlark.Thread, fn *starlark.Builtin, args starlar
 			return nil, err
 		}
 	}
+	if ignoreCase != nil {
+		err := dict.SetKey(starlark.String("ignore_case"), ignoreCase)
+		if err != nil {
+			return nil, err
+		}
+	}
 	var obj *IgnoreDef = &IgnoreDef{t: t}
 	err = obj.Unpack(dict)
 	if err != nil {
@@ -1493,6 +1527,14 @@ func (o *IgnoreDef) Unpack(v starlark.Value) error {
 			obj.Patterns = v
 			continue
 		}
+		if key == "ignore_case" {
+			v, ok := val.(starlark.Bool)
+			if !ok {
+				return fmt.Errorf("Expected bool, got: %v", val.Type())
+			}
+			obj.IgnoreCase = bool(v)
+			continue
+		}
 		return fmt.Errorf("Unexpected attribute name: %s", key)
 	}
 
@@ -301,6 +301,22 @@ func TestSingleFile(t *testing.T) {
 	f.assertEvents(path)
 }
 
+func TestSingleFileDoesNotFireSiblingEvent(t *testing.T) {
+	f := newNotifyFixture(t)
+
+	root := f.TempDir("root")
+	watchedFile := filepath.Join(root, "a.txt")
+	unwatchedSibling := filepath.Join(root, "b.txt")
+
+	f.WriteFile(watchedFile, "hello\n")
+	f.watch(watchedFile)
+	f.fsync()
+	f.events = nil
+
+	f.WriteFile(unwatchedSibling, "hello\ngo\n")
+	f.assertEvents()
+}
+
 func TestWriteBrokenLink(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("no user-space symlinks on windows")
@@ -0,0 +1,226 @@
+package watch
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tilt-dev/tilt/internal/ospath"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// A file watcher that periodically stats every watched path.
+//
+// Much slower than native notifications, but works on filesystems
+// that don't deliver them reliably, like NFS or other network mounts.
+type pollNotify struct {
+	// Paths that we're watching that should be passed up to the caller.
+	notifyList map[string]bool
+
+	ignore   PathMatcher
+	log      logger.Logger
+	interval time.Duration
+
+	events chan FileEvent
+	errors chan error
+	done   chan struct{}
+
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// The parts of a FileInfo that we compare to decide whether a file changed.
+type pollFileInfo struct {
+	modTime time.Time
+	size    int64
+	mode    fs.FileMode
+}
+
+func NewPollingWatcher(paths []string, ignore PathMatcher, interval time.Duration, l logger.Logger) (Notify, error) {
+	if ignore == nil {
+		return nil, fmt.Errorf("NewPollingWatcher: ignore is nil")
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("NewPollingWatcher: interval must be positive, got %s", interval)
+	}
+
+	notifyList := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		path, err := filepath.Abs(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "NewPollingWatcher")
+		}
+		notifyList[path] = true
+	}
+
+	return &pollNotify{
+		notifyList: notifyList,
+		ignore:     ignore,
+		log:        l,
+		interval:   interval,
+		events:     make(chan FileEvent),
+		errors:     make(chan error),
+		done:       make(chan struct{}),
+	}, nil
+}
+
+func (d *pollNotify) Start() error {
+	if len(d.notifyList) == 0 {
+		return nil
+	}
+
+	snapshot, err := d.scan()
+	if err != nil {
+		return err
+	}
+
+	d.wg.Add(1)
+	go d.loop(snapshot)
+	return nil
+}
+
+func (d *pollNotify) Close() error {
+	d.closeOnce.Do(func() {
+		close(d.done)
+	})
+	d.wg.Wait()
+	return nil
+}
+
+func (d *pollNotify) Events() chan FileEvent {
+	return d.events
+}
+
+func (d *pollNotify) Errors() chan error {
+	return d.errors
+}
+
+func (d *pollNotify) loop(snapshot map[string]pollFileInfo) {
+	defer d.wg.Done()
+	defer close(d.events)
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-ticker.C:
+		}
+
+		next, err := d.scan()
+		if err != nil {
+			d.log.Infof("Error polling for file changes: %v", err)
+			continue
+		}
+
+		for _, path := range changedPaths(snapshot, next) {
+			if !d.shouldNotify(path) {
+				continue
+			}
+			select {
+			case d.events <- FileEvent{path}:
+			case <-d.done:
+				return
+			}
+		}
+		snapshot = next
+	}
+}
+
+// Stats every path under the notify list, skipping ignored directories.
+func (d *pollNotify) scan() (map[string]pollFileInfo, error) {
+	result := make(map[string]pollFileInfo)
+	for root := range d.notifyList {
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+
+			if entry.IsDir() && path != root {
+				skip, err := d.ignore.MatchesEntireDir(path)
+				if err != nil {
+					return errors.Wrap(err, "scan")
+				}
+				if skip {
+					return filepath.SkipDir
+				}
+			}
+
+			info, err := entry.Info()
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if entry.IsDir() {
+				// A directory's mtime changes whenever its children do,
+				// and we report those children directly.
+				result[path] = pollFileInfo{mode: info.Mode()}
+				return nil
+			}
+			result[path] = pollFileInfo{
+				modTime: info.ModTime(),
+				size:    info.Size(),
+				mode:    info.Mode(),
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "polling %q", root)
+		}
+	}
+	return result, nil
+}
+
+// Returns the paths that were created, deleted, or modified between two scans.
+func changedPaths(before, after map[string]pollFileInfo) []string {
+	var result []string
+	for path, info := range after {
+		old, ok := before[path]
+		if !ok || old != info {
+			result = append(result, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			result = append(result, path)
+		}
+	}
+	return result
+}
+
+func (d *pollNotify) shouldNotify(path string) bool {
+	ignore, err := d.ignore.Matches(path)
+	if err != nil {
+		d.log.Infof("Error matching path %q: %v", path, err)
+	} else if ignore {
+		return false
+	}
+
+	if _, ok := d.notifyList[path]; ok {
+		// We generally don't care when directories change at the root of an ADD
+		stat, err := os.Lstat(path)
+		isDir := err == nil && stat.IsDir()
+		return !isDir
+	}
+
+	for root := range d.notifyList {
+		if ospath.IsChild(root, path) {
+			return true
+		}
+	}
+	return false
+}
+
+var _ Notify = &pollNotify{}
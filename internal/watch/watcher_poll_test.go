@@ -0,0 +1,164 @@
+package watch
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/dockerignore"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+func TestPollCreateModifyDelete(t *testing.T) {
+	f := newPollFixture(t)
+	root := f.TempDir("root")
+	f.start([]string{root}, EmptyMatcher{})
+
+	path := filepath.Join(root, "a.txt")
+	f.WriteFile(path, "hello\n")
+	f.assertNextEvent(path)
+
+	f.WriteFile(path, "hello\nworld\n")
+	f.assertNextEvent(path)
+
+	require.NoError(t, os.Remove(path))
+	f.assertNextEvent(path)
+}
+
+func TestPollNewDirectoriesAreRecursivelyWatched(t *testing.T) {
+	f := newPollFixture(t)
+	root := f.TempDir("root")
+	f.start([]string{root}, EmptyMatcher{})
+
+	path := filepath.Join(root, "a", "b", "c.txt")
+	f.WriteFile(path, "hello\n")
+	f.assertEventsEventually(filepath.Join(root, "a"), filepath.Join(root, "a", "b"), path)
+}
+
+func TestPollSingleFileDoesNotFireSiblingEvent(t *testing.T) {
+	f := newPollFixture(t)
+	root := f.TempDir("root")
+	watchedFile := filepath.Join(root, "a.txt")
+	f.WriteFile(watchedFile, "hello\n")
+	f.start([]string{watchedFile}, EmptyMatcher{})
+
+	f.WriteFile(filepath.Join(root, "b.txt"), "hello\n")
+	f.assertNoEvents()
+
+	f.WriteFile(watchedFile, "hello\ngo\n")
+	f.assertNextEvent(watchedFile)
+}
+
+func TestPollNonExistentPath(t *testing.T) {
+	f := newPollFixture(t)
+	root := f.TempDir("root")
+	path := filepath.Join(root, "change")
+	f.start([]string{path}, EmptyMatcher{})
+
+	f.WriteFile(path, "hello\n")
+	f.assertNextEvent(path)
+}
+
+func TestPollIgnore(t *testing.T) {
+	f := newPollFixture(t)
+	root := f.TempDir("root")
+	ignore, err := dockerignore.NewDockerPatternMatcher(root, []string{"*.tmp", "build"})
+	require.NoError(t, err)
+	f.start([]string{root}, ignore)
+
+	f.WriteFile(filepath.Join(root, "a.tmp"), "hello\n")
+	f.WriteFile(filepath.Join(root, "build", "out.txt"), "hello\n")
+	f.assertNoEvents()
+
+	path := filepath.Join(root, "a.txt")
+	f.WriteFile(path, "hello\n")
+	f.assertNextEvent(path)
+}
+
+func TestPollRequiresPositiveInterval(t *testing.T) {
+	_, err := NewPollingWatcher([]string{t.TempDir()}, EmptyMatcher{}, 0, logger.NewTestLogger(bytes.NewBuffer(nil)))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "interval must be positive")
+}
+
+func TestChangedPaths(t *testing.T) {
+	now := time.Now()
+	before := map[string]pollFileInfo{
+		"/a": {modTime: now, size: 1},
+		"/b": {modTime: now, size: 1},
+		"/c": {modTime: now, size: 1},
+	}
+	after := map[string]pollFileInfo{
+		"/a": {modTime: now, size: 1},
+		"/b": {modTime: now.Add(time.Second), size: 1},
+		"/d": {modTime: now, size: 1},
+	}
+	actual := changedPaths(before, after)
+	sort.Strings(actual)
+	assert.Equal(t, []string{"/b", "/c", "/d"}, actual)
+}
+
+const pollTestInterval = 10 * time.Millisecond
+
+type pollFixture struct {
+	*tempdir.TempDirFixture
+	notify Notify
+}
+
+func newPollFixture(t *testing.T) *pollFixture {
+	return &pollFixture{TempDirFixture: tempdir.NewTempDirFixture(t)}
+}
+
+func (f *pollFixture) start(paths []string, ignore PathMatcher) {
+	notify, err := NewPollingWatcher(paths, ignore, pollTestInterval, logger.NewTestLogger(bytes.NewBuffer(nil)))
+	require.NoError(f.T(), err)
+	require.NoError(f.T(), notify.Start())
+	f.notify = notify
+	f.T().Cleanup(func() {
+		_ = notify.Close()
+	})
+}
+
+func (f *pollFixture) assertNextEvent(expected string) {
+	f.T().Helper()
+	select {
+	case e := <-f.notify.Events():
+		assert.Equal(f.T(), expected, e.Path())
+	case <-time.After(time.Second):
+		f.T().Fatalf("timed out waiting for event on %s", expected)
+	}
+}
+
+// Waits for events on all the expected paths, in any order.
+func (f *pollFixture) assertEventsEventually(expected ...string) {
+	f.T().Helper()
+	remaining := make(map[string]bool, len(expected))
+	for _, p := range expected {
+		remaining[p] = true
+	}
+	timeout := time.After(time.Second)
+	for len(remaining) > 0 {
+		select {
+		case e := <-f.notify.Events():
+			delete(remaining, e.Path())
+		case <-timeout:
+			f.T().Fatalf("timed out waiting for events on %v", remaining)
+		}
+	}
+}
+
+func (f *pollFixture) assertNoEvents() {
+	f.T().Helper()
+	select {
+	case e := <-f.notify.Events():
+		f.T().Fatalf("unexpected event on %s", e.Path())
+	case <-time.After(10 * pollTestInterval):
+	}
+}
@@ -18,6 +18,8 @@ package v1alpha1
 
 import (
 	"context"
+	"fmt"
+	"net/url"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -55,7 +57,8 @@ type FileWatchList struct {
 
 // FileWatchSpec defines the desired state of FileWatch
 type FileWatchSpec struct {
-	// WatchedPaths are paths of directories or files to watch for changes to. It cannot be empty.
+	// WatchedPaths are paths of directories or files to watch for changes to. It cannot be empty,
+	// except in url mode, where it must be.
 	//
 	// +tilt:local-path=true
 	WatchedPaths []string `json:"watchedPaths" protobuf:"bytes,1,rep,name=watchedPaths"`
@@ -66,8 +69,126 @@ type FileWatchSpec struct {
 	//
 	// +optional
 	DisableSource *DisableSource `json:"disableSource,omitempty" protobuf:"bytes,3,opt,name=disableSource"`
+
+	// DebounceDuration is how long the watcher waits without seeing any new file changes
+	// before reporting a batch of changes in the status.
+	//
+	// Editors that save by writing a temp file and renaming it can generate bursts of events;
+	// a longer debounce coalesces them into a single FileEvent.
+	//
+	// If zero, a short default is used. It cannot be negative.
+	//
+	// +optional
+	DebounceDuration metav1.Duration `json:"debounceDuration,omitempty" protobuf:"bytes,4,opt,name=debounceDuration"`
+
+	// Mode is how the watcher detects file changes.
+	//
+	// If empty, uses native filesystem notifications.
+	//
+	// +optional
+	Mode FileWatchMode `json:"mode,omitempty" protobuf:"bytes,5,opt,name=mode,casttype=FileWatchMode"`
+
+	// PollInterval is how often to check the filesystem for changes in poll mode,
+	// or the URLs in url mode.
+	//
+	// It must be positive in poll and url modes, and zero otherwise.
+	//
+	// +optional
+	PollInterval metav1.Duration `json:"pollInterval,omitempty" protobuf:"bytes,6,opt,name=pollInterval"`
+
+	// MaxEvents is the most file changes the watcher coalesces into a single batch.
+	//
+	// When a batch reaches MaxEvents, it's reported right away, even if changes
+	// are still coming in. This bounds the memory used by high-churn directories,
+	// like build outputs.
+	//
+	// If zero, batches are unbounded. It cannot be negative.
+	//
+	// +optional
+	MaxEvents int32 `json:"maxEvents,omitempty" protobuf:"varint,7,opt,name=maxEvents"`
+
+	// Since is a baseline for the first status.
+	//
+	// When the watcher starts, files under WatchedPaths modified after Since
+	// are reported in a FileEvent, as if they had just changed.
+	//
+	// If zero, only changes after the watcher starts are reported.
+	//
+	// +optional
+	Since metav1.MicroTime `json:"since,omitempty" protobuf:"bytes,8,opt,name=since"`
+
+	// NonRecursive watches only the immediate children of watched directories.
+	//
+	// Changes deeper inside a watched directory are ignored. Watched files
+	// are watched the same either way.
+	//
+	// +optional
+	NonRecursive bool `json:"nonRecursive,omitempty" protobuf:"varint,9,opt,name=nonRecursive"`
+
+	// FollowSymlinks also watches the targets of symlinks under WatchedPaths.
+	//
+	// Changes to a target are reported at the path of the symlink. Symlinks
+	// to a path that's already watched, or to a directory that contains one,
+	// aren't followed, so symlink cycles don't watch anything twice.
+	//
+	// +optional
+	FollowSymlinks bool `json:"followSymlinks,omitempty" protobuf:"varint,10,opt,name=followSymlinks"`
+
+	// HeartbeatInterval is how often the watcher updates LastHeartbeatTime
+	// in its status, even if no files changed.
+	//
+	// It lets monitors tell a live watcher with no changes from a dead one.
+	// If zero, the status is only updated on changes and errors.
+	//
+	// +optional
+	HeartbeatInterval metav1.Duration `json:"heartbeatInterval,omitempty" protobuf:"bytes,11,opt,name=heartbeatInterval"`
+
+	// RateLimitEvents is the most file events the watcher reports per
+	// RateLimitWindow, to limit how fast downstream consumers are triggered.
+	//
+	// The limit is a token bucket: up to RateLimitEvents events may be reported
+	// in a burst, and then one more every RateLimitWindow / RateLimitEvents.
+	// Changes that come in faster are held, and reported together in the next
+	// event that the limit allows.
+	//
+	// If zero, events aren't limited. It cannot be negative, and is set
+	// together with RateLimitWindow.
+	//
+	// +optional
+	RateLimitEvents int32 `json:"rateLimitEvents,omitempty" protobuf:"varint,12,opt,name=rateLimitEvents"`
+
+	// RateLimitWindow is the window of RateLimitEvents.
+	//
+	// +optional
+	RateLimitWindow metav1.Duration `json:"rateLimitWindow,omitempty" protobuf:"bytes,13,opt,name=rateLimitWindow"`
+
+	// URLs are http or https URLs to watch for changes to, in url mode.
+	//
+	// The watcher checks each URL every PollInterval with a HEAD request, and
+	// reports it as changed when its ETag or Last-Modified header changes.
+	//
+	// URLs cannot be empty in url mode, and must be empty otherwise.
+	//
+	// +optional
+	URLs []string `json:"urls,omitempty" protobuf:"bytes,14,rep,name=urls"`
 }
 
+// FileWatchMode describes how a FileWatch detects file changes.
+type FileWatchMode string
+
+const (
+	// Use native filesystem notifications (e.g., inotify or FSEvents).
+	FileWatchModeNotify FileWatchMode = "notify"
+
+	// Periodically stat the watched paths. Slower, but works on
+	// filesystems where notifications are unreliable, like NFS mounts.
+	FileWatchModePoll FileWatchMode = "poll"
+
+	// Periodically check the URLs for a new ETag or Last-Modified header,
+	// instead of watching local paths.
+	FileWatchModeURL FileWatchMode = "url"
+)
+
 // Describes sets of file paths that the FileWatch should ignore.
 type IgnoreDef struct {
 	// BasePath is the base path for the patterns. It cannot be empty.
@@ -81,6 +202,12 @@ type IgnoreDef struct {
 	//
 	// See https://docs.docker.com/engine/reference/builder/#dockerignore-file.
 	Patterns []string `json:"patterns,omitempty" protobuf:"bytes,2,rep,name=patterns"`
+
+	// IgnoreCase makes the patterns and the BasePath match paths regardless of case,
+	// e.g., for case-insensitive filesystems like the macOS default.
+	//
+	// +optional
+	IgnoreCase bool `json:"ignoreCase,omitempty" protobuf:"varint,3,opt,name=ignoreCase"`
 }
 
 var _ resource.Object = &FileWatch{}
@@ -130,10 +257,93 @@ func (in *FileWatch) IsStorageVersion() bool {
 
 func (in *FileWatch) Validate(_ context.Context) field.ErrorList {
 	var fieldErrors field.ErrorList
-	if len(in.Spec.WatchedPaths) == 0 {
-		fieldErrors = append(fieldErrors, field.Required(
-			field.NewPath("spec", "watchedPaths"),
-			"cannot be an empty list"))
+	if in.Spec.Mode == FileWatchModeURL {
+		if len(in.Spec.WatchedPaths) != 0 {
+			fieldErrors = append(fieldErrors, field.Forbidden(
+				field.NewPath("spec", "watchedPaths"),
+				"must be empty in url mode"))
+		}
+		if len(in.Spec.URLs) == 0 {
+			fieldErrors = append(fieldErrors, field.Required(
+				field.NewPath("spec", "urls"),
+				"cannot be an empty list in url mode"))
+		}
+		for i, u := range in.Spec.URLs {
+			parsed, err := url.Parse(u)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				fieldErrors = append(fieldErrors, field.Invalid(
+					field.NewPath("spec", "urls").Index(i),
+					u,
+					"must be an http or https URL"))
+			}
+		}
+	} else {
+		if len(in.Spec.WatchedPaths) == 0 {
+			fieldErrors = append(fieldErrors, field.Required(
+				field.NewPath("spec", "watchedPaths"),
+				"cannot be an empty list"))
+		}
+		if len(in.Spec.URLs) != 0 {
+			fieldErrors = append(fieldErrors, field.Forbidden(
+				field.NewPath("spec", "urls"),
+				"must be empty unless mode is url"))
+		}
+	}
+	if in.Spec.DebounceDuration.Duration < 0 {
+		fieldErrors = append(fieldErrors, field.Invalid(
+			field.NewPath("spec", "debounceDuration"),
+			in.Spec.DebounceDuration.Duration.String(),
+			"cannot be negative"))
+	}
+	if in.Spec.MaxEvents < 0 {
+		fieldErrors = append(fieldErrors, field.Invalid(
+			field.NewPath("spec", "maxEvents"),
+			in.Spec.MaxEvents,
+			"cannot be negative"))
+	}
+	if in.Spec.HeartbeatInterval.Duration < 0 {
+		fieldErrors = append(fieldErrors, field.Invalid(
+			field.NewPath("spec", "heartbeatInterval"),
+			in.Spec.HeartbeatInterval.Duration.String(),
+			"cannot be negative"))
+	}
+	if in.Spec.RateLimitEvents < 0 {
+		fieldErrors = append(fieldErrors, field.Invalid(
+			field.NewPath("spec", "rateLimitEvents"),
+			in.Spec.RateLimitEvents,
+			"cannot be negative"))
+	} else if in.Spec.RateLimitEvents > 0 && in.Spec.RateLimitWindow.Duration <= 0 {
+		fieldErrors = append(fieldErrors, field.Invalid(
+			field.NewPath("spec", "rateLimitWindow"),
+			in.Spec.RateLimitWindow.Duration.String(),
+			"must be positive when rateLimitEvents is set"))
+	} else if in.Spec.RateLimitEvents == 0 && in.Spec.RateLimitWindow.Duration != 0 {
+		fieldErrors = append(fieldErrors, field.Invalid(
+			field.NewPath("spec", "rateLimitWindow"),
+			in.Spec.RateLimitWindow.Duration.String(),
+			"must be zero unless rateLimitEvents is set"))
+	}
+
+	switch in.Spec.Mode {
+	case "", FileWatchModeNotify:
+		if in.Spec.PollInterval.Duration != 0 {
+			fieldErrors = append(fieldErrors, field.Invalid(
+				field.NewPath("spec", "pollInterval"),
+				in.Spec.PollInterval.Duration.String(),
+				"must be zero unless mode is poll"))
+		}
+	case FileWatchModePoll, FileWatchModeURL:
+		if in.Spec.PollInterval.Duration <= 0 {
+			fieldErrors = append(fieldErrors, field.Invalid(
+				field.NewPath("spec", "pollInterval"),
+				in.Spec.PollInterval.Duration.String(),
+				fmt.Sprintf("must be positive in %s mode", in.Spec.Mode)))
+		}
+	default:
+		fieldErrors = append(fieldErrors, field.NotSupported(
+			field.NewPath("spec", "mode"),
+			in.Spec.Mode,
+			[]string{string(FileWatchModeNotify), string(FileWatchModePoll), string(FileWatchModeURL)}))
 	}
 	return fieldErrors
 }
@@ -163,6 +373,10 @@ type FileWatchStatus struct {
 	// Details about whether/why this is disabled.
 	// +optional
 	DisableStatus *DisableStatus `json:"disableStatus,omitempty" protobuf:"bytes,5,opt,name=disableStatus"`
+	// LastHeartbeatTime is the timestamp of the most recent heartbeat of the watcher,
+	// if the spec has a HeartbeatInterval. It is nil until the first heartbeat.
+	// +optional
+	LastHeartbeatTime *metav1.MicroTime `json:"lastHeartbeatTime,omitempty" protobuf:"bytes,6,opt,name=lastHeartbeatTime"`
 }
 
 type FileEvent struct {
@@ -0,0 +1,150 @@
+package v1alpha1_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestFileWatch_Validate_MaxEvents(t *testing.T) {
+	for _, tc := range []struct {
+		maxEvents     int32
+		expectedError string
+	}{
+		{-1, "spec.maxEvents: Invalid value: -1: cannot be negative"},
+		{0, ""},
+		{1, ""},
+	} {
+		fw := &v1alpha1.FileWatch{
+			Spec: v1alpha1.FileWatchSpec{
+				WatchedPaths: []string{"/a"},
+				MaxEvents:    tc.maxEvents,
+			},
+		}
+		errs := fw.Validate(context.Background())
+		if tc.expectedError == "" {
+			assert.Empty(t, errs, "maxEvents %d", tc.maxEvents)
+			continue
+		}
+		require.Len(t, errs, 1, "maxEvents %d", tc.maxEvents)
+		assert.Equal(t, tc.expectedError, errs[0].Error())
+	}
+}
+
+func TestFileWatch_Validate_HeartbeatInterval(t *testing.T) {
+	for _, tc := range []struct {
+		interval      time.Duration
+		expectedError string
+	}{
+		{-time.Second, "spec.heartbeatInterval: Invalid value: \"-1s\": cannot be negative"},
+		{0, ""},
+		{time.Minute, ""},
+	} {
+		fw := &v1alpha1.FileWatch{
+			Spec: v1alpha1.FileWatchSpec{
+				WatchedPaths:      []string{"/a"},
+				HeartbeatInterval: metav1.Duration{Duration: tc.interval},
+			},
+		}
+		errs := fw.Validate(context.Background())
+		if tc.expectedError == "" {
+			assert.Empty(t, errs, "heartbeatInterval %s", tc.interval)
+			continue
+		}
+		require.Len(t, errs, 1, "heartbeatInterval %s", tc.interval)
+		assert.Equal(t, tc.expectedError, errs[0].Error())
+	}
+}
+
+func TestFileWatch_Validate_RateLimit(t *testing.T) {
+	for _, tc := range []struct {
+		events        int32
+		window        time.Duration
+		expectedError string
+	}{
+		{0, 0, ""},
+		{5, time.Second, ""},
+		{-1, time.Second, "spec.rateLimitEvents: Invalid value: -1: cannot be negative"},
+		{5, 0, "spec.rateLimitWindow: Invalid value: \"0s\": must be positive when rateLimitEvents is set"},
+		{5, -time.Second, "spec.rateLimitWindow: Invalid value: \"-1s\": must be positive when rateLimitEvents is set"},
+		{0, time.Second, "spec.rateLimitWindow: Invalid value: \"1s\": must be zero unless rateLimitEvents is set"},
+	} {
+		fw := &v1alpha1.FileWatch{
+			Spec: v1alpha1.FileWatchSpec{
+				WatchedPaths:    []string{"/a"},
+				RateLimitEvents: tc.events,
+				RateLimitWindow: metav1.Duration{Duration: tc.window},
+			},
+		}
+		errs := fw.Validate(context.Background())
+		if tc.expectedError == "" {
+			assert.Empty(t, errs, "rate limit %d/%s", tc.events, tc.window)
+			continue
+		}
+		require.Len(t, errs, 1, "rate limit %d/%s", tc.events, tc.window)
+		assert.Equal(t, tc.expectedError, errs[0].Error())
+	}
+}
+
+func TestFileWatch_Validate_URLMode(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		spec           v1alpha1.FileWatchSpec
+		expectedErrors []string
+	}{
+		{
+			name: "valid",
+			spec: v1alpha1.FileWatchSpec{
+				Mode:         v1alpha1.FileWatchModeURL,
+				URLs:         []string{"https://example.com/a.tar.gz", "http://localhost:8000/b"},
+				PollInterval: metav1.Duration{Duration: time.Minute},
+			},
+		},
+		{
+			name: "no urls",
+			spec: v1alpha1.FileWatchSpec{
+				Mode:         v1alpha1.FileWatchModeURL,
+				PollInterval: metav1.Duration{Duration: time.Minute},
+			},
+			expectedErrors: []string{"spec.urls: Required value: cannot be an empty list in url mode"},
+		},
+		{
+			name: "paths and bad urls",
+			spec: v1alpha1.FileWatchSpec{
+				Mode:         v1alpha1.FileWatchModeURL,
+				WatchedPaths: []string{"/a"},
+				URLs:         []string{"ftp://example.com/a", "example.com/b", "https://"},
+			},
+			expectedErrors: []string{
+				"spec.watchedPaths: Forbidden: must be empty in url mode",
+				"spec.urls[0]: Invalid value: \"ftp://example.com/a\": must be an http or https URL",
+				"spec.urls[1]: Invalid value: \"example.com/b\": must be an http or https URL",
+				"spec.urls[2]: Invalid value: \"https://\": must be an http or https URL",
+				"spec.pollInterval: Invalid value: \"0s\": must be positive in url mode",
+			},
+		},
+		{
+			name: "urls outside url mode",
+			spec: v1alpha1.FileWatchSpec{
+				WatchedPaths: []string{"/a"},
+				URLs:         []string{"https://example.com/a"},
+			},
+			expectedErrors: []string{"spec.urls: Forbidden: must be empty unless mode is url"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fw := &v1alpha1.FileWatch{Spec: tc.spec}
+			var actual []string
+			for _, err := range fw.Validate(context.Background()) {
+				actual = append(actual, err.Error())
+			}
+			assert.Equal(t, tc.expectedErrors, actual)
+		})
+	}
+}
@@ -44,6 +44,14 @@ const AnnotationManifest = "tilt.dev/resource"
 // its logs should appear under.
 const AnnotationSpanID = "tilt.dev/log-span-id"
 
+// AnnotationExpiresAt is an RFC3339 time after which the tilt session
+// deletes a FileWatch, e.g., one created with `tilt create filewatch --ttl`.
+const AnnotationExpiresAt = "tilt.dev/expires-at"
+
+// AnnotationDescription is a note for people about why an object exists,
+// e.g., one set with `tilt create filewatch --description`.
+const AnnotationDescription = "tilt.dev/description"
+
 // Denote that the Tiltfile is the owner.
 const OwnerKindTiltfile = "Tiltfile"
 
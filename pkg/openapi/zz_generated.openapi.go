@@ -3275,7 +3275,7 @@ func schema_pkg_apis_core_v1alpha1_FileWatchSpec(ref common.ReferenceCallback) c
 				Properties: map[string]spec.Schema{
 					"watchedPaths": {
 						SchemaProps: spec.SchemaProps{
-							Description: "WatchedPaths are paths of directories or files to watch for changes to. It cannot be empty.",
+							Description: "WatchedPaths are paths of directories or files to watch for changes to. It cannot be empty, except in url mode, where it must be.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
@@ -3308,12 +3308,92 @@ func schema_pkg_apis_core_v1alpha1_FileWatchSpec(ref common.ReferenceCallback) c
 							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableSource"),
 						},
 					},
+					"debounceDuration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DebounceDuration is how long the watcher waits without seeing any new file changes before reporting a batch of changes in the status.\n\nEditors that save by writing a temp file and renaming it can generate bursts of events; a longer debounce coalesces them into a single FileEvent.\n\nIf zero, a short default is used. It cannot be negative.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"mode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Mode is how the watcher detects file changes.\n\nIf empty, uses native filesystem notifications.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"pollInterval": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PollInterval is how often to check the filesystem for changes in poll mode, or the URLs in url mode.\n\nIt must be positive in poll and url modes, and zero otherwise.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"maxEvents": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxEvents is the most file changes the watcher coalesces into a single batch.\n\nWhen a batch reaches MaxEvents, it's reported right away, even if changes are still coming in. This bounds the memory used by high-churn directories, like build outputs.\n\nIf zero, batches are unbounded. It cannot be negative.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"since": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Since is a baseline for the first status.\n\nWhen the watcher starts, files under WatchedPaths modified after Since are reported in a FileEvent, as if they had just changed.\n\nIf zero, only changes after the watcher starts are reported.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
+						},
+					},
+					"nonRecursive": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NonRecursive watches only the immediate children of watched directories.\n\nChanges deeper inside a watched directory are ignored. Watched files are watched the same either way.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"followSymlinks": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FollowSymlinks also watches the targets of symlinks under WatchedPaths.\n\nChanges to a target are reported at the path of the symlink. Symlinks to a path that's already watched, or to a directory that contains one, aren't followed, so symlink cycles don't watch anything twice.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"heartbeatInterval": {
+						SchemaProps: spec.SchemaProps{
+							Description: "HeartbeatInterval is how often the watcher updates LastHeartbeatTime in its status, even if no files changed.\n\nIt lets monitors tell a live watcher with no changes from a dead one. If zero, the status is only updated on changes and errors.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"rateLimitEvents": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RateLimitEvents is the most file events the watcher reports per RateLimitWindow, to limit how fast downstream consumers are triggered.\n\nThe limit is a token bucket: up to RateLimitEvents events may be reported in a burst, and then one more every RateLimitWindow / RateLimitEvents. Changes that come in faster are held, and reported together in the next event that the limit allows.\n\nIf zero, events aren't limited. It cannot be negative, and is set together with RateLimitWindow.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"rateLimitWindow": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RateLimitWindow is the window of RateLimitEvents.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"urls": {
+						SchemaProps: spec.SchemaProps{
+							Description: "URLs are http or https URLs to watch for changes to, in url mode.\n\nThe watcher checks each URL every PollInterval with a HEAD request, and reports it as changed when its ETag or Last-Modified header changes.\n\nURLs cannot be empty in url mode, and must be empty otherwise.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"watchedPaths"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableSource", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.IgnoreDef"},
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableSource", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.IgnoreDef", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration", "k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"},
 	}
 }
 
@@ -3365,6 +3445,12 @@ func schema_pkg_apis_core_v1alpha1_FileWatchStatus(ref common.ReferenceCallback)
 							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DisableStatus"),
 						},
 					},
+					"lastHeartbeatTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastHeartbeatTime is the timestamp of the most recent heartbeat of the watcher, if the spec has a HeartbeatInterval. It is nil until the first heartbeat.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
+						},
+					},
 				},
 			},
 		},
@@ -3636,6 +3722,13 @@ func schema_pkg_apis_core_v1alpha1_IgnoreDef(ref common.ReferenceCallback) commo
 							},
 						},
 					},
+					"ignoreCase": {
+						SchemaProps: spec.SchemaProps{
+							Description: "IgnoreCase makes the patterns and the BasePath match paths regardless of case, e.g., for case-insensitive filesystems like the macOS default.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 				},
 				Required: []string{"basePath"},
 			},